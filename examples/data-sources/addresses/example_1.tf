@@ -0,0 +1,11 @@
+data "namecheap_addresses" "corporate" {
+  name = "Corporate"
+}
+
+resource "namecheap_domain_contacts" "main" {
+  domain = "example.com"
+
+  registrant {
+    address_id = one(data.namecheap_addresses.corporate.addresses).id
+  }
+}
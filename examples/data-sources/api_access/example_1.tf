@@ -0,0 +1,5 @@
+data "namecheap_api_access" "check" {}
+
+output "namecheap_api_access" {
+  value = data.namecheap_api_access.check.verdict
+}
@@ -0,0 +1,8 @@
+data "namecheap_api_access" "check" {
+  lifecycle {
+    postcondition {
+      condition     = self.ok
+      error_message = self.verdict
+    }
+  }
+}
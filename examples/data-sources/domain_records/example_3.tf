@@ -0,0 +1,24 @@
+data "namecheap_domain_records" "web" {
+  domain = "example.com"
+
+  # Records matching any filter block are kept; every condition in a block
+  # must hold.
+  filter {
+    hostname = "www"
+    types    = ["A", "AAAA"]
+  }
+
+  filter {
+    types = ["MX"]
+  }
+}
+
+output "www_addresses" {
+  value = [for r in jsondecode(data.namecheap_domain_records.web.records_by_hostname["www"]) : r.address]
+}
+
+output "mail_exchangers" {
+  value = {
+    for r in jsondecode(data.namecheap_domain_records.web.records_by_type["MX"]) : r.address => r.mx_pref
+  }
+}
@@ -0,0 +1,12 @@
+# Domains expiring in the next 30 days that will not renew by themselves,
+# soonest first.
+data "namecheap_domains" "expiring_manual" {
+  tlds                = ["com", "net", "io"]
+  expires_within_days = 30
+  auto_renew          = false
+  sort_by             = "EXPIREDATE"
+}
+
+output "renew_by_hand" {
+  value = [for d in data.namecheap_domains.expiring_manual.domains : "${d.name} (${d.expires_in_days} days)"]
+}
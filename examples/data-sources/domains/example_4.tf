@@ -0,0 +1,6 @@
+# The ten soonest-expiring domains. Namecheap sorts the listing, so only the
+# pages holding them are requested.
+data "namecheap_domains" "soonest" {
+  sort_by     = "EXPIREDATE"
+  max_results = 10
+}
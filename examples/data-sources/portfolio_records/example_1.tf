@@ -0,0 +1,16 @@
+data "namecheap_portfolio_records" "all" {
+  list_type = "ALL"
+}
+
+# Every A record in the account, keyed by fully qualified name.
+output "a_records" {
+  value = {
+    for r in data.namecheap_portfolio_records.all.records :
+    "${r.hostname == "@" ? "" : "${r.hostname}."}${r.domain}" => r.address
+    if r.type == "A"
+  }
+}
+
+output "unreadable_domains" {
+  value = { for e in data.namecheap_portfolio_records.all.errors : e.domain => e.error }
+}
@@ -0,0 +1,11 @@
+data "namecheap_renewal_forecast" "next_year" {
+  horizon_days = 365
+}
+
+output "renewal_cost_by_month" {
+  value = { for m in data.namecheap_renewal_forecast.next_year.monthly_totals : m.month => m.total }
+}
+
+output "renewals_needing_attention" {
+  value = [for d in data.namecheap_renewal_forecast.next_year.domains : d.name if !d.auto_renew]
+}
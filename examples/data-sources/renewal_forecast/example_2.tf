@@ -0,0 +1,10 @@
+data "namecheap_renewal_forecast" "next_quarter" {
+  horizon_days = 90
+
+  lifecycle {
+    postcondition {
+      condition     = self.balance_covers_auto_renew
+      error_message = "Auto-renewals in the next 90 days cost ${self.auto_renew_total} ${self.currency}; top up ${self.auto_renew_shortfall} ${self.currency}."
+    }
+  }
+}
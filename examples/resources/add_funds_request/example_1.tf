@@ -0,0 +1,8 @@
+resource "namecheap_add_funds_request" "top_up" {
+  amount     = "50.00"
+  return_url = "https://example.com/billing/done"
+}
+
+output "payment_url" {
+  value = namecheap_add_funds_request.top_up.redirect_url
+}
@@ -0,0 +1,16 @@
+resource "namecheap_address" "corporate" {
+  name = "Corporate"
+
+  first_name     = "Jane"
+  last_name      = "Doe"
+  organization   = "Example Corp"
+  address1       = "1 Main St"
+  city           = "Lisbon"
+  state_province = "Lisboa"
+  postal_code    = "1000-001"
+  country        = "PT"
+  phone          = "+351.123456789"
+  email_address  = "hostmaster@example.com"
+
+  default = true
+}
@@ -0,0 +1 @@
+terraform import namecheap_address.corporate 12345
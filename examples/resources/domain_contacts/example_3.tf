@@ -0,0 +1,24 @@
+resource "namecheap_domain_contacts" "main" {
+  for_each = toset(["example.com", "example.net", "example.org"])
+
+  domain = each.key
+
+  # The registrant, and through it tech, admin and aux_billing, come from the
+  # address book entry.
+  registrant {
+    address_id = namecheap_address.corporate.id
+  }
+
+  # A block may still be given inline.
+  tech {
+    first_name     = "Tim"
+    last_name      = "Ops"
+    address1       = "1 Main St"
+    city           = "Lisbon"
+    state_province = "Lisboa"
+    postal_code    = "1000-001"
+    country        = "PT"
+    phone          = "+351.123456780"
+    email_address  = "ops@example.com"
+  }
+}
@@ -0,0 +1,16 @@
+resource "namecheap_domain_contacts" "ca" {
+  domain = "example.ca"
+
+  registrant {
+    address_id = namecheap_address.corporate.id
+  }
+
+  # .ca registrations require the registrant's legal type and acceptance of
+  # the CIRA registrant agreement.
+  extended_attributes = {
+    CIRALegalType        = "CCO"
+    CIRAAgreementVersion = "2.0"
+    CIRAAgreementValue   = "Y"
+    CIRAWhoisDisplay     = "Private"
+  }
+}
@@ -0,0 +1,13 @@
+resource "namecheap_domain_delegation" "example" {
+  domain = "example.com"
+
+  nameserver {
+    host = "ns1.example.com"
+    ip   = "93.184.216.34"
+  }
+
+  nameserver {
+    host = "ns2.example.com"
+    ip   = "93.184.216.35"
+  }
+}
@@ -0,0 +1,12 @@
+resource "namecheap_domain_delegation" "example" {
+  domain = "example.com"
+
+  nameserver {
+    host = "ns1.example.com"
+    ip   = "93.184.216.34"
+  }
+
+  nameserver {
+    host = "ns1.dns-provider.net"
+  }
+}
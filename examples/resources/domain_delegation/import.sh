@@ -0,0 +1 @@
+terraform import namecheap_domain_delegation.example example.com
@@ -0,0 +1,5 @@
+resource "namecheap_email_forward" "info" {
+  domain     = "example.com"
+  mailbox    = "info"
+  forward_to = "me@example.com"
+}
@@ -0,0 +1,17 @@
+# Each team owns its own aliases on a shared domain; aliases managed elsewhere
+# (another workspace, or the Namecheap dashboard) are left untouched.
+variable "support_aliases" {
+  type = map(string)
+  default = {
+    support = "support-team@example.org"
+    abuse   = "security@example.org"
+  }
+}
+
+resource "namecheap_email_forward" "support" {
+  for_each = var.support_aliases
+
+  domain     = "example.com"
+  mailbox    = each.key
+  forward_to = each.value
+}
@@ -0,0 +1,2 @@
+# The ID is <domain>/<mailbox>; use * for the catch-all alias.
+terraform import namecheap_email_forward.info example.com/info
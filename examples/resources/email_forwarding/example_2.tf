@@ -0,0 +1,14 @@
+# Use forward blocks when an alias fans out to several addresses.
+resource "namecheap_email_forwarding" "example-com" {
+  domain = "example.com"
+
+  forward {
+    mailbox      = "ops"
+    destinations = ["alice@example.com", "bob@example.com", "carol@example.com"]
+  }
+
+  forward {
+    mailbox      = "info"
+    destinations = ["me@example.com"]
+  }
+}
@@ -0,0 +1,10 @@
+# The domain is switched to email_type = "FWD" on apply and back to its
+# previous type on destroy; no namecheap_domain_records resource is needed.
+resource "namecheap_email_forwarding" "example-com" {
+  domain            = "example.com"
+  manage_email_type = true
+
+  forwards = {
+    info = "me@example.com"
+  }
+}
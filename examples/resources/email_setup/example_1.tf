@@ -0,0 +1,12 @@
+resource "namecheap_email_setup" "mail" {
+  domain         = "example.com"
+  email_provider = "google"
+
+  dkim {
+    selector   = "google"
+    public_key = "MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEA..."
+  }
+
+  dmarc_policy = "quarantine"
+  dmarc_rua    = ["dmarc-reports@example.com"]
+}
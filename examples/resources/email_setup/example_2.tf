@@ -0,0 +1,20 @@
+resource "namecheap_email_setup" "mail" {
+  domain         = "example.com"
+  email_provider = "microsoft365"
+
+  # Microsoft 365 hosts the keys itself and has the selectors published as CNAMEs.
+  dkim {
+    selector = "selector1"
+    target   = "selector1-example-com._domainkey.contoso.onmicrosoft.com"
+  }
+  dkim {
+    selector = "selector2"
+    target   = "selector2-example-com._domainkey.contoso.onmicrosoft.com"
+  }
+
+  # A transactional mail service sending as the same domain.
+  spf_includes = ["mailgun.org"]
+  spf_all      = "-all"
+
+  dmarc_policy = "reject"
+}
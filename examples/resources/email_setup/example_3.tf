@@ -0,0 +1,19 @@
+resource "namecheap_email_setup" "mail" {
+  domain         = "example.com"
+  email_provider = "custom"
+
+  mx {
+    address = "mx1.mail.example.net"
+    mx_pref = 10
+  }
+  mx {
+    address = "mx2.mail.example.net"
+    mx_pref = 20
+  }
+
+  spf_mechanisms = ["mx", "ip4:203.0.113.0/24"]
+
+  dmarc_policy = "none"
+  dmarc_rua    = ["dmarc-reports@example.com"]
+  dmarc_pct    = 100
+}
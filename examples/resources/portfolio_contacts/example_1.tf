@@ -0,0 +1,24 @@
+resource "namecheap_portfolio_contacts" "legal_address" {
+  # Every domain in the account whose name contains "example".
+  search_term = "example"
+
+  # A new registrant on gTLD domains such as .com must be acknowledged.
+  acknowledge_transfer_lock = true
+
+  registrant {
+    first_name     = "Jane"
+    last_name      = "Doe"
+    organization   = "Example Corp"
+    address1       = "2 New St"
+    city           = "Porto"
+    state_province = "Porto"
+    postal_code    = "4000-001"
+    country        = "PT"
+    phone          = "+351.123456789"
+    email_address  = "hostmaster@example.com"
+  }
+}
+
+output "failed_domains" {
+  value = [for r in namecheap_portfolio_contacts.legal_address.results : r.domain if r.status != "applied"]
+}
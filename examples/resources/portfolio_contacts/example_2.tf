@@ -0,0 +1,10 @@
+resource "namecheap_portfolio_contacts" "brands" {
+  domains = ["example.com", "example.net", "example.org"]
+
+  # A new registrant on gTLD domains such as .com must be acknowledged.
+  acknowledge_transfer_lock = true
+
+  registrant {
+    address_id = namecheap_address.corporate.id
+  }
+}
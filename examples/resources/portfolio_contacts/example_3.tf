@@ -0,0 +1,24 @@
+resource "namecheap_portfolio_contacts" "mixed_tlds" {
+  domains = ["example.us", "example.ca", "example.pt"]
+
+  registrant {
+    address_id = namecheap_address.corporate.id
+  }
+
+  extended_attributes {
+    tld = "us"
+    attributes = {
+      RegistrantNexus   = "C11"
+      RegistrantPurpose = "P1"
+    }
+  }
+
+  extended_attributes {
+    tld = "ca"
+    attributes = {
+      CIRALegalType        = "CCO"
+      CIRAAgreementVersion = "2.0"
+      CIRAAgreementValue   = "Y"
+    }
+  }
+}
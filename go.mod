@@ -7,6 +7,7 @@ require (
 	github.com/hashicorp/terraform-plugin-testing v1.16.0
 	github.com/namecheap/go-namecheap-sdk/v2 v2.10.1
 	github.com/stretchr/testify v1.12.0
+	golang.org/x/net v0.56.0
 )
 
 require (
@@ -74,7 +75,6 @@ require (
 	golang.org/x/crypto v0.53.0 // indirect
 	golang.org/x/exp v0.0.0-20260410095643-746e56fc9e2f // indirect
 	golang.org/x/mod v0.37.0 // indirect
-	golang.org/x/net v0.56.0 // indirect
 	golang.org/x/sync v0.21.0 // indirect
 	golang.org/x/sys v0.46.0 // indirect
 	golang.org/x/text v0.39.0 // indirect
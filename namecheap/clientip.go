@@ -1,60 +1,315 @@
 package namecheap_provider
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 )
 
 // ipDetectionURL is the endpoint queried to auto-detect the caller's public IP
-// when client_ip is left unset. It is a fixed, provider-controlled constant
-// (never derived from user input) and is always fetched over HTTPS with the
-// caller-supplied *http.Client, whose transport performs standard TLS
-// certificate verification. It is a package-level var only so tests can point
-// detectClientIP at an httptest server; it is not exposed as configuration.
+// when client_ip is left unset and no client_ip_detection block names sources
+// of its own. It is a fixed, provider-controlled constant (never derived from
+// user input) and is always fetched over HTTPS with the caller-supplied
+// *http.Client, whose transport is the API client's own (see transport.go).
+// It is a package-level var only so tests can point detectClientIP at an
+// httptest server; operators who need a different endpoint list it in
+// client_ip_detection.urls instead.
 var ipDetectionURL = "https://api.ipify.org"
 
 // maxDetectionBodyBytes caps how many bytes are read from the IP-detection
 // response. Any valid IPv4/IPv6 text form fits comfortably under this; the cap
 // guards against a misbehaving endpoint streaming an unbounded body within the
-// request timeout (which bounds elapsed time but not bytes).
+// request timeout (which bounds elapsed time but not bytes). The same cap
+// applies to the output of a detection command.
 const maxDetectionBodyBytes = 512
 
+// Address families accepted by client_ip_detection.address_family. "any"
+// leaves the choice to the network stack, which is what detection did before
+// the setting existed.
+const (
+	clientIPFamilyAny  = "any"
+	clientIPFamilyIPv4 = "ipv4"
+	clientIPFamilyIPv6 = "ipv6"
+)
+
+// defaultClientIPDetectionTimeout is the per-source timeout applied when the
+// client_ip_detection block does not set one. It matches the fixed timeout the
+// single-endpoint detection always used.
+const defaultClientIPDetectionTimeout = "5s"
+
 // detectClientIP fetches this machine's public IP address from ipDetectionURL
 // using the provided httpClient and returns it as a string.
 //
 // The response body is trimmed and validated with net.ParseIP. A non-200
 // status, an unreadable body, or a body that is not a valid IP address each
-// produce an error. TLS verification is never disabled; the transport of the
-// supplied httpClient is used as-is.
+// produce an error. The transport of the supplied httpClient is used as-is, so
+// detection honors the same proxy and TLS settings as the API calls.
 func detectClientIP(ctx context.Context, httpClient *http.Client) (string, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ipDetectionURL, nil)
+	return detectClientIPFromURL(ctx, httpClient, ipDetectionURL)
+}
+
+// detectClientIPFromURL is detectClientIP against an explicit endpoint. It is
+// the HTTP source used by clientIPDetector for every entry in
+// client_ip_detection.urls.
+func detectClientIPFromURL(ctx context.Context, httpClient *http.Client, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return "", fmt.Errorf("building public IP detection request for %s: %w", ipDetectionURL, err)
+		return "", fmt.Errorf("building public IP detection request for %s: %w", url, err)
 	}
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("requesting public IP from %s: %w", ipDetectionURL, err)
+		return "", fmt.Errorf("requesting public IP from %s: %w", url, err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("public IP detection from %s returned HTTP status %d", ipDetectionURL, resp.StatusCode)
+		return "", fmt.Errorf("public IP detection from %s returned HTTP status %d", url, resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDetectionBodyBytes))
 	if err != nil {
-		return "", fmt.Errorf("reading public IP detection response from %s: %w", ipDetectionURL, err)
+		return "", fmt.Errorf("reading public IP detection response from %s: %w", url, err)
 	}
 
 	ip := strings.TrimSpace(string(body))
 	if net.ParseIP(ip) == nil {
-		return "", fmt.Errorf("public IP detection from %s returned %q, which is not a valid IP address", ipDetectionURL, ip)
+		return "", fmt.Errorf("public IP detection from %s returned %q, which is not a valid IP address", url, ip)
+	}
+
+	return ip, nil
+}
+
+// detectClientIPFromCommand runs argv (no shell is involved) and reads the
+// caller's public IP from its standard output, with the same trimming and
+// validation as the HTTP source. It exists for hosts whose only way to learn
+// their egress address is local: a cloud metadata helper, a NAT gateway CLI, or
+// a script that asks the egress proxy.
+func detectClientIPFromCommand(ctx context.Context, argv []string) (string, error) {
+	if len(argv) == 0 || strings.TrimSpace(argv[0]) == "" {
+		return "", errors.New("client_ip_detection.command is empty")
+	}
+	name := strings.Join(argv, " ")
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("running %q: %w: %s", name, err, msg)
+		}
+		return "", fmt.Errorf("running %q: %w", name, err)
+	}
+
+	out := stdout.Bytes()
+	if len(out) > maxDetectionBodyBytes {
+		out = out[:maxDetectionBodyBytes]
+	}
+	ip := strings.TrimSpace(string(out))
+	if net.ParseIP(ip) == nil {
+		return "", fmt.Errorf("public IP detection command %q printed %q, which is not a valid IP address", name, ip)
 	}
 
 	return ip, nil
 }
+
+// clientIPDetector resolves the caller's public IP from the sources configured
+// in the client_ip_detection block: an optional local command, then each URL in
+// order. Sources are tried one at a time, each under its own timeout, so a dead
+// first endpoint costs one timeout rather than the whole budget.
+//
+// With requireAgreement set, a single answer is not trusted: sources keep being
+// queried until two of them report the same address, and detection fails if
+// none agree. That guards against an endpoint behind a different egress path (a
+// split-tunnel VPN, a proxy that only some hosts traverse) silently reporting an
+// address Namecheap will never see.
+type clientIPDetector struct {
+	httpClient       *http.Client
+	urls             []string
+	command          []string
+	family           string
+	timeout          time.Duration
+	requireAgreement bool
+}
+
+// defaultClientIPDetector is the detector an absent client_ip_detection block
+// describes: ipDetectionURL alone, any address family, the default timeout.
+func defaultClientIPDetector() *clientIPDetector {
+	timeout, _ := time.ParseDuration(defaultClientIPDetectionTimeout)
+	return &clientIPDetector{
+		httpClient: &http.Client{},
+		family:     clientIPFamilyAny,
+		timeout:    timeout,
+	}
+}
+
+// clientIPSource is one place an address can be read from, named for the
+// error report.
+type clientIPSource struct {
+	name   string
+	lookup func(ctx context.Context) (string, error)
+}
+
+// sources returns the detector's sources in query order. With neither a command
+// nor URLs configured it falls back to ipDetectionURL, which is exactly what
+// detection did before the block existed.
+func (d *clientIPDetector) sources() []clientIPSource {
+	var sources []clientIPSource
+	if len(d.command) > 0 {
+		argv := d.command
+		sources = append(sources, clientIPSource{
+			name:   fmt.Sprintf("command %q", strings.Join(argv, " ")),
+			lookup: func(ctx context.Context) (string, error) { return detectClientIPFromCommand(ctx, argv) },
+		})
+	}
+
+	urls := d.urls
+	if len(urls) == 0 && len(d.command) == 0 {
+		urls = []string{ipDetectionURL}
+	}
+	for _, url := range urls {
+		sources = append(sources, clientIPSource{
+			name:   url,
+			lookup: func(ctx context.Context) (string, error) { return detectClientIPFromURL(ctx, d.httpClient, url) },
+		})
+	}
+	return sources
+}
+
+// detect queries the sources in order and returns the first acceptable address,
+// or — with requireAgreement — the first address two sources report. Every
+// failure along the way is kept, so the final error names each source and why
+// it was rejected instead of only the last one.
+func (d *clientIPDetector) detect(ctx context.Context) (string, error) {
+	var errs []error
+	seen := map[string]string{}
+
+	for _, source := range d.sources() {
+		lookupCtx, cancel := context.WithTimeout(ctx, d.timeout)
+		ip, err := source.lookup(lookupCtx)
+		cancel()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !clientIPMatchesFamily(ip, d.family) {
+			errs = append(errs, fmt.Errorf("%s returned %s, which is not an %s address", source.name, ip, d.family))
+			continue
+		}
+
+		ip = net.ParseIP(ip).String()
+		if !d.requireAgreement {
+			return ip, nil
+		}
+		if _, ok := seen[ip]; ok {
+			return ip, nil
+		}
+		seen[ip] = source.name
+	}
+
+	if d.requireAgreement && len(seen) > 0 {
+		answers := make([]string, 0, len(seen))
+		for ip, name := range seen {
+			answers = append(answers, fmt.Sprintf("%s from %s", ip, name))
+		}
+		sort.Strings(answers)
+		errs = append(errs, fmt.Errorf("require_agreement is set but no two sources reported the same address (%s)", strings.Join(answers, "; ")))
+	}
+	if len(errs) == 0 {
+		return "", errors.New("no client_ip detection sources are configured")
+	}
+	return "", errors.Join(errs...)
+}
+
+// clientIPMatchesFamily reports whether ip belongs to family. An IPv4-mapped
+// IPv6 address counts as IPv4, since that is the address the API will see.
+func clientIPMatchesFamily(ip, family string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	switch family {
+	case clientIPFamilyIPv4:
+		return parsed.To4() != nil
+	case clientIPFamilyIPv6:
+		return parsed.To4() == nil
+	default:
+		return true
+	}
+}
+
+// clientIPDetectionTransport returns a copy of base whose dialer is pinned to
+// the requested address family, so an "ipv6" preference actually egresses over
+// IPv6 instead of merely filtering the answer of an IPv4 connection. "any"
+// returns base unchanged.
+func clientIPDetectionTransport(base *http.Transport, family string) *http.Transport {
+	network := ""
+	switch family {
+	case clientIPFamilyIPv4:
+		network = "tcp4"
+	case clientIPFamilyIPv6:
+		network = "tcp6"
+	default:
+		return base
+	}
+
+	transport := base.Clone()
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport.DialContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, addr)
+	}
+	return transport
+}
+
+// readCachedClientIP returns the address stored by writeCachedClientIP, if the
+// file exists and holds a valid address of the requested family. It backs the
+// client_ip_cache_file fallback used when every detection source fails.
+func readCachedClientIP(path, family string) (string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading client_ip cache file %s: %w", path, err)
+	}
+	ip := strings.TrimSpace(string(raw))
+	if net.ParseIP(ip) == nil {
+		return "", fmt.Errorf("client_ip cache file %s holds %q, which is not a valid IP address", path, ip)
+	}
+	if !clientIPMatchesFamily(ip, family) {
+		return "", fmt.Errorf("client_ip cache file %s holds %s, which is not an %s address", path, ip, family)
+	}
+	return ip, nil
+}
+
+// writeCachedClientIP records a freshly detected address for later runs. The
+// write goes through a temporary file and a rename so a concurrent run never
+// reads a half-written address.
+func writeCachedClientIP(path, ip string) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".client_ip-*")
+	if err != nil {
+		return fmt.Errorf("writing client_ip cache file %s: %w", path, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.WriteString(ip + "\n"); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("writing client_ip cache file %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing client_ip cache file %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("writing client_ip cache file %s: %w", path, err)
+	}
+	return nil
+}
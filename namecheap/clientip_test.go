@@ -4,7 +4,10 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -90,3 +93,90 @@ func TestDetectClientIPTransportFailure(t *testing.T) {
 	require.Error(t, err)
 	assert.Empty(t, ip)
 }
+
+func TestClientIPDetectorRequireAgreement(t *testing.T) {
+	first := ipServer(t, "203.0.113.7")
+	disagreeing := ipServer(t, "198.51.100.1")
+	agreeing := ipServer(t, "203.0.113.7")
+
+	detector := &clientIPDetector{
+		httpClient:       http.DefaultClient,
+		urls:             []string{first.URL, disagreeing.URL, agreeing.URL},
+		family:           clientIPFamilyAny,
+		timeout:          5 * time.Second,
+		requireAgreement: true,
+	}
+	ip, err := detector.detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7", ip)
+
+	detector.urls = []string{first.URL, disagreeing.URL}
+	_, err = detector.detect(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no two sources reported the same address")
+	assert.Contains(t, err.Error(), "198.51.100.1 from "+disagreeing.URL)
+}
+
+func TestClientIPDetectorAddressFamily(t *testing.T) {
+	// An IPv4 answer under an ipv6 preference is skipped, not accepted: it is
+	// the address of a connection Namecheap will not see.
+	v4 := ipServer(t, "203.0.113.7")
+	v6 := ipServer(t, "2001:db8::7")
+
+	detector := &clientIPDetector{
+		httpClient: http.DefaultClient,
+		urls:       []string{v4.URL, v6.URL},
+		family:     clientIPFamilyIPv6,
+		timeout:    5 * time.Second,
+	}
+	ip, err := detector.detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8::7", ip)
+
+	detector.urls = []string{v4.URL}
+	_, err = detector.detect(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not an ipv6 address")
+}
+
+func TestClientIPDetectorCommandRunsFirst(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo is not available")
+	}
+	server := ipServer(t, "198.51.100.1")
+
+	detector := &clientIPDetector{
+		httpClient: http.DefaultClient,
+		urls:       []string{server.URL},
+		command:    []string{"echo", "203.0.113.7"},
+		family:     clientIPFamilyAny,
+		timeout:    5 * time.Second,
+	}
+	ip, err := detector.detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7", ip)
+}
+
+func TestDetectClientIPFromCommandRejectsBadOutput(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo is not available")
+	}
+	_, err := detectClientIPFromCommand(context.Background(), []string{"echo", "not-an-ip"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a valid IP address")
+
+	_, err = detectClientIPFromCommand(context.Background(), nil)
+	require.Error(t, err)
+}
+
+func TestCachedClientIPRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client_ip")
+	require.NoError(t, writeCachedClientIP(path, "2001:db8::7"))
+
+	ip, err := readCachedClientIP(path, clientIPFamilyAny)
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8::7", ip)
+
+	_, err = readCachedClientIP(path, clientIPFamilyIPv4)
+	require.Error(t, err, "a cached address of the wrong family must not be used")
+}
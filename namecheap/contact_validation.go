@@ -0,0 +1,261 @@
+package namecheap_provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// e164MaxDigits is the most digits an E.164 number has, calling code included.
+const e164MaxDigits = 15
+
+// phoneNationalLength is the range of digits a calling code's numbers have
+// after the code. The ranges are deliberately generous (they span landlines
+// and mobiles); they exist to catch a dropped or doubled digit, not to
+// validate numbering plans.
+type phoneNationalLength struct{ min, max int }
+
+// phoneNationalLengths covers the calling codes of the countries in
+// contactCountryCallingCodes. Numbers under any other code are only held to
+// the E.164 maximum.
+var phoneNationalLengths = map[string]phoneNationalLength{
+	"1":   {10, 10}, // NANP: US, CA and the Caribbean
+	"7":   {10, 10},
+	"27":  {9, 9},
+	"30":  {10, 10},
+	"31":  {9, 9},
+	"32":  {8, 9},
+	"33":  {9, 9},
+	"34":  {9, 9},
+	"36":  {8, 9},
+	"39":  {6, 11},
+	"40":  {9, 9},
+	"41":  {9, 9},
+	"43":  {4, 13},
+	"44":  {9, 10},
+	"45":  {8, 8},
+	"46":  {7, 9},
+	"47":  {8, 8},
+	"48":  {9, 9},
+	"49":  {6, 13},
+	"52":  {10, 10},
+	"55":  {10, 11},
+	"61":  {9, 9},
+	"64":  {8, 10},
+	"65":  {8, 8},
+	"81":  {9, 10},
+	"82":  {9, 10},
+	"86":  {10, 11},
+	"90":  {10, 10},
+	"91":  {10, 10},
+	"351": {9, 9},
+	"353": {7, 9},
+	"358": {5, 12},
+	"380": {9, 9},
+	"420": {9, 9},
+	"971": {8, 9},
+	"972": {8, 9},
+}
+
+// contactCountryCallingCodes maps the countries checked for consistency with
+// the phone number to their calling code.
+var contactCountryCallingCodes = map[string]string{
+	"US": "1", "CA": "1", "RU": "7", "KZ": "7", "ZA": "27", "GR": "30", "NL": "31", "BE": "32", "FR": "33",
+	"ES": "34", "HU": "36", "IT": "39", "RO": "40", "CH": "41", "AT": "43", "GB": "44", "DK": "45", "SE": "46",
+	"NO": "47", "PL": "48", "DE": "49", "MX": "52", "BR": "55", "AU": "61", "NZ": "64", "SG": "65", "JP": "81",
+	"KR": "82", "CN": "86", "TR": "90", "IN": "91", "PT": "351", "IE": "353", "FI": "358", "UA": "380",
+	"CZ": "420", "AE": "971", "IL": "972",
+}
+
+// contactPostalCodePatterns are the postal code formats of the countries the
+// provider checks. Codes for any other country are not checked.
+var contactPostalCodePatterns = map[string]struct {
+	pattern *regexp.Regexp
+	example string
+}{
+	"US": {regexp.MustCompile(`^\d{5}(-\d{4})?$`), "94105 or 94105-1234"},
+	"CA": {regexp.MustCompile(`^[A-Za-z]\d[A-Za-z][ -]?\d[A-Za-z]\d$`), "K1A 0B1"},
+	"GB": {regexp.MustCompile(`^[A-Za-z]{1,2}\d[A-Za-z\d]? ?\d[A-Za-z]{2}$`), "SW1A 1AA"},
+	"PT": {regexp.MustCompile(`^\d{4}-\d{3}$`), "1000-001"},
+	"DE": {regexp.MustCompile(`^\d{5}$`), "10115"},
+	"FR": {regexp.MustCompile(`^\d{5}$`), "75001"},
+	"ES": {regexp.MustCompile(`^\d{5}$`), "28001"},
+	"IT": {regexp.MustCompile(`^\d{5}$`), "00118"},
+	"NL": {regexp.MustCompile(`^\d{4} ?[A-Za-z]{2}$`), "1012 AB"},
+	"BE": {regexp.MustCompile(`^\d{4}$`), "1000"},
+	"AT": {regexp.MustCompile(`^\d{4}$`), "1010"},
+	"CH": {regexp.MustCompile(`^\d{4}$`), "8001"},
+	"DK": {regexp.MustCompile(`^\d{4}$`), "1050"},
+	"NO": {regexp.MustCompile(`^\d{4}$`), "0150"},
+	"SE": {regexp.MustCompile(`^\d{3} ?\d{2}$`), "111 22"},
+	"FI": {regexp.MustCompile(`^\d{5}$`), "00100"},
+	"PL": {regexp.MustCompile(`^\d{2}-\d{3}$`), "00-001"},
+	"AU": {regexp.MustCompile(`^\d{4}$`), "2000"},
+	"NZ": {regexp.MustCompile(`^\d{4}$`), "6011"},
+	"JP": {regexp.MustCompile(`^\d{3}-?\d{4}$`), "100-0001"},
+	"KR": {regexp.MustCompile(`^\d{5}$`), "03187"},
+	"CN": {regexp.MustCompile(`^\d{6}$`), "100000"},
+	"IN": {regexp.MustCompile(`^\d{6}$`), "110001"},
+	"SG": {regexp.MustCompile(`^\d{6}$`), "018956"},
+	"RU": {regexp.MustCompile(`^\d{6}$`), "101000"},
+	"BR": {regexp.MustCompile(`^\d{5}-?\d{3}$`), "01001-000"},
+	"MX": {regexp.MustCompile(`^\d{5}$`), "06000"},
+	"ZA": {regexp.MustCompile(`^\d{4}$`), "0001"},
+}
+
+// contactStateCodes are the accepted state_province values of the countries
+// whose subdivisions are checked: each two-letter code, mapped to its name,
+// which is accepted as well.
+var contactStateCodes = map[string]map[string]string{
+	"US": {
+		"AL": "Alabama", "AK": "Alaska", "AZ": "Arizona", "AR": "Arkansas", "CA": "California", "CO": "Colorado",
+		"CT": "Connecticut", "DE": "Delaware", "FL": "Florida", "GA": "Georgia", "HI": "Hawaii", "ID": "Idaho",
+		"IL": "Illinois", "IN": "Indiana", "IA": "Iowa", "KS": "Kansas", "KY": "Kentucky", "LA": "Louisiana",
+		"ME": "Maine", "MD": "Maryland", "MA": "Massachusetts", "MI": "Michigan", "MN": "Minnesota",
+		"MS": "Mississippi", "MO": "Missouri", "MT": "Montana", "NE": "Nebraska", "NV": "Nevada",
+		"NH": "New Hampshire", "NJ": "New Jersey", "NM": "New Mexico", "NY": "New York", "NC": "North Carolina",
+		"ND": "North Dakota", "OH": "Ohio", "OK": "Oklahoma", "OR": "Oregon", "PA": "Pennsylvania",
+		"RI": "Rhode Island", "SC": "South Carolina", "SD": "South Dakota", "TN": "Tennessee", "TX": "Texas",
+		"UT": "Utah", "VT": "Vermont", "VA": "Virginia", "WA": "Washington", "WV": "West Virginia",
+		"WI": "Wisconsin", "WY": "Wyoming", "DC": "District of Columbia", "PR": "Puerto Rico", "GU": "Guam",
+		"VI": "U.S. Virgin Islands", "AS": "American Samoa", "MP": "Northern Mariana Islands",
+		"UM": "U.S. Minor Outlying Islands", "AA": "Armed Forces Americas", "AE": "Armed Forces Europe",
+		"AP": "Armed Forces Pacific",
+	},
+	"CA": {
+		"AB": "Alberta", "BC": "British Columbia", "MB": "Manitoba", "NB": "New Brunswick",
+		"NL": "Newfoundland and Labrador", "NS": "Nova Scotia", "NT": "Northwest Territories", "NU": "Nunavut",
+		"ON": "Ontario", "PE": "Prince Edward Island", "QC": "Quebec", "SK": "Saskatchewan", "YT": "Yukon",
+	},
+}
+
+// validateContactPhone checks a phone value's +NNN.NNNNNNNNNN shape, the E.164
+// maximum length, and the number of digits after the calling codes listed in
+// phoneNationalLengths.
+func validateContactPhone(v interface{}, k string) (warns []string, errs []error) {
+	phone, ok := v.(string)
+	if !ok || !contactPhoneRegexp.MatchString(phone) {
+		return nil, []error{fmt.Errorf("%s must be in international format +NNN.NNNNNNNNNN (e.g. +1.6613102107), got %q", k, v)}
+	}
+
+	code, national, _ := strings.Cut(strings.TrimPrefix(phone, "+"), ".")
+	if digits := len(code) + len(national); digits > e164MaxDigits {
+		return nil, []error{fmt.Errorf("%s %q has %d digits; an international number has at most %d, calling code included", k, phone, digits, e164MaxDigits)}
+	}
+	if length, ok := phoneNationalLengths[code]; ok && (len(national) < length.min || len(national) > length.max) {
+		want := fmt.Sprintf("%d", length.min)
+		if length.max != length.min {
+			want = fmt.Sprintf("%d to %d", length.min, length.max)
+		}
+		return nil, []error{fmt.Errorf("%s %q has %d digits after the calling code +%s, which takes %s", k, phone, len(national), code, want)}
+	}
+	return nil, nil
+}
+
+// contactFieldsDiagnostics checks the fields of one contact against each
+// other: the phone's calling code against the country (a warning, since a
+// contact may use a phone from abroad), and the postal code and state against
+// the country's formats (errors). fields holds the contact's attributes and
+// path is where they sit in the configuration. Null and unknown values are not
+// checked.
+func contactFieldsDiagnostics(fields map[string]cty.Value, path cty.Path) diag.Diagnostics {
+	value := func(attr string) string {
+		v, ok := fields[attr]
+		if !ok || v.IsNull() || !v.IsKnown() || !v.Type().Equals(cty.String) {
+			return ""
+		}
+		return v.AsString()
+	}
+	attrPath := func(attr string) cty.Path {
+		return append(path.Copy(), cty.GetAttrStep{Name: attr})
+	}
+
+	country := strings.ToUpper(value("country"))
+	if country == "" {
+		return nil
+	}
+
+	var diags diag.Diagnostics
+	if phone, want := value("phone"), contactCountryCallingCodes[country]; phone != "" && want != "" && contactPhoneRegexp.MatchString(phone) {
+		if code, _, _ := strings.Cut(strings.TrimPrefix(phone, "+"), "."); code != want {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Phone number is not from the contact's country",
+				Detail: fmt.Sprintf("The phone number %q has calling code +%s, but country %s uses +%s. Registries may reject "+
+					"or query a contact whose phone and country disagree; check that neither is a typo.", phone, code, country, want),
+				AttributePath: attrPath("phone"),
+			})
+		}
+	}
+
+	if postal, format := value("postal_code"), contactPostalCodePatterns[country]; postal != "" && format.pattern != nil && !format.pattern.MatchString(postal) {
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       "Invalid postal code for " + country,
+			Detail:        fmt.Sprintf("%q is not a postal code of country %s, whose codes look like %s.", postal, country, format.example),
+			AttributePath: attrPath("postal_code"),
+		})
+	}
+
+	if state, codes := value("state_province"), contactStateCodes[country]; state != "" && codes != nil && !isContactState(codes, state) {
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       "Invalid state or province for " + country,
+			Detail:        fmt.Sprintf("%q is not a state or province of country %s. Use its two-letter code or its full name.", state, country),
+			AttributePath: attrPath("state_province"),
+		})
+	}
+	return diags
+}
+
+// isContactState reports whether state is one of codes, by code or by name.
+func isContactState(codes map[string]string, state string) bool {
+	if _, ok := codes[strings.ToUpper(state)]; ok {
+		return true
+	}
+	for _, name := range codes {
+		if strings.EqualFold(name, state) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateContactBlocksRawConfig returns a ValidateRawResourceConfigFunc
+// running contactFieldsDiagnostics on each of the named contact blocks, so the
+// diagnostics point into the block that holds the mistake.
+func validateContactBlocksRawConfig(blocks ...string) schema.ValidateRawResourceConfigFunc {
+	return func(_ context.Context, req schema.ValidateResourceConfigFuncRequest, resp *schema.ValidateResourceConfigFuncResponse) {
+		if req.RawConfig.IsNull() || !req.RawConfig.IsKnown() {
+			return
+		}
+		for _, block := range blocks {
+			list := req.RawConfig.GetAttr(block)
+			if list.IsNull() || !list.IsKnown() {
+				continue
+			}
+			for it := list.ElementIterator(); it.Next(); {
+				index, elem := it.Element()
+				if elem.IsNull() || !elem.IsKnown() {
+					continue
+				}
+				path := cty.Path{cty.GetAttrStep{Name: block}, cty.IndexStep{Key: index}}
+				resp.Diagnostics = append(resp.Diagnostics, contactFieldsDiagnostics(elem.AsValueMap(), path)...)
+			}
+		}
+	}
+}
+
+// validateContactRawConfig is validateContactBlocksRawConfig for a resource
+// whose contact fields are top-level attributes, as namecheap_address's are.
+func validateContactRawConfig(_ context.Context, req schema.ValidateResourceConfigFuncRequest, resp *schema.ValidateResourceConfigFuncResponse) {
+	if req.RawConfig.IsNull() || !req.RawConfig.IsKnown() {
+		return
+	}
+	resp.Diagnostics = append(resp.Diagnostics, contactFieldsDiagnostics(req.RawConfig.AsValueMap(), cty.Path{})...)
+}
@@ -0,0 +1,168 @@
+package namecheap_provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateContactPhone(t *testing.T) {
+	cases := []struct {
+		phone   string
+		wantErr string
+	}{
+		{"+1.6613102107", ""},
+		{"+351.123456789", ""},
+		{"+44.2071234567", ""},
+		{"+44.201234567", ""},
+		{"+49.3012345678901", ""},
+		{"+999.123456", ""}, // unlisted calling codes are only length-checked
+		{"+1.661310210", "has 9 digits after the calling code +1, which takes 10"},
+		{"+351.12345678", "has 8 digits after the calling code +351, which takes 9"},
+		{"+44.20712345678", "which takes 9 to 10"},
+		{"+999.1234567890123", "has 16 digits; an international number has at most 15"},
+		{"16613102107", "must be in international format"},
+		{"", "must be in international format"},
+	}
+	for _, tc := range cases {
+		_, errs := validateContactPhone(tc.phone, "phone")
+		if tc.wantErr == "" {
+			assert.Emptyf(t, errs, "unexpected error for %q", tc.phone)
+			continue
+		}
+		if assert.Lenf(t, errs, 1, "expected an error for %q", tc.phone) {
+			assert.Contains(t, errs[0].Error(), tc.wantErr)
+		}
+	}
+}
+
+func contactFields(overrides map[string]string) map[string]cty.Value {
+	fields := map[string]cty.Value{}
+	for attr, v := range fullContactMap() {
+		fields[attr] = cty.StringVal(v.(string))
+	}
+	for attr, v := range overrides {
+		fields[attr] = cty.StringVal(v)
+	}
+	return fields
+}
+
+func TestContactFieldsDiagnostics(t *testing.T) {
+	path := cty.Path{cty.GetAttrStep{Name: "registrant"}, cty.IndexStep{Key: cty.NumberIntVal(0)}}
+	us := map[string]string{"country": "US", "phone": "+1.6613102107", "postal_code": "94105", "state_province": "CA"}
+	with := func(base map[string]string, attr, value string) map[string]string {
+		out := map[string]string{attr: value}
+		for k, v := range base {
+			if k != attr {
+				out[k] = v
+			}
+		}
+		return out
+	}
+
+	cases := []struct {
+		name     string
+		fields   map[string]cty.Value
+		severity diag.Severity
+		summary  string
+		attr     string
+	}{
+		{name: "complete US contact", fields: contactFields(us)},
+		{name: "US state by name", fields: contactFields(with(us, "state_province", "california"))},
+		{name: "ZIP+4", fields: contactFields(with(us, "postal_code", "94105-1234"))},
+		{name: "unchecked country", fields: contactFields(map[string]string{"country": "AR", "postal_code": "anything", "phone": "+1.6613102107"})},
+		{name: "lower-case country", fields: contactFields(map[string]string{"country": "pt", "postal_code": "1000-001"})},
+		{
+			name: "US state typo", fields: contactFields(with(us, "state_province", "Califronia")),
+			severity: diag.Error, summary: "Invalid state or province for US", attr: "state_province",
+		},
+		{
+			name: "CA province code", fields: contactFields(map[string]string{"country": "CA", "phone": "+1.4165550100", "postal_code": "M5V 2T6", "state_province": "TX"}),
+			severity: diag.Error, summary: "Invalid state or province for CA", attr: "state_province",
+		},
+		{
+			name: "PT postal code", fields: contactFields(map[string]string{"postal_code": "1000001"}),
+			severity: diag.Error, summary: "Invalid postal code for PT", attr: "postal_code",
+		},
+		{
+			name: "phone from another country", fields: contactFields(map[string]string{"phone": "+44.2071234567"}),
+			severity: diag.Warning, summary: "Phone number is not from the contact's country", attr: "phone",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			diags := contactFieldsDiagnostics(tc.fields, path)
+			if tc.summary == "" {
+				assert.Empty(t, diags)
+				return
+			}
+			require.Len(t, diags, 1)
+			assert.Equal(t, tc.severity, diags[0].Severity)
+			assert.Equal(t, tc.summary, diags[0].Summary)
+			assert.Equal(t, append(path.Copy(), cty.GetAttrStep{Name: tc.attr}), diags[0].AttributePath)
+		})
+	}
+
+	unknown := contactFields(map[string]string{"postal_code": "nope"})
+	unknown["country"] = cty.UnknownVal(cty.String)
+	assert.Empty(t, contactFieldsDiagnostics(unknown, path), "nothing is checked against an unknown country")
+}
+
+func TestValidateContactBlocksRawConfig(t *testing.T) {
+	resource := resourceNamecheapDomainContacts()
+	schemaType := schema.InternalMap(resource.Schema).CoreConfigSchema().ImpliedType()
+
+	block := func(overrides map[string]string) cty.Value {
+		attrs := map[string]cty.Value{contactAddressIDAttr: cty.NullVal(cty.Number)}
+		for attr, v := range contactFields(overrides) {
+			attrs[attr] = v
+		}
+		return cty.ListVal([]cty.Value{cty.ObjectVal(attrs)})
+	}
+	attrs := map[string]cty.Value{}
+	for name, attrType := range schemaType.AttributeTypes() {
+		attrs[name] = cty.NullVal(attrType)
+	}
+	attrs["domain"] = cty.StringVal("example.com")
+	attrs["registrant"] = block(nil)
+	attrs["admin"] = block(map[string]string{"postal_code": "1000"})
+	config := cty.ObjectVal(attrs)
+
+	var resp schema.ValidateResourceConfigFuncResponse
+	for _, f := range resource.ValidateRawResourceConfigFuncs {
+		f(context.Background(), schema.ValidateResourceConfigFuncRequest{RawConfig: config}, &resp)
+	}
+
+	require.Len(t, resp.Diagnostics, 1)
+	assert.Equal(t, "Invalid postal code for PT", resp.Diagnostics[0].Summary)
+	assert.Equal(t, cty.Path{
+		cty.GetAttrStep{Name: "admin"}, cty.IndexStep{Key: cty.NumberIntVal(0)}, cty.GetAttrStep{Name: "postal_code"},
+	}, resp.Diagnostics[0].AttributePath)
+}
+
+func TestAddressValidateRawConfig(t *testing.T) {
+	resource := resourceNamecheapAddress()
+	schemaType := schema.InternalMap(resource.Schema).CoreConfigSchema().ImpliedType()
+
+	attrs := map[string]cty.Value{}
+	for name, attrType := range schemaType.AttributeTypes() {
+		attrs[name] = cty.NullVal(attrType)
+	}
+	for attr, v := range contactFields(map[string]string{"country": "US", "phone": "+1.6613102107", "state_province": "ZZ", "postal_code": "94105"}) {
+		attrs[attr] = v
+	}
+
+	var resp schema.ValidateResourceConfigFuncResponse
+	for _, f := range resource.ValidateRawResourceConfigFuncs {
+		f(context.Background(), schema.ValidateResourceConfigFuncRequest{RawConfig: cty.ObjectVal(attrs)}, &resp)
+	}
+
+	require.Len(t, resp.Diagnostics, 1)
+	assert.Equal(t, cty.GetAttrPath("state_province"), resp.Diagnostics[0].AttributePath)
+}
@@ -0,0 +1,161 @@
+package namecheap_provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests drive the namecheap_api_access ReadContext against an in-process
+// API server, in the same style as data_source_pricing_read_test.go, with the
+// client_ip detection pointed at a second server standing in for api.ipify.org.
+
+// withAPIAccessConfig returns the provider meta of client with a detection that
+// answers detectedIP, as configureContext would have built it.
+func withAPIAccessConfig(t *testing.T, client *namecheap.Client, detectedIP string) *providerMeta {
+	t.Helper()
+	return &providerMeta{client: client, config: &providerConfig{
+		clientIPDetector: &clientIPDetector{
+			httpClient: http.DefaultClient,
+			urls:       []string{ipServer(t, detectedIP).URL},
+			family:     clientIPFamilyAny,
+			timeout:    5 * time.Second,
+		},
+		clientIPSource: clientIPSourceConfigured,
+	}}
+}
+
+func readAPIAccess(t *testing.T, meta *providerMeta) *schema.ResourceData {
+	t.Helper()
+	d := schema.TestResourceDataRaw(t, dataSourceNamecheapAPIAccess().Schema, map[string]interface{}{})
+	diags := dataSourceNamecheapAPIAccessRead(context.Background(), d, meta)
+	require.False(t, diags.HasError(), "a failed check is reported through attributes, not diagnostics: %+v", diags)
+	assert.Equal(t, "api_access", d.Id())
+	return d
+}
+
+func TestDataSourceAPIAccessRead_Success(t *testing.T) {
+	client := startDataSourceServer(t, func(command string, _ *http.Request) string {
+		if command == "namecheap.users.getBalances" {
+			return xmlGetBalances("USD", "1.00", "1.00", "0.00", "0.00", "0.00")
+		}
+		return apiErrorXML("1010101", "unexpected command "+command)
+	})
+	meta := withAPIAccessConfig(t, client, "127.0.0.1")
+
+	d := readAPIAccess(t, meta)
+	assert.True(t, d.Get("ok").(bool))
+	assert.True(t, d.Get("api_reachable").(bool))
+	assert.True(t, d.Get("credentials_valid").(bool))
+	assert.True(t, d.Get("ip_whitelisted").(bool))
+	assert.True(t, d.Get("client_ip_matches_detected").(bool))
+	assert.Equal(t, "127.0.0.1", d.Get("detected_ip"))
+	assert.Equal(t, "configured", d.Get("client_ip_source"))
+	assert.Equal(t, "custom", d.Get("environment"))
+	assert.Equal(t, 0, d.Get("error_code"))
+	assert.Contains(t, d.Get("verdict"), "API access works")
+}
+
+func TestDataSourceAPIAccessRead_CredentialsRejected(t *testing.T) {
+	client := startDataSourceServer(t, func(string, *http.Request) string {
+		return apiErrorXML("1011102", "API Key is invalid or API access has not been enabled")
+	})
+	meta := withAPIAccessConfig(t, client, "127.0.0.1")
+
+	d := readAPIAccess(t, meta)
+	assert.False(t, d.Get("ok").(bool))
+	assert.True(t, d.Get("api_reachable").(bool))
+	assert.False(t, d.Get("credentials_valid").(bool))
+	assert.False(t, d.Get("ip_whitelisted").(bool), "the whitelist is never reached with rejected credentials")
+	assert.Equal(t, 1011102, d.Get("error_code"))
+	assert.Contains(t, d.Get("verdict"), "rejected the credentials")
+	assert.Contains(t, d.Get("verdict"), "use_sandbox is false")
+}
+
+func TestDataSourceAPIAccessRead_IPNotWhitelisted(t *testing.T) {
+	client := startDataSourceServer(t, func(string, *http.Request) string {
+		return apiErrorXML("1011150", "Invalid request IP: 127.0.0.1")
+	})
+	// The machine really calls from another address, which is the fix the
+	// verdict should point at.
+	meta := withAPIAccessConfig(t, client, "198.51.100.9")
+
+	d := readAPIAccess(t, meta)
+	assert.False(t, d.Get("ok").(bool))
+	assert.True(t, d.Get("credentials_valid").(bool))
+	assert.False(t, d.Get("ip_whitelisted").(bool))
+	assert.False(t, d.Get("client_ip_matches_detected").(bool))
+	assert.Equal(t, "198.51.100.9", d.Get("detected_ip"))
+
+	verdict := d.Get("verdict").(string)
+	assert.Contains(t, verdict, "The credentials are valid")
+	assert.Contains(t, verdict, "this machine's public IP is 198.51.100.9")
+}
+
+func TestDataSourceAPIAccessRead_CommandErrorMeansAuthenticated(t *testing.T) {
+	// An error from the command itself can only come after authentication and
+	// the whitelist have both passed.
+	client := startDataSourceServer(t, func(string, *http.Request) string {
+		return apiErrorXML("4011331", "Failed to retrieve balances")
+	})
+	meta := withAPIAccessConfig(t, client, "127.0.0.1")
+
+	d := readAPIAccess(t, meta)
+	assert.True(t, d.Get("ok").(bool))
+	assert.Equal(t, 4011331, d.Get("error_code"))
+}
+
+func TestDataSourceAPIAccessRead_Unreachable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	srv.Close()
+	client := namecheap.NewClient(&namecheap.ClientOptions{
+		UserName:  "unit-user",
+		ApiUser:   "unit-user",
+		ApiKey:    "unit-key",
+		ClientIp:  "127.0.0.1",
+		RateLimit: &namecheap.RateLimitOptions{Disabled: true},
+		Retry:     &namecheap.RetryOptions{MaxAttempts: 1},
+	})
+	client.BaseURL = srv.URL
+	meta := &providerMeta{client: client, config: &providerConfig{
+		clientIPDetector: &clientIPDetector{
+			httpClient: http.DefaultClient,
+			urls:       []string{closedServerURL()},
+			family:     clientIPFamilyAny,
+			timeout:    time.Second,
+		},
+		clientIPSource: clientIPSourceDetected,
+	}}
+
+	d := readAPIAccess(t, meta)
+	assert.False(t, d.Get("ok").(bool))
+	assert.False(t, d.Get("api_reachable").(bool))
+	assert.False(t, d.Get("credentials_valid").(bool))
+	assert.Empty(t, d.Get("detected_ip"))
+
+	verdict := d.Get("verdict").(string)
+	assert.Contains(t, verdict, "could not be reached")
+	assert.Contains(t, verdict, "could not be detected")
+}
+
+func TestAPIEnvironment(t *testing.T) {
+	assert.Equal(t, "production", apiEnvironment("https://api.namecheap.com/xml.response"))
+	assert.Equal(t, "sandbox", apiEnvironment("https://api.sandbox.namecheap.com/xml.response"))
+	assert.Equal(t, "custom", apiEnvironment("http://127.0.0.1:8080"))
+}
+
+func TestDefaultProviderConfig(t *testing.T) {
+	// The settings of an empty provider block, which tests building a client
+	// directly run with.
+	config := defaultProviderConfig()
+	require.NotNil(t, config.clientIPDetector)
+	assert.Equal(t, clientIPSourceConfigured, config.clientIPSource)
+	assert.Equal(t, ipDetectionURL, config.clientIPDetector.sources()[0].name)
+}
@@ -31,38 +31,67 @@ var domainLifecycleAttrs = []string{
 // ListType/SearchTerm params (searchTerm is omitted when empty).
 func fetchAllDomains(ctx context.Context, client *namecheap.Client, listType, searchTerm string) ([]namecheap.Domain, error) {
 	var all []namecheap.Domain
+	err := walkDomains(ctx, client, domainsQuery{listType: listType, searchTerm: searchTerm}, func(page []namecheap.Domain) bool {
+		all = append(all, page...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// domainsQuery holds the getList parameters walkDomains passes through. Empty
+// searchTerm and sortBy are omitted from the request.
+type domainsQuery struct {
+	listType   string
+	searchTerm string
+	sortBy     string
+}
+
+// walkDomains pages through namecheap.domains.getList, handing each page to
+// visit in order, until every page is read or visit returns false. A caller
+// that needs only the first results stops there rather than spending a
+// rate-limited request on every remaining page.
+func walkDomains(ctx context.Context, client *namecheap.Client, query domainsQuery, visit func(page []namecheap.Domain) bool) error {
 	for page := 1; ; page++ {
 		args := &namecheap.DomainsGetListArgs{
-			ListType: namecheap.String(listType),
+			ListType: namecheap.String(query.listType),
 			Page:     namecheap.Int(page),
 			PageSize: namecheap.Int(domainsPageSize),
 		}
-		if searchTerm != "" {
-			args.SearchTerm = namecheap.String(searchTerm)
+		if query.searchTerm != "" {
+			args.SearchTerm = namecheap.String(query.searchTerm)
+		}
+		if query.sortBy != "" {
+			args.SortBy = namecheap.String(query.sortBy)
 		}
 
 		resp, err := client.Domains.GetListWithContext(ctx, args)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if resp == nil {
-			return nil, fmt.Errorf("empty response from Namecheap while listing domains (page %d)", page)
+			return fmt.Errorf("empty response from Namecheap while listing domains (page %d)", page)
 		}
+		var domains []namecheap.Domain
 		if resp.Domains != nil {
-			all = append(all, *resp.Domains...)
+			domains = *resp.Domains
+		}
+		if !visit(domains) {
+			return nil
 		}
 
 		// Stop when the paging block indicates every item has been fetched. When
 		// paging is absent or degenerate, stop after the current page so a
 		// malformed response cannot spin an unbounded loop.
 		if resp.Paging == nil || resp.Paging.TotalItems == nil || resp.Paging.PageSize == nil || *resp.Paging.PageSize <= 0 {
-			break
+			return nil
 		}
 		if page*(*resp.Paging.PageSize) >= *resp.Paging.TotalItems {
-			break
+			return nil
 		}
 	}
-	return all, nil
 }
 
 // setDomainLifecycleFromList fetches domain from the account portfolio listing
@@ -5,7 +5,6 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
 )
 
 // accountBalanceID is the synthetic ID of the account-balance data source. The
@@ -63,7 +62,7 @@ func dataSourceNamecheapAccountBalance() *schema.Resource {
 }
 
 func dataSourceNamecheapAccountBalanceRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 
 	resp, err := client.Users.GetBalancesWithContext(ctx)
 	if err != nil {
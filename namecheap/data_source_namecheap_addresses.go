@@ -0,0 +1,84 @@
+package namecheap_provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// addressesID is the synthetic ID of the addresses data source, which lists
+// the account's address book rather than any one entry.
+const addressesID = "addresses"
+
+// dataSourceNamecheapAddresses lists the account's address book
+// (namecheap.users.address.getList), chiefly to look up the address_id of an
+// entry managed elsewhere — in the dashboard, or in another configuration —
+// for use in namecheap_domain_contacts.
+func dataSourceNamecheapAddresses() *schema.Resource {
+	return &schema.Resource{
+		Description: "Lists the entries of the account's address book, optionally filtered by name, so namecheap_domain_contacts can reference one by address_id.",
+		ReadContext: dataSourceNamecheapAddressesRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only list entries with this name, compared case-insensitively. Leave unset to list every entry.",
+			},
+			"addresses": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The matching address book entries, in the order Namecheap lists them.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The entry's address id, as address_id in namecheap_domain_contacts expects it.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The entry's name.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceNamecheapAddressesRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	name := strings.TrimSpace(data.Get("name").(string))
+
+	entries, err := client.UsersAddress.ListAllSlice(ctx)
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	addresses := make([]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		if entry == nil || entry.AddressID == nil {
+			continue
+		}
+		if name != "" && !strings.EqualFold(derefString(entry.AddressName), name) {
+			continue
+		}
+		addresses = append(addresses, map[string]interface{}{
+			"id":   *entry.AddressID,
+			"name": derefString(entry.AddressName),
+		})
+	}
+	if err := data.Set("addresses", addresses); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if name != "" {
+		data.SetId(addressesID + "/" + strings.ToLower(name))
+	} else {
+		data.SetId(addressesID)
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package namecheap_provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+)
+
+// apiAccessID is the synthetic ID of the API-access data source. Like the
+// account balance, it describes the provider's own configuration, so there is
+// nothing to key the ID on.
+const apiAccessID = "api_access"
+
+// Hosts of the two endpoints the SDK selects between with use_sandbox.
+const (
+	namecheapProductionAPIHost = "api.namecheap.com"
+	namecheapSandboxAPIHost    = "api.sandbox.namecheap.com"
+)
+
+// Namecheap global response codes that reject the caller before the command
+// runs. 1011102 is the one diagFromClientError has to explain four ways; the
+// IP codes below are what Namecheap answers instead once the API user and key
+// are recognized, which is what lets a pre-flight tell the causes apart.
+var (
+	apiAccessCredentialErrors = map[int]bool{
+		1010101: true, // APIUser missing
+		1010102: true, // APIKey missing
+		1011101: true, // APIUser invalid
+		1011102: true, // APIKey invalid, or API access not enabled
+		1016103: true, // UserName unauthorized
+		1017101: true, // APIUser disabled or locked
+		1017103: true, // UserName disabled or locked
+		1019103: true, // UserName not available
+		1050900: true, // unknown error validating the APIUser
+	}
+	apiAccessIPErrors = map[int]bool{
+		1010105: true, // ClientIP missing
+		1011105: true, // ClientIP invalid
+		1011150: true, // RequestIP invalid: the calling IP is not whitelisted
+		1017105: true, // ClientIP disabled or locked
+		1017150: true, // RequestIP disabled or locked
+	}
+)
+
+// dataSourceNamecheapAPIAccess is a pre-flight for the provider configuration:
+// one cheap authenticated call plus a public IP lookup, each outcome reported as
+// its own attribute so a pipeline can fail on the actual cause rather than on
+// the catch-all authentication error a resource would report.
+func dataSourceNamecheapAPIAccess() *schema.Resource {
+	return &schema.Resource{
+		Description: "Checks that the provider's credentials, client_ip and endpoint work together, reporting each check separately with a human-readable verdict, so CI can fail fast on the real cause of an authentication error.",
+		ReadContext: dataSourceNamecheapAPIAccessRead,
+		Schema: map[string]*schema.Schema{
+			"ok": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "True when the API was reachable, the credentials were accepted and the calling IP is whitelisted. Use it in a postcondition with verdict as the error message.",
+			},
+			"verdict": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "A one-paragraph explanation of the result: what passed, what failed, and the likely fix.",
+			},
+			"endpoint": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The API endpoint the provider calls.",
+			},
+			"environment": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Which Namecheap environment the endpoint belongs to: \"production\", \"sandbox\", or \"custom\" for any other host.",
+			},
+			"use_sandbox": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "The provider's use_sandbox setting, to compare against environment.",
+			},
+			"api_reachable": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "True when the endpoint answered with an API response, whatever its outcome.",
+			},
+			"credentials_valid": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "True when the endpoint accepted user_name, api_user and api_key. False when they were rejected or the endpoint was unreachable.",
+			},
+			"ip_whitelisted": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "True only when a call from client_ip was confirmed to pass the account's IP whitelist. Namecheap checks the whitelist after the credentials, so this is also false when the credentials were rejected.",
+			},
+			"client_ip": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The client_ip the provider sends to Namecheap.",
+			},
+			"client_ip_source": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Where client_ip came from: \"configured\", \"detected\" or \"cache\" (client_ip_cache_file).",
+			},
+			"detected_ip": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "This machine's public IP as reported by the client_ip_detection sources now, or empty when it could not be detected.",
+			},
+			"client_ip_matches_detected": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "True when client_ip equals detected_ip. A mismatch means the address sent to Namecheap is not the one this machine currently calls from.",
+			},
+			"error_code": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The Namecheap error number the check call failed with, or 0 when it succeeded or no API response was received.",
+			},
+		},
+	}
+}
+
+// apiAccessReport is the outcome of checkAPIAccess, shared by the data source
+// and the provider's verify_credentials_on_configure flag.
+type apiAccessReport struct {
+	endpoint, environment    string
+	useSandbox               bool
+	apiReachable             bool
+	credentialsValid         bool
+	ipWhitelisted            bool
+	clientIP, clientIPSource string
+	detectedIP               string
+	clientIPMatchesDetected  bool
+	detectErr, callErr       error
+	errorCode                int
+	ok                       bool
+	verdict                  string
+}
+
+// checkAPIAccess runs the pre-flight: a public IP lookup through the
+// configured detection sources, then namecheap.users.getBalances, the cheapest
+// authenticated command (no parameters, no side effects).
+func checkAPIAccess(ctx context.Context, client *namecheap.Client, config *providerConfig) *apiAccessReport {
+	report := &apiAccessReport{
+		endpoint:       client.BaseURL,
+		environment:    apiEnvironment(client.BaseURL),
+		useSandbox:     client.ClientOptions.UseSandbox,
+		clientIP:       client.ClientOptions.ClientIp,
+		clientIPSource: config.clientIPSource,
+	}
+
+	detected, err := config.clientIPDetector.detect(ctx)
+	if err != nil {
+		report.detectErr = err
+	} else {
+		report.detectedIP = detected
+		report.clientIPMatchesDetected = sameIP(detected, report.clientIP)
+	}
+
+	_, err = client.Users.GetBalancesWithContext(ctx)
+	report.callErr = err
+	var apiErr *namecheap.APIError
+	switch {
+	case err == nil:
+		report.apiReachable, report.credentialsValid, report.ipWhitelisted = true, true, true
+	case errors.As(err, &apiErr):
+		report.apiReachable = true
+		report.errorCode = apiErr.Number
+		switch {
+		case apiAccessCredentialErrors[apiErr.Number]:
+			// Rejected before the whitelist was ever evaluated: both stay false.
+		case apiAccessIPErrors[apiErr.Number]:
+			report.credentialsValid = true
+		default:
+			// Anything else came from the command itself, which only runs
+			// once authentication and the whitelist have both passed.
+			report.credentialsValid, report.ipWhitelisted = true, true
+		}
+	}
+
+	report.ok = report.apiReachable && report.credentialsValid && report.ipWhitelisted
+	report.verdict = apiAccessVerdict(report)
+	return report
+}
+
+// apiAccessVerdict explains a report in the order a reader needs it: the
+// first failing check, its fix, then anything about client_ip that bears on it.
+func apiAccessVerdict(r *apiAccessReport) string {
+	var parts []string
+	where := fmt.Sprintf("the %s endpoint (%s)", r.environment, r.endpoint)
+
+	switch {
+	case !r.apiReachable:
+		parts = append(parts, fmt.Sprintf("The Namecheap API at %s could not be reached: %s. Check network egress, proxy_url and ca_bundle_file.", where, r.callErr))
+	case !r.credentialsValid:
+		other := "sandbox"
+		if r.useSandbox {
+			other = "production"
+		}
+		parts = append(parts, fmt.Sprintf("%s rejected the credentials (%s). Check that api_user and api_key belong to the %s environment "+
+			"(%s credentials do not work there; use_sandbox is %t) and that API access is enabled for the account at "+
+			"https://ap.www.namecheap.com/settings/tools/apiaccess/.", capitalize(where), r.callErr, r.environment, other, r.useSandbox))
+	case !r.ipWhitelisted:
+		parts = append(parts, fmt.Sprintf("The credentials are valid, but %s rejected client_ip %s (%s). Whitelist it at "+
+			"https://ap.www.namecheap.com/settings/tools/apiaccess/whitelisted-ips.", where, r.clientIP, r.callErr))
+	default:
+		parts = append(parts, fmt.Sprintf("API access works: %s accepted the credentials from client_ip %s.", where, r.clientIP))
+	}
+
+	if r.useSandbox != (r.environment == "sandbox") && r.environment != "custom" {
+		parts = append(parts, fmt.Sprintf("use_sandbox is %t but the endpoint is %s.", r.useSandbox, r.environment))
+	}
+
+	switch {
+	case r.detectErr != nil:
+		parts = append(parts, fmt.Sprintf("This machine's public IP could not be detected (%s), so client_ip (%s, %s) was not cross-checked.", r.detectErr, r.clientIP, r.clientIPSource))
+	case !r.clientIPMatchesDetected:
+		parts = append(parts, fmt.Sprintf("client_ip is %s (%s) but this machine's public IP is %s; if Namecheap traffic leaves from %s, set client_ip to it and whitelist it.",
+			r.clientIP, r.clientIPSource, r.detectedIP, r.detectedIP))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// apiEnvironment names the Namecheap environment endpoint belongs to.
+func apiEnvironment(endpoint string) string {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "custom"
+	}
+	switch strings.ToLower(parsed.Hostname()) {
+	case namecheapProductionAPIHost:
+		return "production"
+	case namecheapSandboxAPIHost:
+		return "sandbox"
+	default:
+		return "custom"
+	}
+}
+
+// sameIP compares two textual addresses by value, so "::ffff:192.0.2.1" and
+// "192.0.2.1" count as the same address.
+func sameIP(a, b string) bool {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	return ipA != nil && ipB != nil && ipA.Equal(ipB)
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func dataSourceNamecheapAPIAccessRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*providerMeta)
+	report := checkAPIAccess(ctx, m.client, m.config)
+
+	_ = data.Set("ok", report.ok)
+	_ = data.Set("verdict", report.verdict)
+	_ = data.Set("endpoint", report.endpoint)
+	_ = data.Set("environment", report.environment)
+	_ = data.Set("use_sandbox", report.useSandbox)
+	_ = data.Set("api_reachable", report.apiReachable)
+	_ = data.Set("credentials_valid", report.credentialsValid)
+	_ = data.Set("ip_whitelisted", report.ipWhitelisted)
+	_ = data.Set("client_ip", report.clientIP)
+	_ = data.Set("client_ip_source", report.clientIPSource)
+	_ = data.Set("detected_ip", report.detectedIP)
+	_ = data.Set("client_ip_matches_detected", report.clientIPMatchesDetected)
+	_ = data.Set("error_code", report.errorCode)
+
+	data.SetId(apiAccessID)
+	return nil
+}
@@ -7,7 +7,6 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
 )
 
 // dataSourceNamecheapDomain exposes read-only information about a single domain.
@@ -167,7 +166,7 @@ func dataSourceNamecheapDomain() *schema.Resource {
 }
 
 func dataSourceNamecheapDomainRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 	domain := strings.ToLower(data.Get("domain").(string))
 
 	resp, err := client.Domains.GetInfoWithContext(ctx, domain)
@@ -2,19 +2,33 @@ package namecheap_provider
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 // dataSourceNamecheapDomainRecords exposes a read-only view of a domain's live
 // DNS record set via namecheap.domains.dns.getHosts, together with the domain's
 // nameservers (namecheap.domains.dns.getList) and email routing type. The record
 // object shape mirrors the namecheap_domain_records resource so the output
-// composes into resource inputs without transformation.
+// composes into resource inputs without transformation; fqdn is the one
+// read-only addition. filter blocks narrow the record set, and records_by_type
+// and records_by_hostname group what is left.
 func dataSourceNamecheapDomainRecords() *schema.Resource {
+	recordSchema := domainRecordElemSchema()
+	recordSchema["fqdn"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The fully qualified name of the record, without a trailing dot (e.g. www.example.com, or example.com for @).",
+	}
+
 	return &schema.Resource{
 		Description: "Reads the DNS host records currently published for a domain, in the same shape the namecheap_domain_records resource accepts.",
 		ReadContext: dataSourceNamecheapDomainRecordsRead,
@@ -25,6 +39,45 @@ func dataSourceNamecheapDomainRecords() *schema.Resource {
 				Description:  "The domain whose DNS records to read (e.g. example.com). Must be a registered root domain, not a subdomain.",
 				ValidateFunc: validateDomainIsNotSubdomain,
 			},
+			"filter": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Narrows records to the records matching at least one filter block. A block matches a record when every condition set in it holds; without filter blocks every record is returned.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"hostname": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Glob the record hostname must match, case-insensitively (e.g. www, _*, *.dev): * matches any run of characters, ? one character.",
+							ValidateFunc: validateHostnameGlob,
+						},
+						"types": {
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Record types the record must be one of, case-insensitively (e.g. [\"A\", \"AAAA\"]).",
+						},
+						"address": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Regular expression (RE2 syntax) the record address must match somewhere; anchor it with ^ and $ to match the whole address.",
+							ValidateFunc: validation.StringIsValidRegExp,
+						},
+						"min_ttl": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Description:  "Lowest TTL, in seconds, the record may have.",
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+						"max_ttl": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Description:  "Highest TTL, in seconds, the record may have.",
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+					},
+				},
+			},
 			"email_type": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -39,19 +92,36 @@ func dataSourceNamecheapDomainRecords() *schema.Resource {
 			"records": {
 				Type:        schema.TypeList,
 				Computed:    true,
-				Description: "The live DNS host records for the domain. Field shapes mirror the namecheap_domain_records resource record block.",
+				Description: "The live DNS host records for the domain that match the filter blocks. Field shapes mirror the namecheap_domain_records resource record block, plus fqdn.",
 				Elem: &schema.Resource{
-					Schema: domainRecordElemSchema(),
+					Schema: recordSchema,
 				},
 			},
+			"records_by_type": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The records, grouped by record type. Each value is a JSON-encoded list of record objects; read it with jsondecode (e.g. jsondecode(records_by_type[\"MX\"])).",
+			},
+			"records_by_hostname": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The records, grouped by hostname. Each value is a JSON-encoded list of record objects; read it with jsondecode (e.g. jsondecode(records_by_hostname[\"www\"])).",
+			},
 		},
 	}
 }
 
 func dataSourceNamecheapDomainRecordsRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 	domain := strings.ToLower(data.Get("domain").(string))
 
+	filters, err := expandDomainRecordFilters(data.Get("filter").([]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
 	// Read the DNS/nameserver state first (mirrors the resource read ordering):
 	// a domain on custom nameservers exposes those, otherwise the record set.
 	nsResp, err := client.DomainsDNS.GetListWithContext(ctx, domain)
@@ -74,8 +144,8 @@ func dataSourceNamecheapDomainRecordsRead(ctx context.Context, data *schema.Reso
 		if err := data.Set("nameservers", nameservers); err != nil {
 			return diag.FromErr(err)
 		}
-		if err := data.Set("records", []map[string]interface{}{}); err != nil {
-			return diag.FromErr(err)
+		if diags := setDataSourceRecords(data, nil); diags != nil {
+			return diags
 		}
 		if err := data.Set("email_type", ""); err != nil {
 			return diag.FromErr(err)
@@ -109,13 +179,159 @@ func dataSourceNamecheapDomainRecordsRead(ctx context.Context, data *schema.Reso
 		// source into that resource writes the parking records back and drifts.
 		filtered := filterDefaultParkingRecords(hostsResp.DomainDNSGetHostsResult.Hosts, &domain)
 		for i := range *filtered {
-			records = append(records, flattenHostRecord(&(*filtered)[i]))
+			record := flattenHostRecord(&(*filtered)[i])
+			record["fqdn"] = domainRecordFQDN(derefString((*filtered)[i].Name), domain)
+			if domainRecordFiltersMatch(filters, record) {
+				records = append(records, record)
+			}
 		}
 	}
+	if diags := setDataSourceRecords(data, records); diags != nil {
+		return diags
+	}
+
+	data.SetId(domain)
+	return nil
+}
+
+// setDataSourceRecords sets records and the maps that group them.
+func setDataSourceRecords(data *schema.ResourceData, records []map[string]interface{}) diag.Diagnostics {
+	if records == nil {
+		records = []map[string]interface{}{}
+	}
 	if err := data.Set("records", records); err != nil {
 		return diag.FromErr(err)
 	}
 
-	data.SetId(domain)
+	byType, err := groupDomainRecords(records, "type")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := data.Set("records_by_type", byType); err != nil {
+		return diag.FromErr(err)
+	}
+
+	byHostname, err := groupDomainRecords(records, "hostname")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := data.Set("records_by_hostname", byHostname); err != nil {
+		return diag.FromErr(err)
+	}
 	return nil
 }
+
+// groupDomainRecords groups records by the value of key, keeping their order.
+// A map attribute can only hold strings, so each group is JSON-encoded.
+func groupDomainRecords(records []map[string]interface{}, key string) (map[string]string, error) {
+	groups := map[string][]map[string]interface{}{}
+	for _, record := range records {
+		value := record[key].(string)
+		groups[value] = append(groups[value], record)
+	}
+
+	encoded := make(map[string]string, len(groups))
+	for value, group := range groups {
+		b, err := json.Marshal(group)
+		if err != nil {
+			return nil, err
+		}
+		encoded[value] = string(b)
+	}
+	return encoded, nil
+}
+
+// domainRecordFQDN returns the fully qualified name of a record on domain.
+func domainRecordFQDN(hostname, domain string) string {
+	if hostname == "" || hostname == "@" {
+		return domain
+	}
+	return strings.ToLower(hostname) + "." + domain
+}
+
+// domainRecordFilter is one filter block. Unset conditions are zero values.
+type domainRecordFilter struct {
+	hostname string
+	types    []string
+	address  *regexp.Regexp
+	minTTL   int
+	maxTTL   int
+}
+
+func expandDomainRecordFilters(raw []interface{}) ([]domainRecordFilter, error) {
+	filters := make([]domainRecordFilter, 0, len(raw))
+	for i, r := range raw {
+		// An empty block matches every record.
+		m, _ := r.(map[string]interface{})
+		if m == nil {
+			filters = append(filters, domainRecordFilter{})
+			continue
+		}
+
+		f := domainRecordFilter{
+			hostname: strings.ToLower(m["hostname"].(string)),
+			minTTL:   m["min_ttl"].(int),
+			maxTTL:   m["max_ttl"].(int),
+		}
+		if types, ok := m["types"].(*schema.Set); ok {
+			for _, t := range types.List() {
+				f.types = append(f.types, strings.ToUpper(t.(string)))
+			}
+		}
+		if address := m["address"].(string); address != "" {
+			re, err := regexp.Compile(address)
+			if err != nil {
+				return nil, fmt.Errorf("filter %d: invalid address regular expression: %w", i, err)
+			}
+			f.address = re
+		}
+		if f.maxTTL != 0 && f.minTTL > f.maxTTL {
+			return nil, fmt.Errorf("filter %d: min_ttl (%d) is greater than max_ttl (%d)", i, f.minTTL, f.maxTTL)
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// domainRecordFiltersMatch reports whether record, as flattened by
+// flattenHostRecord, matches at least one filter; no filters match everything.
+func domainRecordFiltersMatch(filters []domainRecordFilter, record map[string]interface{}) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, f := range filters {
+		if f.matches(record) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f domainRecordFilter) matches(record map[string]interface{}) bool {
+	if f.hostname != "" {
+		if ok, _ := path.Match(f.hostname, strings.ToLower(record["hostname"].(string))); !ok {
+			return false
+		}
+	}
+	if len(f.types) > 0 && !slices.Contains(f.types, strings.ToUpper(record["type"].(string))) {
+		return false
+	}
+	if f.address != nil && !f.address.MatchString(record["address"].(string)) {
+		return false
+	}
+	ttl := record["ttl"].(int)
+	if f.minTTL != 0 && ttl < f.minTTL {
+		return false
+	}
+	if f.maxTTL != 0 && ttl > f.maxTTL {
+		return false
+	}
+	return true
+}
+
+func validateHostnameGlob(val interface{}, key string) (warns []string, errs []error) {
+	if _, err := path.Match(val.(string), ""); err != nil {
+		errs = append(errs, fmt.Errorf("%q is not a valid glob: %s", key, val.(string)))
+	}
+	return
+}
@@ -3,6 +3,9 @@ package namecheap_provider
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -21,10 +24,18 @@ const (
 	domainsPageSize = 100
 )
 
+// domainsSortOrders are the sort_by values, in the vocabulary of the getList
+// SortBy parameter.
+var domainsSortOrders = []string{
+	"NAME", "NAME_DESC", "EXPIREDATE", "EXPIREDATE_DESC", "CREATEDATE", "CREATEDATE_DESC",
+}
+
 // dataSourceNamecheapDomains lists the account's domain portfolio via the
 // namecheap.domains.getList API command, auto-paginating across all pages so
 // the returned domains attribute always reflects the complete result set for
-// the given filters.
+// the given filters. sort_by is passed to getList as SortBy; the remaining
+// filter attributes are applied client-side to each page, so max_results can
+// stop the paging as soon as enough domains pass them.
 func dataSourceNamecheapDomains() *schema.Resource {
 	return &schema.Resource{
 		Description: "Lists the account's domain portfolio with optional filtering, paginating through every result page.",
@@ -42,6 +53,51 @@ func dataSourceNamecheapDomains() *schema.Resource {
 				ValidateFunc: validation.StringInSlice([]string{domainsListTypeAll, domainsListTypeExpiring, domainsListTypeExpired}, false),
 				Description:  "Which subset of the account's domains to return. Possible values: ALL (default), EXPIRING, EXPIRED (maps to the getList ListType parameter).",
 			},
+			"tlds": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validateTld},
+				Description: "Only return domains under one of these TLDs, written without a leading dot (e.g. [\"com\", \"co.uk\"]).",
+			},
+			"expires_within_days": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Only return domains that have not expired and expire within this many days (0 means today).",
+			},
+			"is_locked": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Only return domains whose registrar lock is (true) or is not (false) enabled.",
+			},
+			"auto_renew": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Only return domains with auto-renew enabled (true) or disabled (false).",
+			},
+			"whois_guard": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Only return domains with this WhoisGuard status, case-insensitively (e.g. ENABLED, DISABLED, NOTPRESENT).",
+			},
+			"is_our_dns": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Only return domains that are (true) or are not (false) using Namecheap's DNS.",
+			},
+			"sort_by": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(domainsSortOrders, true),
+				Description:  "Order of the returned domains: NAME, NAME_DESC, EXPIREDATE, EXPIREDATE_DESC, CREATEDATE or CREATEDATE_DESC (maps to the getList SortBy parameter). Defaults to the order Namecheap lists them in.",
+			},
+			"max_results": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Return at most this many domains: the first ones in sort_by order that pass the filters. Paging stops as soon as they are read. Unset returns every match.",
+			},
 			"domains": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -55,23 +111,38 @@ func dataSourceNamecheapDomains() *schema.Resource {
 }
 
 func dataSourceNamecheapDomainsRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 
 	listType := data.Get("list_type").(string)
 	searchTerm := data.Get("search_term").(string)
+	sortBy := strings.ToUpper(data.Get("sort_by").(string))
+	maxResults := data.Get("max_results").(int)
 
-	// fetchAllDomains walks every page of the filtered listing (shared with the
-	// single-domain lifecycle lookup) so the returned set is always complete.
-	allDomains, err := fetchAllDomains(ctx, client, listType, searchTerm)
+	// Namecheap sorts the whole listing, so the first max_results domains to
+	// pass the client-side filters, page by page, are the ones wanted, and
+	// the pages after them need not be requested.
+	filter := expandDomainsFilter(data)
+	now := time.Now().UTC()
+	result := []map[string]interface{}{}
+	err := walkDomains(ctx, client, domainsQuery{listType: listType, searchTerm: searchTerm, sortBy: sortBy}, func(page []namecheap.Domain) bool {
+		for i := range page {
+			domain := flattenPortfolioDomain(&page[i], now)
+			if !filter.matches(domain) {
+				continue
+			}
+			result = append(result, domain)
+			if maxResults > 0 && len(result) == maxResults {
+				return false
+			}
+		}
+		return true
+	})
 	if err != nil {
 		return diagFromClientError(err)
 	}
-
-	now := time.Now().UTC()
-	result := make([]map[string]interface{}, 0, len(allDomains))
-	for i := range allDomains {
-		result = append(result, flattenPortfolioDomain(&allDomains[i], now))
-	}
+	// Namecheap's order is kept apart from ties, which are ordered by name so
+	// the result does not shift between reads.
+	sortPortfolioDomains(result, sortBy)
 
 	if err := data.Set("domains", result); err != nil {
 		return diag.FromErr(err)
@@ -80,3 +151,83 @@ func dataSourceNamecheapDomainsRead(ctx context.Context, data *schema.ResourceDa
 	data.SetId(fmt.Sprintf("domains:%s:%s", listType, searchTerm))
 	return nil
 }
+
+// domainsFilter holds the client-side filters of namecheap_domains. A nil
+// pointer is a filter that is not set.
+type domainsFilter struct {
+	tlds              []string
+	expiresWithinDays *int
+	isLocked          *bool
+	autoRenew         *bool
+	whoisGuard        string
+	isOurDNS          *bool
+}
+
+func expandDomainsFilter(data *schema.ResourceData) domainsFilter {
+	var f domainsFilter
+	for _, tld := range data.Get("tlds").(*schema.Set).List() {
+		f.tlds = append(f.tlds, strings.ToLower(tld.(string)))
+	}
+	// GetOkExists tells an explicit 0 or false from an attribute left unset,
+	// which GetOk cannot.
+	if v, ok := data.GetOkExists("expires_within_days"); ok {
+		days := v.(int)
+		f.expiresWithinDays = &days
+	}
+	for attr, target := range map[string]**bool{
+		"is_locked":  &f.isLocked,
+		"auto_renew": &f.autoRenew,
+		"is_our_dns": &f.isOurDNS,
+	} {
+		if v, ok := data.GetOkExists(attr); ok {
+			b := v.(bool)
+			*target = &b
+		}
+	}
+	f.whoisGuard = data.Get("whois_guard").(string)
+	return f
+}
+
+// matches reports whether domain, as flattened by flattenPortfolioDomain,
+// passes every filter that is set.
+func (f domainsFilter) matches(domain map[string]interface{}) bool {
+	if len(f.tlds) > 0 && !slices.Contains(f.tlds, domainTld(strings.ToLower(domain["name"].(string)))) {
+		return false
+	}
+	if f.expiresWithinDays != nil {
+		days := domain["expires_in_days"].(int)
+		if domain["is_expired"].(bool) || domain["expires"].(string) == "" || days < 0 || days > *f.expiresWithinDays {
+			return false
+		}
+	}
+	for attr, want := range map[string]*bool{"is_locked": f.isLocked, "auto_renew": f.autoRenew, "is_our_dns": f.isOurDNS} {
+		if want != nil && domain[attr].(bool) != *want {
+			return false
+		}
+	}
+	if f.whoisGuard != "" && !strings.EqualFold(domain["whois_guard"].(string), f.whoisGuard) {
+		return false
+	}
+	return true
+}
+
+// sortPortfolioDomains orders flattened domains by one of domainsSortOrders,
+// breaking ties by name. An empty order keeps the listing's order. expires and
+// created are RFC3339 UTC timestamps, so they order as strings.
+func sortPortfolioDomains(domains []map[string]interface{}, order string) {
+	if order == "" {
+		return
+	}
+	key := map[string]string{"NAME": "name", "EXPIREDATE": "expires", "CREATEDATE": "created"}[strings.TrimSuffix(order, "_DESC")]
+	desc := strings.HasSuffix(order, "_DESC")
+	sort.SliceStable(domains, func(i, j int) bool {
+		a, b := domains[i][key].(string), domains[j][key].(string)
+		if a == b {
+			return domains[i]["name"].(string) < domains[j]["name"].(string)
+		}
+		if desc {
+			return a > b
+		}
+		return a < b
+	})
+}
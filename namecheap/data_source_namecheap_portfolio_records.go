@@ -0,0 +1,200 @@
+package namecheap_provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+)
+
+// dataSourceNamecheapPortfolioRecords reads the DNS host records of every
+// domain in the portfolio listing (the same search_term/list_type filters as
+// namecheap_domains) into one flat list. Domains are read one after another
+// through the provider's client, so the reads stay within its rate limit. A
+// domain that cannot be read is reported in errors rather than failing the
+// whole read, so one expired or locked domain does not hide the other 299.
+//
+// Each domain costs two calls, so at the default requests_per_minute of 20 a
+// 300-domain portfolio takes about 30 minutes. The read has its own timeouts
+// block for that reason, and the domains it has not reached when the deadline
+// passes are reported in errors as well.
+func dataSourceNamecheapPortfolioRecords() *schema.Resource {
+	recordSchema := domainRecordElemSchema()
+	recordSchema["domain"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The domain the record belongs to.",
+	}
+
+	return &schema.Resource{
+		Description: "Reads the DNS host records of every domain in the account's portfolio into one flat list, reporting domains that cannot be read instead of failing.",
+		ReadContext: dataSourceNamecheapPortfolioRecordsRead,
+
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(portfolioRecordsReadTimeout),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"search_term": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Optional keyword to filter the domains whose records are read (maps to the getList SearchTerm parameter).",
+			},
+			"list_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      domainsListTypeAll,
+				ValidateFunc: validation.StringInSlice([]string{domainsListTypeAll, domainsListTypeExpiring, domainsListTypeExpired}, false),
+				Description:  "Which subset of the account's domains to read. Possible values: ALL (default), EXPIRING, EXPIRED (maps to the getList ListType parameter).",
+			},
+			"records": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The live DNS host records of every domain read, in portfolio order. Field shapes mirror the namecheap_domain_records data source, plus the domain.",
+				Elem: &schema.Resource{
+					Schema: recordSchema,
+				},
+			},
+			"custom_nameserver_domains": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The domains delegated to custom nameservers. Namecheap does not serve their records, so none are read for them.",
+			},
+			"errors": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The domains whose records could not be read, with the reason, including those not reached before the read's deadline. Their records are missing from records.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"domain": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The domain that could not be read.",
+						},
+						"error": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Why the domain's records could not be read.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// portfolioRecordsReadTimeout is the default deadline of a portfolio records
+// read: about 600 domains at the default requests_per_minute.
+const portfolioRecordsReadTimeout = 60 * time.Minute
+
+func dataSourceNamecheapPortfolioRecordsRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+
+	listType := data.Get("list_type").(string)
+	searchTerm := data.Get("search_term").(string)
+
+	// Without the listing there is nothing to read, so unlike a single domain
+	// its failure fails the read.
+	allDomains, err := fetchAllDomains(ctx, client, listType, searchTerm)
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	records := []map[string]interface{}{}
+	customNameserverDomains := []string{}
+	readErrors := []map[string]interface{}{}
+	unread := 0
+	for i := range allDomains {
+		domain := strings.ToLower(derefString(allDomains[i].Name))
+		if domain == "" {
+			continue
+		}
+		// Once the deadline passes, the rest are reported rather than read, so
+		// the records already read are kept.
+		if err := ctx.Err(); err != nil {
+			unread++
+			readErrors = append(readErrors, map[string]interface{}{
+				"domain": domain,
+				"error":  "not read before the deadline: " + err.Error(),
+			})
+			continue
+		}
+
+		hosts, ourDNS, err := readPortfolioDomainHosts(ctx, client, domain)
+		if err != nil {
+			log.Printf("[WARN] namecheap: reading the records of %s: %s", domain, err)
+			readErrors = append(readErrors, map[string]interface{}{
+				"domain": domain,
+				"error":  err.Error(),
+			})
+			continue
+		}
+		if !ourDNS {
+			customNameserverDomains = append(customNameserverDomains, domain)
+			continue
+		}
+		for j := range hosts {
+			record := flattenHostRecord(&hosts[j])
+			record["domain"] = domain
+			records = append(records, record)
+		}
+	}
+
+	if err := data.Set("records", records); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := data.Set("custom_nameserver_domains", customNameserverDomains); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := data.Set("errors", readErrors); err != nil {
+		return diag.FromErr(err)
+	}
+
+	data.SetId(fmt.Sprintf("portfolio_records:%s:%s", listType, searchTerm))
+
+	if unread > 0 {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("%d of %d domains were not read before the deadline", unread, len(allDomains)),
+			Detail: "The read's deadline passed part-way through the portfolio. The domains not reached are listed in errors. " +
+				"Raise the data source's read timeout, or the provider's requests_per_minute, for a large portfolio.",
+		}}
+	}
+	return nil
+}
+
+// readPortfolioDomainHosts reads domain's host records the way the
+// namecheap_domain_records data source does: nameservers first, and the record
+// set, without Namecheap's default parking records, only when Namecheap's DNS
+// serves the domain. ourDNS is false for a domain on custom nameservers.
+func readPortfolioDomainHosts(ctx context.Context, client *namecheap.Client, domain string) (hosts []namecheap.DomainsDNSHostRecordDetailed, ourDNS bool, err error) {
+	nsResp, err := client.DomainsDNS.GetListWithContext(ctx, domain)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := validateGetListResponse(nsResp); err != nil {
+		return nil, false, err
+	}
+	if !*nsResp.DomainDNSGetListResult.IsUsingOurDNS {
+		return nil, false, nil
+	}
+
+	hostsResp, err := client.DomainsDNS.GetHostsWithContext(ctx, domain)
+	if err != nil {
+		return nil, true, err
+	}
+	if err := validateGetHostsResponse(hostsResp); err != nil {
+		return nil, true, err
+	}
+	if hostsResp.DomainDNSGetHostsResult.Hosts == nil {
+		return nil, true, nil
+	}
+	return *filterDefaultParkingRecords(hostsResp.DomainDNSGetHostsResult.Hosts, &domain), true, nil
+}
@@ -0,0 +1,343 @@
+package namecheap_provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+)
+
+const (
+	renewalForecastDefaultHorizon = 365
+	renewalForecastMaxHorizon     = 3650
+
+	// renewalForecastDecimals is how many decimal places the forecast's totals
+	// are rendered with. Prices are summed exactly; only the rendering rounds.
+	renewalForecastDecimals = 2
+)
+
+// dataSourceNamecheapRenewalForecast joins the portfolio listing, the one-year
+// RENEW price of each TLD in it and the account balance into a forecast of what
+// renewing the domains that expire within a horizon will cost, month by month.
+//
+// As in namecheap_tld_pricing and namecheap_account_balance, every monetary
+// attribute is an exact decimal string, never a number. The totals are summed
+// as exact rationals, not floats.
+func dataSourceNamecheapRenewalForecast() *schema.Resource {
+	return &schema.Resource{
+		Description: "Forecasts what renewing the domains that expire within a horizon will cost, month by month, and compares it with the account balance.",
+		ReadContext: dataSourceNamecheapRenewalForecastRead,
+		Schema: map[string]*schema.Schema{
+			"horizon_days": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      renewalForecastDefaultHorizon,
+				Description:  fmt.Sprintf("How many days ahead to forecast (1-%d). Defaults to %d.", renewalForecastMaxHorizon, renewalForecastDefaultHorizon),
+				ValidateFunc: validation.IntBetween(1, renewalForecastMaxHorizon),
+			},
+			"currency": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The account currency every amount is in (e.g. USD).",
+			},
+			"domains": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The domains that expire within the horizon, soonest first. Domains that have already expired are not included.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The domain name (e.g. example.com).",
+						},
+						"tld": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The domain's TLD, without a leading dot (e.g. com, co.uk).",
+						},
+						"expires": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Expiration date as an RFC3339 timestamp (UTC).",
+						},
+						"expires_in_days": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Whole calendar days until the domain expires.",
+						},
+						"month": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The month the domain expires in, as YYYY-MM (UTC).",
+						},
+						"auto_renew": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether auto-renew is enabled for the domain.",
+						},
+						"is_premium": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the domain is a premium domain. A premium domain renews at its own price, not the TLD's, so it is left unpriced.",
+						},
+						"renewal_price": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The one-year renewal price of the domain's TLD, as an exact decimal string. Empty when the domain is unpriced.",
+						},
+					},
+				},
+			},
+			"monthly_totals": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The renewal cost of the domains expiring in each month of the horizon that has any, in month order.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"month": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The month, as YYYY-MM (UTC).",
+						},
+						"domain_count": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "How many domains expire in the month.",
+						},
+						"total": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The renewal cost of the priced domains expiring in the month, as a decimal string.",
+						},
+						"auto_renew_total": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The part of total for domains with auto-renew enabled, as a decimal string.",
+						},
+					},
+				},
+			},
+			"total": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The renewal cost of every priced domain in the horizon, as a decimal string.",
+			},
+			"auto_renew_total": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The renewal cost of the priced domains in the horizon with auto-renew enabled, as a decimal string. This is what the account will be charged without further action.",
+			},
+			"unpriced_domains": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The domains in the horizon left out of every total: premium domains, and domains whose TLD has no published one-year renewal price in the account currency.",
+			},
+			"available_balance": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The account's available balance, as reported by namecheap_account_balance.",
+			},
+			"funds_required_for_auto_renew": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Namecheap's own figure for the funds needed to auto-renew the account's domains, as reported by namecheap_account_balance. Namecheap decides which renewals it counts, so it can differ from auto_renew_total.",
+			},
+			"auto_renew_shortfall": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "How far available_balance falls short of auto_renew_total, as a decimal string; \"0.00\" when the balance covers it.",
+			},
+			"balance_covers_auto_renew": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether available_balance covers auto_renew_total.",
+			},
+		},
+	}
+}
+
+func dataSourceNamecheapRenewalForecastRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	horizon := data.Get("horizon_days").(int)
+
+	balanceResp, err := client.Users.GetBalancesWithContext(ctx)
+	if err != nil {
+		return diagFromClientError(err)
+	}
+	if balanceResp == nil || balanceResp.UserGetBalancesResult == nil {
+		return diag.Errorf("Namecheap returned no balance information for this account")
+	}
+	balances := balanceResp.UserGetBalancesResult
+	currency := balances.Currency
+
+	allDomains, err := fetchAllDomains(ctx, client, domainsListTypeAll, "")
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	now := time.Now().UTC()
+	var expiring []map[string]interface{}
+	for i := range allDomains {
+		d := flattenPortfolioDomain(&allDomains[i], now)
+		days := d["expires_in_days"].(int)
+		if d["is_expired"].(bool) || d["expires"].(string) == "" || days < 0 || days > horizon {
+			continue
+		}
+		name := strings.ToLower(d["name"].(string))
+		expiring = append(expiring, map[string]interface{}{
+			"name":            name,
+			"tld":             domainTld(name),
+			"expires":         d["expires"],
+			"expires_in_days": days,
+			"month":           allDomains[i].Expires.Time.UTC().Format("2006-01"),
+			"auto_renew":      d["auto_renew"],
+			"is_premium":      d["is_premium"],
+			"renewal_price":   "",
+		})
+	}
+	sort.SliceStable(expiring, func(i, j int) bool {
+		return expiring[i]["expires_in_days"].(int) < expiring[j]["expires_in_days"].(int)
+	})
+
+	// One pricing request per TLD in the horizon, not per domain.
+	prices := map[string]*big.Rat{}
+	var diags diag.Diagnostics
+	for _, d := range expiring {
+		tld := d["tld"].(string)
+		if _, seen := prices[tld]; seen || d["is_premium"].(bool) {
+			continue
+		}
+		price, priceDiags := renewalPrice(ctx, client, tld, currency)
+		if priceDiags.HasError() {
+			return priceDiags
+		}
+		diags = append(diags, priceDiags...)
+		prices[tld] = price
+	}
+
+	total, autoRenewTotal := new(big.Rat), new(big.Rat)
+	type monthTotal struct {
+		count            int
+		total, autoRenew *big.Rat
+	}
+	months := map[string]*monthTotal{}
+	unpriced := []string{}
+	for _, d := range expiring {
+		month := d["month"].(string)
+		mt := months[month]
+		if mt == nil {
+			mt = &monthTotal{total: new(big.Rat), autoRenew: new(big.Rat)}
+			months[month] = mt
+		}
+		mt.count++
+
+		price := prices[d["tld"].(string)]
+		if d["is_premium"].(bool) || price == nil {
+			unpriced = append(unpriced, d["name"].(string))
+			continue
+		}
+		d["renewal_price"] = formatForecastAmount(price)
+		total.Add(total, price)
+		mt.total.Add(mt.total, price)
+		if d["auto_renew"].(bool) {
+			autoRenewTotal.Add(autoRenewTotal, price)
+			mt.autoRenew.Add(mt.autoRenew, price)
+		}
+	}
+
+	monthKeys := make([]string, 0, len(months))
+	for month := range months {
+		monthKeys = append(monthKeys, month)
+	}
+	sort.Strings(monthKeys)
+	monthlyTotals := make([]map[string]interface{}, 0, len(monthKeys))
+	for _, month := range monthKeys {
+		mt := months[month]
+		monthlyTotals = append(monthlyTotals, map[string]interface{}{
+			"month":            month,
+			"domain_count":     mt.count,
+			"total":            formatForecastAmount(mt.total),
+			"auto_renew_total": formatForecastAmount(mt.autoRenew),
+		})
+	}
+
+	available, err := parseAmount(balances.AvailableBalance)
+	if err != nil {
+		return diag.Errorf("Namecheap returned an available balance that %s", err)
+	}
+	shortfall := new(big.Rat).Sub(autoRenewTotal, available)
+	if shortfall.Sign() < 0 {
+		shortfall.SetInt64(0)
+	}
+
+	if expiring == nil {
+		expiring = []map[string]interface{}{}
+	}
+	_ = data.Set("currency", currency)
+	_ = data.Set("domains", expiring)
+	_ = data.Set("monthly_totals", monthlyTotals)
+	_ = data.Set("total", formatForecastAmount(total))
+	_ = data.Set("auto_renew_total", formatForecastAmount(autoRenewTotal))
+	_ = data.Set("unpriced_domains", unpriced)
+	_ = data.Set("available_balance", balances.AvailableBalance.String())
+	_ = data.Set("funds_required_for_auto_renew", balances.FundsRequiredForAutoRenew.String())
+	_ = data.Set("auto_renew_shortfall", formatForecastAmount(shortfall))
+	_ = data.Set("balance_covers_auto_renew", shortfall.Sign() == 0)
+
+	data.SetId(fmt.Sprintf("renewal_forecast:%d", horizon))
+	return diags
+}
+
+// renewalPrice returns the one-year RENEW price of tld, or nil with a warning
+// when Namecheap publishes none, or publishes it in a currency other than the
+// account's: summing across currencies would be meaningless.
+func renewalPrice(ctx context.Context, client *namecheap.Client, tld, currency string) (*big.Rat, diag.Diagnostics) {
+	price, ok, diags := publishedPrice(ctx, client, pricingActionRenew, tld, 1)
+	if diags.HasError() {
+		return nil, diags
+	}
+	if !ok {
+		log.Printf("[WARN] namecheap: no one-year RENEW price published for .%s", tld)
+		return nil, diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("No renewal price published for .%s", tld),
+			Detail:   fmt.Sprintf("Namecheap publishes no one-year RENEW price for .%s, so its domains are listed in unpriced_domains and left out of the totals.", tld),
+		}}
+	}
+	if price.Currency != "" && currency != "" && !strings.EqualFold(price.Currency, currency) {
+		return nil, diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Renewal price for .%s is not in the account currency", tld),
+			Detail: fmt.Sprintf("Namecheap prices the renewal of .%s in %s but the account is in %s, so its domains are listed in unpriced_domains and left out of the totals.",
+				tld, price.Currency, currency),
+		}}
+	}
+
+	amount, err := parseAmount(price.EffectivePrice())
+	if err != nil {
+		return nil, diag.Errorf("Namecheap returned a renewal price for .%s that %s", tld, err)
+	}
+	return amount, nil
+}
+
+// domainTld returns the part of a registered domain name after its first label.
+func domainTld(domain string) string {
+	if i := strings.Index(domain, "."); i >= 0 {
+		return domain[i+1:]
+	}
+	return domain
+}
+
+func formatForecastAmount(amount *big.Rat) string {
+	return amount.FloatString(renewalForecastDecimals)
+}
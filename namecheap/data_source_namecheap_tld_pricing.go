@@ -3,6 +3,7 @@ package namecheap_provider
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -100,7 +101,7 @@ func dataSourceNamecheapTldPricing() *schema.Resource {
 }
 
 func dataSourceNamecheapTldPricingRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 
 	// validateTld has already rejected a leading dot and surrounding whitespace,
 	// so case is the only normalization left to do.
@@ -197,3 +198,32 @@ func dataSourcePricingReadError(tld, action string, err error) diag.Diagnostics
 	}
 	return diags
 }
+
+// publishedPrice looks up the published price of tld for action over years,
+// for the reads and checks that need a price without exporting it. ok is false
+// when Namecheap publishes no such tier.
+func publishedPrice(ctx context.Context, client *namecheap.Client, action, tld string, years int) (price namecheap.Price, ok bool, diags diag.Diagnostics) {
+	resp, err := client.Users.GetPricingWithContext(ctx, &namecheap.UsersGetPricingArgs{
+		ProductType: namecheap.String(pricingProductType),
+		ActionName:  namecheap.String(action),
+		ProductName: namecheap.String(tld),
+	})
+	if err != nil {
+		return namecheap.Price{}, false, dataSourcePricingReadError(tld, action, err)
+	}
+	if resp == nil || resp.UserGetPricingResult == nil {
+		return namecheap.Price{}, false, nil
+	}
+	price, ok = resp.UserGetPricingResult.PriceFor(action, tld, years)
+	return price, ok, nil
+}
+
+// parseAmount reads an exact decimal amount as a rational, so sums and
+// comparisons of money never pass through a float.
+func parseAmount(amount namecheap.Amount) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(amount.String())
+	if !ok {
+		return nil, fmt.Errorf("is not a decimal number: %q", amount.String())
+	}
+	return r, nil
+}
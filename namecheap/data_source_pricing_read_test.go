@@ -7,6 +7,7 @@ import (
 	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/stretchr/testify/assert"
@@ -92,7 +93,7 @@ func TestDataSourceAccountBalanceRead_Success(t *testing.T) {
 	})
 
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapAccountBalance().Schema, map[string]interface{}{})
-	diags := dataSourceNamecheapAccountBalanceRead(context.Background(), d, client)
+	diags := dataSourceNamecheapAccountBalanceRead(context.Background(), d, testMeta(client))
 	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
 
 	assert.Equal(t, "USD", d.Get("currency"))
@@ -117,7 +118,7 @@ func TestDataSourceAccountBalanceRead_PrecisionPreserved(t *testing.T) {
 	})
 
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapAccountBalance().Schema, map[string]interface{}{})
-	diags := dataSourceNamecheapAccountBalanceRead(context.Background(), d, client)
+	diags := dataSourceNamecheapAccountBalanceRead(context.Background(), d, testMeta(client))
 	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
 
 	assert.Equal(t, "EUR", d.Get("currency"))
@@ -134,7 +135,7 @@ func TestDataSourceAccountBalanceRead_APIError(t *testing.T) {
 	})
 
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapAccountBalance().Schema, map[string]interface{}{})
-	diags := dataSourceNamecheapAccountBalanceRead(context.Background(), d, client)
+	diags := dataSourceNamecheapAccountBalanceRead(context.Background(), d, testMeta(client))
 	require.True(t, diags.HasError(), "an API error should surface")
 	assert.Empty(t, d.Id(), "a failed read must not set an ID")
 }
@@ -153,7 +154,7 @@ func TestDataSourceAccountBalanceRead_MalformedEnvelope(t *testing.T) {
 	})
 
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapAccountBalance().Schema, map[string]interface{}{})
-	diags := dataSourceNamecheapAccountBalanceRead(context.Background(), d, client)
+	diags := dataSourceNamecheapAccountBalanceRead(context.Background(), d, testMeta(client))
 	require.True(t, diags.HasError(), "a result-less response must not read as a zero balance")
 	assert.Equal(t, "", d.Get("available_balance"))
 	assert.Empty(t, d.Id(), "a failed read must not set an ID")
@@ -188,7 +189,7 @@ func TestDataSourceTldPricingRead_Success(t *testing.T) {
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapTldPricing().Schema, map[string]interface{}{
 		"tld": "com", "action": "REGISTER", "years": 1,
 	})
-	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, client)
+	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, testMeta(client))
 	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
 
 	assert.Equal(t, "8.88", d.Get("price"))
@@ -215,7 +216,7 @@ func TestDataSourceTldPricingRead_MultiYearTier(t *testing.T) {
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapTldPricing().Schema, map[string]interface{}{
 		"tld": "com", "action": "REGISTER", "years": 2,
 	})
-	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, client)
+	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, testMeta(client))
 	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
 	assert.Equal(t, "17.76", d.Get("price"))
 	assert.Equal(t, "pricing:com:REGISTER:2", d.Id())
@@ -237,7 +238,7 @@ func TestDataSourceTldPricingRead_Promotion(t *testing.T) {
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapTldPricing().Schema, map[string]interface{}{
 		"tld": "shop", "action": "REGISTER", "years": 1,
 	})
-	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, client)
+	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, testMeta(client))
 	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
 
 	assert.Equal(t, "1.16", d.Get("price"))
@@ -262,7 +263,7 @@ func TestDataSourceTldPricingRead_OptionalAttributesAbsent(t *testing.T) {
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapTldPricing().Schema, map[string]interface{}{
 		"tld": "org", "action": "RENEW", "years": 1,
 	})
-	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, client)
+	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, testMeta(client))
 	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
 
 	// Price and YourPrice are zero, so the effective price falls through to the
@@ -291,7 +292,7 @@ func TestDataSourceTldPricingRead_ZeroPromotionIsNoPromotion(t *testing.T) {
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapTldPricing().Schema, map[string]interface{}{
 		"tld": "net", "action": "REGISTER", "years": 1,
 	})
-	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, client)
+	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, testMeta(client))
 	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
 	assert.Equal(t, "", d.Get("promo_price"), "a non-positive promotion is not a promotion")
 	assert.Equal(t, "12.00", d.Get("price"), "the charged price is unaffected by the promotion attribute")
@@ -335,7 +336,7 @@ func TestDataSourceTldPricingRead_PrecedenceRungs(t *testing.T) {
 			d := schema.TestResourceDataRaw(t, dataSourceNamecheapTldPricing().Schema, map[string]interface{}{
 				"tld": "com", "action": "REGISTER", "years": 1,
 			})
-			diags := dataSourceNamecheapTldPricingRead(context.Background(), d, client)
+			diags := dataSourceNamecheapTldPricingRead(context.Background(), d, testMeta(client))
 			require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
 			assert.Equal(t, tc.wantPrice, d.Get("price"))
 		})
@@ -358,7 +359,7 @@ func TestDataSourceTldPricingRead_DurationTypeVerbatim(t *testing.T) {
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapTldPricing().Schema, map[string]interface{}{
 		"tld": "com", "action": "REGISTER", "years": 1,
 	})
-	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, client)
+	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, testMeta(client))
 	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
 	assert.Equal(t, "Year", d.Get("duration_type"), "duration_type must be the server's value, not a constant")
 }
@@ -381,7 +382,7 @@ func TestDataSourceTldPricingRead_MultiLabelTld(t *testing.T) {
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapTldPricing().Schema, map[string]interface{}{
 		"tld": "co.uk", "action": "REGISTER", "years": 1,
 	})
-	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, client)
+	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, testMeta(client))
 	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
 	assert.Equal(t, "co.uk", sentProduct)
 	assert.Equal(t, "7.48", d.Get("price"))
@@ -406,7 +407,7 @@ func TestDataSourceTldPricingRead_BlankPromotionIsAbsent(t *testing.T) {
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapTldPricing().Schema, map[string]interface{}{
 		"tld": "info", "action": "REGISTER", "years": 1,
 	})
-	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, client)
+	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, testMeta(client))
 	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
 	assert.Equal(t, "", d.Get("promo_price"), "a whitespace-only promotion is not a promotion")
 	assert.Equal(t, "3.98", d.Get("price"))
@@ -428,7 +429,7 @@ func TestDataSourceTldPricingRead_MalformedEnvelope(t *testing.T) {
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapTldPricing().Schema, map[string]interface{}{
 		"tld": "com", "action": "REGISTER", "years": 1,
 	})
-	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, client)
+	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, testMeta(client))
 	require.True(t, diags.HasError(), "a result-less response must not read as a valid price")
 	assert.Contains(t, diags[0].Summary, "com")
 	assert.Empty(t, d.Id(), "a failed read must not set an ID")
@@ -456,7 +457,7 @@ func TestDataSourceTldPricingRead_CaseNormalization(t *testing.T) {
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapTldPricing().Schema, map[string]interface{}{
 		"tld": "COM", "action": "transfer", "years": 1,
 	})
-	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, client)
+	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, testMeta(client))
 	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
 
 	assert.Equal(t, "com", sentProduct, "TLD should be lower-cased before the request")
@@ -481,7 +482,7 @@ func TestDataSourceTldPricingRead_TierNotFound(t *testing.T) {
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapTldPricing().Schema, map[string]interface{}{
 		"tld": "com", "action": "REGISTER", "years": 9,
 	})
-	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, client)
+	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, testMeta(client))
 	require.True(t, diags.HasError(), "a missing tier should be an error, not an empty price")
 	assert.Contains(t, diags[0].Summary, "com")
 	assert.Contains(t, diags[0].Summary, "9 year")
@@ -496,7 +497,7 @@ func TestDataSourceTldPricingRead_APIError(t *testing.T) {
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapTldPricing().Schema, map[string]interface{}{
 		"tld": "xyz", "action": "REGISTER", "years": 1,
 	})
-	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, client)
+	diags := dataSourceNamecheapTldPricingRead(context.Background(), d, testMeta(client))
 	require.True(t, diags.HasError(), "an API error should surface")
 	assert.Contains(t, diags[0].Summary, "xyz", "diagnostic should name the TLD")
 	assert.Contains(t, diags[0].Summary, "REGISTER", "diagnostic should name the action")
@@ -545,11 +546,12 @@ func TestValidateTld(t *testing.T) {
 // A future edit that makes an attribute writable would change plan behaviour for
 // existing configurations, and this test fails first.
 func TestDataSourcePricingSchemasAreReadOnly(t *testing.T) {
-	inputs := map[string]bool{"tld": true, "action": true, "years": true}
+	inputs := map[string]bool{"tld": true, "action": true, "years": true, "horizon_days": true}
 
 	for name, ds := range map[string]*schema.Resource{
-		"namecheap_account_balance": dataSourceNamecheapAccountBalance(),
-		"namecheap_tld_pricing":     dataSourceNamecheapTldPricing(),
+		"namecheap_account_balance":  dataSourceNamecheapAccountBalance(),
+		"namecheap_tld_pricing":      dataSourceNamecheapTldPricing(),
+		"namecheap_renewal_forecast": dataSourceNamecheapRenewalForecast(),
 	} {
 		for attr, s := range ds.Schema {
 			if inputs[attr] {
@@ -564,3 +566,127 @@ func TestDataSourcePricingSchemasAreReadOnly(t *testing.T) {
 		assert.Nil(t, ds.DeleteContext, "%s must not define a delete", name)
 	}
 }
+
+// --- namecheap_renewal_forecast ----------------------------------------------
+
+// forecastExpiry renders a date days from today in getList's MM/DD/YYYY form.
+func forecastExpiry(days int) string {
+	return time.Now().UTC().AddDate(0, 0, days).Format("01/02/2006")
+}
+
+// TestDataSourceRenewalForecastRead prices the domains inside the horizon once
+// per TLD, leaves premium and unpublished TLDs out of the totals, and compares
+// the auto-renew total with the balance.
+func TestDataSourceRenewalForecastRead(t *testing.T) {
+	rows := []dsDomainRow{
+		{ID: "1", Name: "later-example.com", Expires: forecastExpiry(40), AutoRenew: true},
+		{ID: "2", Name: "soon-example.com", Expires: forecastExpiry(10), AutoRenew: true},
+		{ID: "3", Name: "manual-example.net", Expires: forecastExpiry(20)},
+		{ID: "4", Name: "premium-example.com", Expires: forecastExpiry(30), AutoRenew: true, IsPremium: true},
+		{ID: "5", Name: "rare-example.xyz", Expires: forecastExpiry(50), AutoRenew: true},
+		{ID: "6", Name: "far-example.com", Expires: forecastExpiry(400), AutoRenew: true},
+		{ID: "7", Name: "gone-example.com", Expires: forecastExpiry(-5), IsExpired: true},
+	}
+	var pricingCalls atomic.Int32
+	client := startDataSourceServer(t, func(command string, r *http.Request) string {
+		switch command {
+		case "namecheap.users.getBalances":
+			return xmlGetBalances("USD", "20.00", "20.00", "0.00", "0.00", "25.96")
+		case "namecheap.domains.getList":
+			return xmlGetListPage(rows, len(rows), 1, domainsPageSize)
+		case "namecheap.users.getPricing":
+			pricingCalls.Add(1)
+			require.Equal(t, "RENEW", r.FormValue("ActionName"))
+			switch r.FormValue("ProductName") {
+			case "com":
+				return xmlGetPricing("renew", "com", dsPriceTier{Duration: 1, DurationType: "YEAR", Price: "14.58", RegularPrice: "14.58", YourPrice: "14.58", Currency: "USD"})
+			case "net":
+				return xmlGetPricing("renew", "net", dsPriceTier{Duration: 1, DurationType: "YEAR", Price: "16.10", RegularPrice: "16.10", YourPrice: "16.10"})
+			}
+			return xmlGetPricing("renew", r.FormValue("ProductName"))
+		}
+		return apiErrorXML("1010101", "unexpected command "+command)
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceNamecheapRenewalForecast().Schema, map[string]interface{}{"horizon_days": 365})
+	diags := dataSourceNamecheapRenewalForecastRead(context.Background(), d, testMeta(client))
+	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
+	require.Len(t, diags, 1, "the unpublished .xyz price is a warning")
+	assert.Contains(t, diags[0].Summary, ".xyz")
+	assert.EqualValues(t, 3, pricingCalls.Load(), "one pricing request per TLD, none for the premium domain's")
+
+	domains := d.Get("domains").([]interface{})
+	var names []string
+	for _, dom := range domains {
+		names = append(names, dom.(map[string]interface{})["name"].(string))
+	}
+	assert.Equal(t, []string{"soon-example.com", "manual-example.net", "premium-example.com", "later-example.com", "rare-example.xyz"}, names)
+	first := domains[0].(map[string]interface{})
+	assert.Equal(t, "com", first["tld"])
+	assert.Equal(t, "14.58", first["renewal_price"])
+	assert.Equal(t, "", domains[2].(map[string]interface{})["renewal_price"])
+
+	assert.Equal(t, "USD", d.Get("currency"))
+	assert.Equal(t, "45.26", d.Get("total"))
+	assert.Equal(t, "29.16", d.Get("auto_renew_total"))
+	assert.Equal(t, []interface{}{"premium-example.com", "rare-example.xyz"}, d.Get("unpriced_domains"))
+	assert.Equal(t, "20.00", d.Get("available_balance"))
+	assert.Equal(t, "25.96", d.Get("funds_required_for_auto_renew"))
+	assert.Equal(t, "9.16", d.Get("auto_renew_shortfall"))
+	assert.Equal(t, false, d.Get("balance_covers_auto_renew"))
+
+	var count int
+	for _, m := range d.Get("monthly_totals").([]interface{}) {
+		count += m.(map[string]interface{})["domain_count"].(int)
+	}
+	assert.Equal(t, 5, count, "every domain in the horizon is counted in its month")
+	assert.Equal(t, "renewal_forecast:365", d.Id())
+}
+
+func TestDataSourceRenewalForecastRead_Covered(t *testing.T) {
+	rows := []dsDomainRow{{ID: "1", Name: "soon-example.com", Expires: forecastExpiry(10), AutoRenew: true}}
+	client := startDataSourceServer(t, func(command string, _ *http.Request) string {
+		switch command {
+		case "namecheap.users.getBalances":
+			return xmlGetBalances("USD", "100.00", "100.00", "0.00", "0.00", "14.58")
+		case "namecheap.domains.getList":
+			return xmlGetListPage(rows, len(rows), 1, domainsPageSize)
+		case "namecheap.users.getPricing":
+			return xmlGetPricing("renew", "com", dsPriceTier{Duration: 1, DurationType: "YEAR", Price: "14.58", RegularPrice: "14.58", YourPrice: "14.58"})
+		}
+		return apiErrorXML("1010101", "unexpected command "+command)
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceNamecheapRenewalForecast().Schema, map[string]interface{}{"horizon_days": 30})
+	diags := dataSourceNamecheapRenewalForecastRead(context.Background(), d, testMeta(client))
+	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
+	assert.Empty(t, diags)
+	assert.Equal(t, "0.00", d.Get("auto_renew_shortfall"))
+	assert.Equal(t, true, d.Get("balance_covers_auto_renew"))
+	monthly := d.Get("monthly_totals").([]interface{})
+	require.Len(t, monthly, 1)
+	assert.Equal(t, "14.58", monthly[0].(map[string]interface{})["auto_renew_total"])
+}
+
+func TestDataSourceRenewalForecastRead_PricingError(t *testing.T) {
+	rows := []dsDomainRow{{ID: "1", Name: "soon-example.com", Expires: forecastExpiry(10), AutoRenew: true}}
+	client := startDataSourceServer(t, func(command string, _ *http.Request) string {
+		switch command {
+		case "namecheap.users.getBalances":
+			return xmlGetBalances("USD", "100.00", "100.00", "0.00", "0.00", "14.58")
+		case "namecheap.domains.getList":
+			return xmlGetListPage(rows, len(rows), 1, domainsPageSize)
+		}
+		return apiErrorXML("4022336", "pricing failed")
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceNamecheapRenewalForecast().Schema, map[string]interface{}{"horizon_days": 30})
+	diags := dataSourceNamecheapRenewalForecastRead(context.Background(), d, testMeta(client))
+	require.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, ".com, RENEW")
+}
+
+func TestDomainTld(t *testing.T) {
+	assert.Equal(t, "com", domainTld("example.com"))
+	assert.Equal(t, "co.uk", domainTld("example.co.uk"))
+}
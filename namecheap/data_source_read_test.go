@@ -10,7 +10,9 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
 	"github.com/stretchr/testify/assert"
@@ -262,7 +264,7 @@ func TestDataSourceDomainRead_Success(t *testing.T) {
 	})
 
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapDomain().Schema, map[string]interface{}{"domain": domain})
-	diags := dataSourceNamecheapDomainRead(context.Background(), d, client)
+	diags := dataSourceNamecheapDomainRead(context.Background(), d, testMeta(client))
 	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
 
 	// getInfo-sourced fields.
@@ -307,7 +309,7 @@ func TestDataSourceDomainRead_NotFound(t *testing.T) {
 	})
 
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapDomain().Schema, map[string]interface{}{"domain": domain})
-	diags := dataSourceNamecheapDomainRead(context.Background(), d, client)
+	diags := dataSourceNamecheapDomainRead(context.Background(), d, testMeta(client))
 	require.True(t, diags.HasError(), "expected an error for an unknown domain")
 	assert.Contains(t, diags[0].Summary, domain, "diagnostic should name the domain")
 }
@@ -337,7 +339,7 @@ func TestDataSourceDomainRead_LifecycleMissing(t *testing.T) {
 	})
 
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapDomain().Schema, map[string]interface{}{"domain": domain})
-	diags := dataSourceNamecheapDomainRead(context.Background(), d, client)
+	diags := dataSourceNamecheapDomainRead(context.Background(), d, testMeta(client))
 	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
 	assert.Equal(t, "NAMECHEAP", d.Get("dns_provider_type"))
 	// getInfo-sourced fields survive a portfolio miss.
@@ -400,7 +402,7 @@ func TestDataSourceDomainRead_ExpiredFallback(t *testing.T) {
 			})
 
 			d := schema.TestResourceDataRaw(t, dataSourceNamecheapDomain().Schema, map[string]interface{}{"domain": domain})
-			diags := dataSourceNamecheapDomainRead(context.Background(), d, client)
+			diags := dataSourceNamecheapDomainRead(context.Background(), d, testMeta(client))
 			require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
 			assert.Equal(t, tc.expired, d.Get("is_expired"))
 			if tc.days < 0 {
@@ -425,7 +427,7 @@ func TestDataSourceDomainRead_GetListError(t *testing.T) {
 	})
 
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapDomain().Schema, map[string]interface{}{"domain": domain})
-	diags := dataSourceNamecheapDomainRead(context.Background(), d, client)
+	diags := dataSourceNamecheapDomainRead(context.Background(), d, testMeta(client))
 	require.True(t, diags.HasError(), "a getList transport/API error should surface")
 	assert.Contains(t, diags[0].Summary, domain)
 }
@@ -464,7 +466,7 @@ func TestDataSourceDomainsRead_MultiPage(t *testing.T) {
 	})
 
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapDomains().Schema, map[string]interface{}{"list_type": "ALL"})
-	diags := dataSourceNamecheapDomainsRead(context.Background(), d, client)
+	diags := dataSourceNamecheapDomainsRead(context.Background(), d, testMeta(client))
 	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
 
 	domains := d.Get("domains").([]interface{})
@@ -490,7 +492,7 @@ func TestDataSourceDomainsRead_Empty(t *testing.T) {
 	})
 
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapDomains().Schema, map[string]interface{}{"list_type": "ALL"})
-	diags := dataSourceNamecheapDomainsRead(context.Background(), d, client)
+	diags := dataSourceNamecheapDomainsRead(context.Background(), d, testMeta(client))
 	require.False(t, diags.HasError(), "an empty portfolio must not be an error")
 	assert.Empty(t, d.Get("domains").([]interface{}))
 }
@@ -501,10 +503,149 @@ func TestDataSourceDomainsRead_Error(t *testing.T) {
 	})
 
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapDomains().Schema, map[string]interface{}{"list_type": "ALL"})
-	diags := dataSourceNamecheapDomainsRead(context.Background(), d, client)
+	diags := dataSourceNamecheapDomainsRead(context.Background(), d, testMeta(client))
 	assert.True(t, diags.HasError(), "a getList API error should surface")
 }
 
+// TestDataSourceDomainsRead_Filters applies the client-side filters and sort
+// after the whole listing is fetched.
+func TestDataSourceDomainsRead_Filters(t *testing.T) {
+	expiry := func(days int) string { return time.Now().UTC().AddDate(0, 0, days).Format("01/02/2006") }
+	rows := []dsDomainRow{
+		{ID: "1", Name: "zulu-example.com", Created: "01/01/2020", Expires: expiry(20), WhoisGuard: "ENABLED", AutoRenew: true, IsLocked: true, IsOurDNS: true},
+		{ID: "2", Name: "alpha-example.com", Created: "01/01/2021", Expires: expiry(5), WhoisGuard: "ENABLED", IsLocked: true, IsOurDNS: true},
+		{ID: "3", Name: "mike-example.co.uk", Created: "01/01/2019", Expires: expiry(10), WhoisGuard: "NOTPRESENT", IsLocked: true, IsOurDNS: true},
+		{ID: "4", Name: "bravo-example.net", Created: "01/01/2018", Expires: expiry(1), WhoisGuard: "ENABLED", IsLocked: true, IsOurDNS: true},
+		{ID: "5", Name: "unlocked-example.com", Created: "01/01/2018", Expires: expiry(2), WhoisGuard: "ENABLED", IsOurDNS: true},
+		{ID: "6", Name: "later-example.com", Created: "01/01/2018", Expires: expiry(90), WhoisGuard: "ENABLED", IsLocked: true, IsOurDNS: true},
+		{ID: "7", Name: "expired-example.com", Created: "01/01/2018", Expires: expiry(-3), WhoisGuard: "ENABLED", IsLocked: true, IsOurDNS: true, IsExpired: true},
+		{ID: "8", Name: "custom-ns-example.com", Created: "01/01/2018", Expires: expiry(3), WhoisGuard: "ENABLED", IsLocked: true},
+	}
+	client := startDataSourceServer(t, func(command string, _ *http.Request) string {
+		if command == "namecheap.domains.getList" {
+			return xmlGetListPage(rows, len(rows), 1, domainsPageSize)
+		}
+		return apiErrorXML("1010101", "unexpected command "+command)
+	})
+
+	names := func(d *schema.ResourceData) []string {
+		var names []string
+		for _, dom := range d.Get("domains").([]interface{}) {
+			names = append(names, dom.(map[string]interface{})["name"].(string))
+		}
+		return names
+	}
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		want   []string
+	}{
+		{
+			name: "expiry alerting",
+			config: map[string]interface{}{
+				"tlds":                []interface{}{"com", "CO.UK"},
+				"expires_within_days": 30,
+				"is_locked":           true,
+				"is_our_dns":          true,
+				"sort_by":             "expiredate",
+			},
+			want: []string{"alpha-example.com", "mike-example.co.uk", "zulu-example.com"},
+		},
+		{
+			name:   "explicit false",
+			config: map[string]interface{}{"auto_renew": false, "is_locked": false},
+			want:   []string{"unlocked-example.com"},
+		},
+		{
+			name:   "whois guard",
+			config: map[string]interface{}{"whois_guard": "notpresent"},
+			want:   []string{"mike-example.co.uk"},
+		},
+		{
+			name:   "expires today",
+			config: map[string]interface{}{"expires_within_days": 0},
+			want:   nil,
+		},
+		{
+			name:   "name descending",
+			config: map[string]interface{}{"tlds": []interface{}{"net", "co.uk"}, "sort_by": "NAME_DESC"},
+			want:   []string{"mike-example.co.uk", "bravo-example.net"},
+		},
+		{
+			name:   "created",
+			config: map[string]interface{}{"expires_within_days": 10, "is_our_dns": true, "sort_by": "CREATEDATE_DESC"},
+			want:   []string{"alpha-example.com", "mike-example.co.uk", "bravo-example.net", "unlocked-example.com"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.config["list_type"] = "ALL"
+			d := schema.TestResourceDataRaw(t, dataSourceNamecheapDomains().Schema, tc.config)
+			diags := dataSourceNamecheapDomainsRead(context.Background(), d, testMeta(client))
+			require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
+			assert.Equal(t, tc.want, names(d))
+		})
+	}
+}
+
+// TestDataSourceDomainsRead_MaxResults proves sort_by is sent as getList's
+// SortBy and that paging stops once max_results domains pass the filters.
+func TestDataSourceDomainsRead_MaxResults(t *testing.T) {
+	var rows []dsDomainRow
+	for i := 0; i < 250; i++ {
+		rows = append(rows, dsDomainRow{
+			ID: strconv.Itoa(i), Name: fmt.Sprintf("domain-%03d-example.com", i), Created: "06/02/2021", Expires: "06/02/2099",
+			WhoisGuard: "ENABLED", AutoRenew: i%2 == 0,
+		})
+	}
+	var mu sync.Mutex
+	var pages []int
+	client := startDataSourceServer(t, func(command string, r *http.Request) string {
+		if command != "namecheap.domains.getList" {
+			return apiErrorXML("1010101", "unexpected command "+command)
+		}
+		assert.Equal(t, "EXPIREDATE", r.FormValue("SortBy"))
+		page, _ := strconv.Atoi(r.FormValue("Page"))
+		mu.Lock()
+		pages = append(pages, page)
+		mu.Unlock()
+		start := (page - 1) * domainsPageSize
+		end := min(start+domainsPageSize, len(rows))
+		return xmlGetListPage(rows[start:end], len(rows), page, domainsPageSize)
+	})
+
+	tests := []struct {
+		name       string
+		maxResults int
+		wantPages  []int
+		wantLast   string
+	}{
+		{"first page is enough", 10, []int{1}, "domain-018-example.com"},
+		{"ends exactly on a page", 50, []int{1}, "domain-098-example.com"},
+		{"needs a second page", 60, []int{1, 2}, "domain-118-example.com"},
+		{"fewer matches than asked", 500, []int{1, 2, 3}, "domain-248-example.com"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pages = nil
+			d := schema.TestResourceDataRaw(t, dataSourceNamecheapDomains().Schema, map[string]interface{}{
+				"list_type":   "ALL",
+				"sort_by":     "expiredate",
+				"auto_renew":  true,
+				"max_results": tc.maxResults,
+			})
+			diags := dataSourceNamecheapDomainsRead(context.Background(), d, testMeta(client))
+			require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
+
+			domains := d.Get("domains").([]interface{})
+			assert.Len(t, domains, min(tc.maxResults, 125))
+			assert.Equal(t, tc.wantLast, domains[len(domains)-1].(map[string]interface{})["name"])
+			assert.Equal(t, tc.wantPages, pages)
+		})
+	}
+}
+
 // --- namecheap_domain_records ------------------------------------------------
 
 func TestDataSourceDomainRecordsRead_OurDNS(t *testing.T) {
@@ -523,7 +664,7 @@ func TestDataSourceDomainRecordsRead_OurDNS(t *testing.T) {
 	})
 
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapDomainRecords().Schema, map[string]interface{}{"domain": domain})
-	diags := dataSourceNamecheapDomainRecordsRead(context.Background(), d, client)
+	diags := dataSourceNamecheapDomainRecordsRead(context.Background(), d, testMeta(client))
 	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
 
 	assert.Equal(t, "MX", d.Get("email_type"))
@@ -551,7 +692,7 @@ func TestDataSourceDomainRecordsRead_CustomNS(t *testing.T) {
 	})
 
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapDomainRecords().Schema, map[string]interface{}{"domain": domain})
-	diags := dataSourceNamecheapDomainRecordsRead(context.Background(), d, client)
+	diags := dataSourceNamecheapDomainRecordsRead(context.Background(), d, testMeta(client))
 	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
 
 	ns := d.Get("nameservers").([]interface{})
@@ -567,7 +708,7 @@ func TestDataSourceDomainRecordsRead_GetListError(t *testing.T) {
 	})
 
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapDomainRecords().Schema, map[string]interface{}{"domain": domain})
-	diags := dataSourceNamecheapDomainRecordsRead(context.Background(), d, client)
+	diags := dataSourceNamecheapDomainRecordsRead(context.Background(), d, testMeta(client))
 	require.True(t, diags.HasError(), "a dns.getList error should surface")
 	assert.Contains(t, diags[0].Summary, domain)
 }
@@ -585,7 +726,186 @@ func TestDataSourceDomainRecordsRead_GetHostsError(t *testing.T) {
 	})
 
 	d := schema.TestResourceDataRaw(t, dataSourceNamecheapDomainRecords().Schema, map[string]interface{}{"domain": domain})
-	diags := dataSourceNamecheapDomainRecordsRead(context.Background(), d, client)
+	diags := dataSourceNamecheapDomainRecordsRead(context.Background(), d, testMeta(client))
 	require.True(t, diags.HasError(), "a dns.getHosts error should surface")
 	assert.Contains(t, diags[0].Summary, domain)
 }
+
+// --- namecheap_portfolio_records ---------------------------------------------
+
+// TestDataSourcePortfolioRecordsRead covers the three outcomes for a domain in
+// the listing: its records read, its nameservers being custom, and an error
+// that is reported for that domain alone.
+func TestDataSourcePortfolioRecordsRead(t *testing.T) {
+	rows := []dsDomainRow{
+		{ID: "1", Name: "one-example.com", User: "u", Created: "06/02/2021", Expires: "06/02/2099", WhoisGuard: "ENABLED", IsOurDNS: true},
+		{ID: "2", Name: "custom-ns-example.com", User: "u", Created: "06/02/2021", Expires: "06/02/2099", WhoisGuard: "ENABLED"},
+		{ID: "3", Name: "broken-example.com", User: "u", Created: "06/02/2021", Expires: "06/02/2099", WhoisGuard: "ENABLED", IsOurDNS: true},
+		{ID: "4", Name: "two-example.com", User: "u", Created: "06/02/2021", Expires: "06/02/2099", WhoisGuard: "ENABLED", IsOurDNS: true},
+	}
+	client := startDataSourceServer(t, func(command string, r *http.Request) string {
+		domain := r.FormValue("SLD") + "." + r.FormValue("TLD")
+		switch command {
+		case "namecheap.domains.getList":
+			return xmlGetListPage(rows, len(rows), 1, domainsPageSize)
+		case "namecheap.domains.dns.getList":
+			if domain == "custom-ns-example.com" {
+				return xmlDNSGetList(domain, false, []string{"dns1.p01.nsone.net", "dns2.p01.nsone.net"})
+			}
+			return xmlDNSGetList(domain, true, nil)
+		case "namecheap.domains.dns.getHosts":
+			switch domain {
+			case "broken-example.com":
+				return apiErrorXML("2030288", "Cannot complete this command as this domain has a lock")
+			case "one-example.com":
+				return xmlDNSGetHosts(domain, "MX", []dsHost{
+					{Name: "@", Type: "A", Address: "10.0.0.1", MXPref: 10, TTL: 1800},
+					{Name: "www", Type: "CNAME", Address: "parkingpage.namecheap.com.", MXPref: 10, TTL: 1800},
+				})
+			}
+			return xmlDNSGetHosts(domain, "NONE", []dsHost{
+				{Name: "mail", Type: "CNAME", Address: "mail.example.net", MXPref: 10, TTL: 3600},
+			})
+		}
+		return apiErrorXML("1010101", "unexpected command "+command)
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceNamecheapPortfolioRecords().Schema, map[string]interface{}{"list_type": "ALL"})
+	diags := dataSourceNamecheapPortfolioRecordsRead(context.Background(), d, testMeta(client))
+	require.False(t, diags.HasError(), "one bad domain must not fail the read: %+v", diags)
+
+	records := d.Get("records").([]interface{})
+	require.Len(t, records, 2, "the default parking CNAME is filtered out")
+	first := records[0].(map[string]interface{})
+	assert.Equal(t, "one-example.com", first["domain"])
+	assert.Equal(t, "@", first["hostname"])
+	assert.Equal(t, "A", first["type"])
+	assert.Equal(t, "10.0.0.1", first["address"])
+	assert.Equal(t, 1800, first["ttl"])
+	second := records[1].(map[string]interface{})
+	assert.Equal(t, "two-example.com", second["domain"])
+	assert.Equal(t, "mail.example.net.", second["address"])
+
+	assert.Equal(t, []interface{}{"custom-ns-example.com"}, d.Get("custom_nameserver_domains"))
+
+	readErrors := d.Get("errors").([]interface{})
+	require.Len(t, readErrors, 1)
+	readError := readErrors[0].(map[string]interface{})
+	assert.Equal(t, "broken-example.com", readError["domain"])
+	assert.Contains(t, readError["error"], "has a lock")
+	assert.Equal(t, "portfolio_records:ALL:", d.Id())
+}
+
+func TestDataSourcePortfolioRecordsRead_ListError(t *testing.T) {
+	client := startDataSourceServer(t, func(command string, _ *http.Request) string {
+		return apiErrorXML("4022336", "listing failed")
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceNamecheapPortfolioRecords().Schema, map[string]interface{}{"list_type": "ALL"})
+	diags := dataSourceNamecheapPortfolioRecordsRead(context.Background(), d, testMeta(client))
+	assert.True(t, diags.HasError(), "without the listing there is nothing to read")
+}
+
+// TestDataSourcePortfolioRecordsRead_Deadline keeps what was read when the
+// deadline passes part-way, and lists the domains not reached in errors.
+func TestDataSourcePortfolioRecordsRead_Deadline(t *testing.T) {
+	rows := []dsDomainRow{
+		{ID: "1", Name: "one-example.com", User: "u", Created: "06/02/2021", Expires: "06/02/2099", WhoisGuard: "ENABLED", IsOurDNS: true},
+		{ID: "2", Name: "two-example.com", User: "u", Created: "06/02/2021", Expires: "06/02/2099", WhoisGuard: "ENABLED", IsOurDNS: true},
+		{ID: "3", Name: "three-example.com", User: "u", Created: "06/02/2021", Expires: "06/02/2099", WhoisGuard: "ENABLED", IsOurDNS: true},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := startDataSourceServer(t, func(command string, r *http.Request) string {
+		domain := r.FormValue("SLD") + "." + r.FormValue("TLD")
+		switch command {
+		case "namecheap.domains.getList":
+			return xmlGetListPage(rows, len(rows), 1, domainsPageSize)
+		case "namecheap.domains.dns.getList":
+			return xmlDNSGetList(domain, true, nil)
+		case "namecheap.domains.dns.getHosts":
+			// The deadline passes while the first domain is read.
+			cancel()
+			return xmlDNSGetHosts(domain, "NONE", nil)
+		}
+		return apiErrorXML("1010101", "unexpected command "+command)
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceNamecheapPortfolioRecords().Schema, map[string]interface{}{"list_type": "ALL"})
+	diags := dataSourceNamecheapPortfolioRecordsRead(ctx, d, testMeta(client))
+	require.False(t, diags.HasError(), "a deadline must not fail the read: %+v", diags)
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Equal(t, "2 of 3 domains were not read before the deadline", diags[0].Summary)
+
+	unread := map[string]string{}
+	for _, raw := range d.Get("errors").([]interface{}) {
+		readError := raw.(map[string]interface{})
+		unread[readError["domain"].(string)] = readError["error"].(string)
+	}
+	for _, domain := range []string{"two-example.com", "three-example.com"} {
+		assert.Contains(t, unread[domain], "not read before the deadline", domain)
+	}
+	assert.Equal(t, "portfolio_records:ALL:", d.Id())
+}
+
+// TestDataSourceDomainRecordsRead_Filters keeps the records matching any
+// filter block, and groups only those into records_by_type and
+// records_by_hostname.
+func TestDataSourceDomainRecordsRead_Filters(t *testing.T) {
+	const domain = "records-example.com"
+	client := startDataSourceServer(t, func(command string, _ *http.Request) string {
+		switch command {
+		case "namecheap.domains.dns.getList":
+			return xmlDNSGetList(domain, true, nil)
+		case "namecheap.domains.dns.getHosts":
+			return xmlDNSGetHosts(domain, "MX", []dsHost{
+				{Name: "@", Type: "A", Address: "10.0.0.1", MXPref: 10, TTL: 1800},
+				{Name: "www", Type: "A", Address: "10.0.0.2", MXPref: 10, TTL: 300},
+				{Name: "WWW", Type: "AAAA", Address: "2001:db8::2", MXPref: 10, TTL: 300},
+				{Name: "@", Type: "MX", Address: "mx1.mail.example.net.", MXPref: 10, TTL: 3600},
+				{Name: "@", Type: "MX", Address: "mx2.mail.example.net.", MXPref: 20, TTL: 3600},
+				{Name: "_dmarc", Type: "TXT", Address: "v=DMARC1; p=none", MXPref: 10, TTL: 1800},
+			})
+		}
+		return apiErrorXML("1010101", "unexpected command "+command)
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceNamecheapDomainRecords().Schema, map[string]interface{}{
+		"domain": domain,
+		"filter": []interface{}{
+			map[string]interface{}{"hostname": "w*", "max_ttl": 600},
+			map[string]interface{}{"types": []interface{}{"mx"}, "address": `^mx1\.`},
+		},
+	})
+	diags := dataSourceNamecheapDomainRecordsRead(context.Background(), d, testMeta(client))
+	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
+
+	records := d.Get("records").([]interface{})
+	require.Len(t, records, 3)
+	var fqdns []string
+	for _, r := range records {
+		fqdns = append(fqdns, r.(map[string]interface{})["fqdn"].(string))
+	}
+	assert.Equal(t, []string{"www.records-example.com", "www.records-example.com", "records-example.com"}, fqdns)
+
+	byType := d.Get("records_by_type").(map[string]interface{})
+	assert.Len(t, byType, 3)
+	assert.JSONEq(t, `[{"hostname":"@","type":"MX","address":"mx1.mail.example.net.","mx_pref":10,"ttl":3600,"fqdn":"records-example.com"}]`,
+		byType["MX"].(string))
+
+	byHostname := d.Get("records_by_hostname").(map[string]interface{})
+	assert.Len(t, byHostname, 3, "hostnames are grouped as the API spells them")
+	assert.Contains(t, byHostname["www"], "10.0.0.2")
+	assert.Contains(t, byHostname["WWW"], "2001:db8::2")
+}
+
+func TestDataSourceDomainRecordsRead_FilterTTLRange(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, dataSourceNamecheapDomainRecords().Schema, map[string]interface{}{
+		"domain": "records-example.com",
+		"filter": []interface{}{map[string]interface{}{"min_ttl": 3600, "max_ttl": 60}},
+	})
+	diags := dataSourceNamecheapDomainRecordsRead(context.Background(), d, testMeta(newDataSourceTestClient("http://127.0.0.1:0")))
+	require.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "min_ttl (3600) is greater than max_ttl (60)")
+}
@@ -42,7 +42,7 @@ func TestDataSourcesInternalValidate(t *testing.T) {
 // namecheap_domain_records resource attribute-for-attribute, so a data-source
 // record composes into a resource record block without field remapping.
 func TestDomainRecordFieldParity(t *testing.T) {
-	resourceRecord := resourceNamecheapDomainRecords().Schema["record"]
+	resourceRecord := resourceNamecheapDomainRecords(nil).Schema["record"]
 	resourceElem := resourceRecord.Elem.(*schema.Resource).Schema
 
 	dataElem := domainRecordElemSchema()
@@ -106,3 +106,19 @@ func TestDaysUntilTimezoneSafe(t *testing.T) {
 	target := time.Date(2026, 7, 10, 1, 0, 0, 0, loc)
 	assert.Equal(t, 0, daysUntil(target, now))
 }
+
+func TestDomainRecordFQDN(t *testing.T) {
+	assert.Equal(t, "example.com", domainRecordFQDN("@", "example.com"))
+	assert.Equal(t, "example.com", domainRecordFQDN("", "example.com"))
+	assert.Equal(t, "www.example.com", domainRecordFQDN("WWW", "example.com"))
+	assert.Equal(t, "*.dev.example.com", domainRecordFQDN("*.dev", "example.com"))
+}
+
+func TestValidateHostnameGlob(t *testing.T) {
+	for _, ok := range []string{"www", "*", "_*", "*.dev", "mail?"} {
+		_, errs := validateHostnameGlob(ok, "hostname")
+		assert.Empty(t, errs, ok)
+	}
+	_, errs := validateHostnameGlob("[www", "hostname")
+	assert.NotEmpty(t, errs)
+}
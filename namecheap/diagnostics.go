@@ -31,6 +31,9 @@ import (
 // surfaces it as a pre-request HTTP 405 that go-namecheap-sdk absorbs in its
 // transport retry layer, so it never reaches this function as an *APIError
 // (set the requests_per_minute provider option to avoid it).
+//
+// An error that ran out of time is explained by timeoutDiagnostic, since the
+// bare "context deadline exceeded" names neither deadline that can cause it.
 func diagFromClientError(err error) diag.Diagnostics {
 	var apiErr *namecheap.APIError
 	if errors.As(err, &apiErr) {
@@ -47,7 +50,8 @@ func diagFromClientError(err error) diag.Diagnostics {
 						"(1) api_user/api_key are correct for the target environment (sandbox credentials differ from production; set use_sandbox to match); "+
 						"(2) API access is enabled for the account; "+
 						"(3) the public IP this provider calls from is whitelisted at "+
-						"https://ap.www.namecheap.com/settings/tools/apiaccess/whitelisted-ips - the most common cause in CI, where the runner's egress IP changes (set client_ip to that egress IP).",
+						"https://ap.www.namecheap.com/settings/tools/apiaccess/whitelisted-ips - the most common cause in CI, where the runner's egress IP changes (set client_ip to that egress IP). "+
+						"The namecheap_api_access data source, or the provider's verify_credentials_on_configure argument, reports which of these applies.",
 					apiErr.Number, apiErr.Message,
 				),
 			}}
@@ -75,5 +79,9 @@ func diagFromClientError(err error) diag.Diagnostics {
 		}
 	}
 
+	if diags := timeoutDiagnostic(err); diags != nil {
+		return diags
+	}
+
 	return diag.FromErr(err)
 }
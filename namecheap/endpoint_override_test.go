@@ -9,6 +9,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // In a normal (non-testacc) build the endpoint override must be a no-op so the
@@ -27,8 +28,9 @@ func TestProviderConfigureIgnoresEndpointOverrideInReleaseBuild(t *testing.T) {
 	diags := rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
 	assert.False(t, diags.HasError(), "unexpected diags: %v", diags)
 
-	client, ok := rawProvider.Meta().(*namecheap.Client)
-	assert.True(t, ok, "expected provider meta to be *namecheap.Client")
+	meta, ok := rawProvider.Meta().(*providerMeta)
+	require.True(t, ok, "expected provider meta to be *providerMeta")
+	client := meta.client
 
 	// Assert the exact default endpoint (production, since use_sandbox=false) so
 	// this catches a redirect to ANY other host, not merely a loopback one.
@@ -1,35 +1,60 @@
 package mutexkv
 
 import (
+	"context"
 	"sync"
 )
 
 // MutexKV is a simple key/value store for arbitrary mutexes. It can be used to
 // serialize changes across arbitrary collaborators that share knowledge of the
 // keys they must serialize on.
+//
+// Each mutex is a one-slot channel rather than a sync.Mutex so that a waiter can
+// give up when its context ends; see LockContext.
 type MutexKV struct {
 	lock  sync.Mutex
-	store map[string]*sync.Mutex
+	store map[string]chan struct{}
 }
 
 // Lock locks the mutex for the given key. Caller is responsible for calling Unlock
 // for the same key
 func (m *MutexKV) Lock(key string) {
-	m.get(key).Lock()
+	m.get(key) <- struct{}{}
+}
+
+// LockContext locks the mutex for the given key like Lock, but stops waiting
+// and returns ctx.Err() once ctx is done. Only a nil return holds the lock;
+// caller is then responsible for calling Unlock for the same key
+func (m *MutexKV) LockContext(ctx context.Context, key string) error {
+	// A context that has already ended must not win a free mutex: select picks
+	// at random between ready cases.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case m.get(key) <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // Unlock the mutex for the given key. Caller must have called Lock for the same key first
 func (m *MutexKV) Unlock(key string) {
-	m.get(key).Unlock()
+	select {
+	case <-m.get(key):
+	default:
+		panic("mutexkv: unlock of unlocked key " + key)
+	}
 }
 
 // get returns a mutex for the given key, no guarantee of its lock status
-func (m *MutexKV) get(key string) *sync.Mutex {
+func (m *MutexKV) get(key string) chan struct{} {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 	mutex, ok := m.store[key]
 	if !ok {
-		mutex = &sync.Mutex{}
+		mutex = make(chan struct{}, 1)
 		m.store[key] = mutex
 	}
 	return mutex
@@ -38,6 +63,6 @@ func (m *MutexKV) get(key string) *sync.Mutex {
 // NewMutexKV returns a properly initialized MutexKV
 func NewMutexKV() *MutexKV {
 	return &MutexKV{
-		store: make(map[string]*sync.Mutex),
+		store: make(map[string]chan struct{}),
 	}
 }
@@ -1,6 +1,8 @@
 package mutexkv
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 )
@@ -65,3 +67,58 @@ func TestMutexKVDifferentKeys(t *testing.T) {
 		t.Fatal("Second lock on a different key blocked. This shouldn't happen.")
 	}
 }
+
+func TestMutexKVLockContextTimesOut(t *testing.T) {
+	mkv := NewMutexKV()
+
+	mkv.Lock("foo")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := mkv.LockContext(ctx, "foo"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("LockContext on a held key returned %v, want context.DeadlineExceeded", err)
+	}
+
+	// The abandoned wait must not have taken the lock behind the holder's back.
+	mkv.Unlock("foo")
+	if err := mkv.LockContext(context.Background(), "foo"); err != nil {
+		t.Fatalf("LockContext after unlock: %v", err)
+	}
+}
+
+func TestMutexKVLockContextAcquires(t *testing.T) {
+	mkv := NewMutexKV()
+
+	mkv.Lock("foo")
+
+	errCh := make(chan error)
+
+	go func() {
+		errCh <- mkv.LockContext(context.Background(), "foo")
+	}()
+
+	mkv.Unlock("foo")
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("LockContext: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("LockContext blocked after unlock. This shouldn't happen.")
+	}
+}
+
+func TestMutexKVLockContextCanceled(t *testing.T) {
+	mkv := NewMutexKV()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Even a free key is refused once the context has ended.
+	if err := mkv.LockContext(ctx, "foo"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("LockContext with a canceled context returned %v, want context.Canceled", err)
+	}
+	mkv.Lock("foo")
+}
@@ -162,6 +162,73 @@ data "namecheap_domains" "all" {
 	})
 }
 
+// TestAccMockDataSourceDomainsFilters narrows the portfolio client-side. An
+// explicit false must filter rather than read as unset, which only holds if
+// the configuration reaches the read as written.
+func TestAccMockDataSourceDomainsFilters(t *testing.T) {
+	m := newNamecheapMock(t)
+	m.seedPortfolio(0,
+		mockPortfolioDomain{ID: "1", Name: "alpha-example.com", User: "mock-user", Created: "06/02/2021", Expires: "06/02/2099", WhoisGuard: "ENABLED", IsLocked: true, AutoRenew: true, IsOurDNS: true},
+		mockPortfolioDomain{ID: "2", Name: "zulu-example.com", User: "mock-user", Created: "06/02/2021", Expires: "06/02/2098", WhoisGuard: "ENABLED", AutoRenew: false, IsOurDNS: true},
+		mockPortfolioDomain{ID: "3", Name: "beta-example.net", User: "mock-user", Created: "06/02/2021", Expires: "06/02/2097", WhoisGuard: "ENABLED", AutoRenew: false, IsOurDNS: true},
+		mockPortfolioDomain{ID: "4", Name: "gamma-example.com", User: "mock-user", Created: "06/02/2021", Expires: "06/02/2096", WhoisGuard: "ENABLED", AutoRenew: false},
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { mockPreCheck(t, m) },
+		ProviderFactories: mockProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "namecheap_domains" "manual" {
+  auto_renew = false
+  is_our_dns = true
+  sort_by    = "NAME_DESC"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.namecheap_domains.manual", "domains.#", "2"),
+					resource.TestCheckResourceAttr("data.namecheap_domains.manual", "domains.0.name", "zulu-example.com"),
+					resource.TestCheckResourceAttr("data.namecheap_domains.manual", "domains.1.name", "beta-example.net"),
+				),
+			},
+		},
+	})
+}
+
+// TestAccMockDataSourceDomainsSortAndLimit asks Namecheap for the portfolio
+// by expiry date and keeps the two soonest-expiring domains. With one domain
+// per mock page, the mock sorting is what puts them on the first pages.
+func TestAccMockDataSourceDomainsSortAndLimit(t *testing.T) {
+	m := newNamecheapMock(t)
+	m.seedPortfolio(1,
+		mockPortfolioDomain{ID: "1", Name: "alpha-example.com", User: "mock-user", Created: "06/02/2021", Expires: "06/02/2099", WhoisGuard: "ENABLED"},
+		mockPortfolioDomain{ID: "2", Name: "beta-example.com", User: "mock-user", Created: "06/02/2021", Expires: "01/15/2097", WhoisGuard: "ENABLED"},
+		mockPortfolioDomain{ID: "3", Name: "gamma-example.com", User: "mock-user", Created: "06/02/2021", Expires: "03/10/2096", WhoisGuard: "ENABLED"},
+		mockPortfolioDomain{ID: "4", Name: "delta-example.com", User: "mock-user", Created: "06/02/2021", Expires: "12/31/2098", WhoisGuard: "ENABLED"},
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { mockPreCheck(t, m) },
+		ProviderFactories: mockProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "namecheap_domains" "soonest" {
+  sort_by     = "EXPIREDATE"
+  max_results = 2
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.namecheap_domains.soonest", "domains.#", "2"),
+					resource.TestCheckResourceAttr("data.namecheap_domains.soonest", "domains.0.name", "gamma-example.com"),
+					resource.TestCheckResourceAttr("data.namecheap_domains.soonest", "domains.1.name", "beta-example.com"),
+				),
+			},
+		},
+	})
+}
+
 // TestAccMockDataSourceDomainsPagination proves the data source paginates the
 // full portfolio: with a mock page-size cap of 1 and three seeded domains, all
 // three must be returned and the mock must have served getList at least once per
@@ -250,6 +317,51 @@ data "namecheap_domain_records" "customns" {
 	})
 }
 
+// TestAccMockDataSourceDomainRecordsFilter narrows namecheap_domain_records
+// with a filter block and reads the MX set back through records_by_type.
+func TestAccMockDataSourceDomainRecordsFilter(t *testing.T) {
+	m := newNamecheapMock(t)
+
+	const domain = "records-example.com"
+	m.seed(domain, []hostEntry{
+		{Name: "@", Type: "A", Address: "10.0.0.1", MXPref: 10, TTL: 1800},
+		{Name: "@", Type: "MX", Address: "mx1.mail.example.net.", MXPref: 10, TTL: 3600},
+		{Name: "@", Type: "MX", Address: "mx2.mail.example.net.", MXPref: 20, TTL: 3600},
+		{Name: "www", Type: "CNAME", Address: "records-example.com.", MXPref: 10, TTL: 3600},
+	}, "MX", nil)
+
+	const dataSource = "data.namecheap_domain_records.mail"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { mockPreCheck(t, m) },
+		ProviderFactories: mockProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+data "namecheap_domain_records" "mail" {
+  domain = %q
+
+  filter {
+    types = ["MX"]
+  }
+}
+
+output "mx_preferences" {
+  value = join(",", [for r in jsondecode(data.namecheap_domain_records.mail.records_by_type["MX"]) : r.mx_pref])
+}
+`, domain),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSource, "records.#", "2"),
+					resource.TestCheckResourceAttr(dataSource, "records.0.fqdn", domain),
+					resource.TestCheckResourceAttr(dataSource, "records_by_type.%", "1"),
+					resource.TestCheckResourceAttr(dataSource, "records_by_hostname.%", "1"),
+					resource.TestCheckResourceAttr(dataSource, "records.1.address", "mx2.mail.example.net."),
+					resource.TestCheckOutput("mx_preferences", "10,20"),
+				),
+			},
+		},
+	})
+}
+
 // TestAccMockDataSourcePortfolioComposition exercises the headline composition
 // pattern end-to-end: a namecheap_domains data source drives the creation of a
 // uniform SPF record on every domain in the portfolio (data source -> 3 mock
@@ -312,3 +424,48 @@ resource "namecheap_domain_records" "spf" {
 		},
 	})
 }
+
+// TestAccMockDataSourcePortfolioRecords reads every portfolio domain's records
+// into one list through namecheap_portfolio_records, with a custom-nameserver
+// domain listed on its own instead of read.
+func TestAccMockDataSourcePortfolioRecords(t *testing.T) {
+	m := newNamecheapMock(t)
+	m.seedPortfolio(0,
+		mockPortfolioDomain{ID: "1", Name: "alpha-example.com", User: "mock-user", Created: "06/02/2021", Expires: "06/02/2099", WhoisGuard: "ENABLED", IsOurDNS: true},
+		mockPortfolioDomain{ID: "2", Name: "custom-ns-example.com", User: "mock-user", Created: "06/02/2021", Expires: "06/02/2099", WhoisGuard: "ENABLED"},
+		mockPortfolioDomain{ID: "3", Name: "beta-example.net", User: "mock-user", Created: "06/02/2021", Expires: "06/02/2099", WhoisGuard: "ENABLED", IsOurDNS: true},
+	)
+	m.seed("alpha-example.com", []hostEntry{
+		{Name: "@", Type: "A", Address: "10.0.0.1", MXPref: 10, TTL: 1800},
+	}, "NONE", nil)
+	m.seed("custom-ns-example.com", nil, "NONE", []string{"dns1.p01.nsone.net", "dns2.p01.nsone.net"})
+	m.seed("beta-example.net", []hostEntry{
+		{Name: "@", Type: "TXT", Address: "v=spf1 -all", MXPref: 10, TTL: 3600},
+		{Name: "www", Type: "A", Address: "10.0.0.2", MXPref: 10, TTL: 1800},
+	}, "NONE", nil)
+
+	const dataSource = "data.namecheap_portfolio_records.all"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { mockPreCheck(t, m) },
+		ProviderFactories: mockProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "namecheap_portfolio_records" "all" {
+  list_type = "ALL"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSource, "records.#", "3"),
+					resource.TestCheckResourceAttr(dataSource, "records.0.domain", "alpha-example.com"),
+					resource.TestCheckResourceAttr(dataSource, "records.0.address", "10.0.0.1"),
+					resource.TestCheckResourceAttr(dataSource, "records.2.domain", "beta-example.net"),
+					resource.TestCheckResourceAttr(dataSource, "records.2.hostname", "www"),
+					resource.TestCheckResourceAttr(dataSource, "custom_nameserver_domains.#", "1"),
+					resource.TestCheckResourceAttr(dataSource, "custom_nameserver_domains.0", "custom-ns-example.com"),
+					resource.TestCheckResourceAttr(dataSource, "errors.#", "0"),
+				),
+			},
+		},
+	})
+}
@@ -4,6 +4,7 @@ package namecheap_provider
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
@@ -59,10 +60,11 @@ func TestAccMockDomainContactsLifecycle(t *testing.T) {
 	m := newNamecheapMock(t)
 	const resourceName = "namecheap_domain_contacts.test"
 
-	configWithTech := func(regEmail string) string {
+	configWithTech := func(regEmail string, acknowledge bool) string {
 		return fmt.Sprintf(`
 resource "namecheap_domain_contacts" "test" {
-  domain = "%s"
+  domain                    = "%s"
+  acknowledge_transfer_lock = %t
 
   registrant {
     first_name     = "Jane"
@@ -88,7 +90,7 @@ resource "namecheap_domain_contacts" "test" {
     email_address  = "tech@example.com"
   }
 }
-`, mockContactsDomain, regEmail)
+`, mockContactsDomain, acknowledge, regEmail)
 	}
 
 	resource.Test(t, resource.TestCase{
@@ -96,7 +98,7 @@ resource "namecheap_domain_contacts" "test" {
 		ProviderFactories: mockProviderFactories(),
 		Steps: []resource.TestStep{
 			{
-				Config: configWithTech("jane@example.com"),
+				Config: configWithTech("jane@example.com", false),
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr(resourceName, "registrant.0.first_name", "Jane"),
 					resource.TestCheckResourceAttr(resourceName, "registrant.0.email_address", "jane@example.com"),
@@ -108,10 +110,16 @@ resource "namecheap_domain_contacts" "test" {
 					mockCheckContactField(m, mockContactsDomain, "AuxBilling", "EmailAddress", "jane@example.com"),
 				),
 			},
+			{
+				// A new registrant email is a material change of registrant on a
+				// gTLD, which the plan refuses without the acknowledgement.
+				Config:      configWithTech("jane.doe@example.com", false),
+				ExpectError: regexp.MustCompile(`material change of registrant`),
+			},
 			{
 				// Update the registrant email; the setContacts call must carry
 				// the new value and drop no other field.
-				Config: configWithTech("jane.doe@example.com"),
+				Config: configWithTech("jane.doe@example.com", true),
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr(resourceName, "registrant.0.email_address", "jane.doe@example.com"),
 					mockCheckContactField(m, mockContactsDomain, "Registrant", "EmailAddress", "jane.doe@example.com"),
@@ -123,6 +131,8 @@ resource "namecheap_domain_contacts" "test" {
 				ImportState:       true,
 				ImportStateId:     mockContactsDomain,
 				ImportStateVerify: true,
+				// Configuration-only; getContacts has nothing to import it from.
+				ImportStateVerifyIgnore: []string{"acknowledge_transfer_lock"},
 			},
 		},
 	})
@@ -178,11 +188,13 @@ func TestAccMockDomainContactsDrift(t *testing.T) {
 				Config: config,
 			},
 			{
-				// Simulate an out-of-band change to the registrant email, then
-				// plan the unchanged config: refresh must observe the drift.
+				// Simulate an out-of-band change to the registrant city, then
+				// plan the unchanged config: refresh must observe the drift. (A
+				// changed name or e-mail would be planned back as a material
+				// change of registrant, which the plan refuses unacknowledged.)
 				PreConfig: func() {
 					st := m.state(mockContactsDomain)
-					st.contacts["Registrant"]["EmailAddress"] = "hacked@evil.example"
+					st.contacts["Registrant"]["City"] = "Porto"
 				},
 				Config:             config,
 				PlanOnly:           true,
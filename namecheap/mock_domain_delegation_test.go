@@ -0,0 +1,140 @@
+//go:build testacc
+
+package namecheap_provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// Mock-backed acceptance coverage for namecheap_domain_delegation. The mock
+// refuses a setCustom naming an in-bailiwick host without glue and the delete
+// of glue the delegation still names, so each step passing also proves the
+// order of the writes.
+
+const delegationTestDomain = "delegation-example.com"
+
+// TestAccMockDomainDelegationLifecycle walks a delegation to two in-bailiwick
+// nameservers, a step that renumbers ns1, swaps ns2 for an external
+// nameserver, and destroy.
+func TestAccMockDomainDelegationLifecycle(t *testing.T) {
+	m := newNamecheapMock(t)
+	m.seed(delegationTestDomain, nil, "NONE", nil)
+	ns1 := "ns1." + delegationTestDomain
+	ns2 := "ns2." + delegationTestDomain
+	const resourceName = "namecheap_domain_delegation.test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { mockPreCheck(t, m) },
+		ProviderFactories: mockProviderFactories(),
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			mockCheckNameserversDefault(m, delegationTestDomain),
+			mockCheckNameserverAbsent(m, delegationTestDomain, ns1),
+			mockCheckNameserverAbsent(m, delegationTestDomain, ns2),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "namecheap_domain_delegation" "test" {
+  domain = %q
+
+  nameserver {
+    host = %q
+    ip   = "93.184.216.34"
+  }
+
+  nameserver {
+    host = %q
+    ip   = "93.184.216.35"
+  }
+}
+`, delegationTestDomain, ns1, ns2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "id", delegationTestDomain),
+					resource.TestCheckResourceAttr(resourceName, "nameserver.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "nameserver.0.ip", "93.184.216.34"),
+					mockCheckNameserverIP(m, delegationTestDomain, ns1, "93.184.216.34"),
+					mockCheckNameserverIP(m, delegationTestDomain, ns2, "93.184.216.35"),
+					mockCheckNameservers(m, delegationTestDomain, ns1, ns2),
+				),
+			},
+			{
+				Config: fmt.Sprintf(`
+resource "namecheap_domain_delegation" "test" {
+  domain = %q
+
+  nameserver {
+    host = %q
+    ip   = "93.184.216.36"
+  }
+
+  nameserver {
+    host = "ns1.provider.net"
+  }
+}
+`, delegationTestDomain, ns1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "nameserver.1.host", "ns1.provider.net"),
+					mockCheckNameserverIP(m, delegationTestDomain, ns1, "93.184.216.36"),
+					mockCheckNameserverAbsent(m, delegationTestDomain, ns2),
+					mockCheckNameservers(m, delegationTestDomain, ns1, "ns1.provider.net"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateId:     delegationTestDomain,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+// TestAccMockDomainDelegationDrift covers glue deleted and a delegation reset
+// outside Terraform: each plans the write that restores it.
+func TestAccMockDomainDelegationDrift(t *testing.T) {
+	m := newNamecheapMock(t)
+	m.seed(delegationTestDomain, nil, "NONE", nil)
+	ns1 := "ns1." + delegationTestDomain
+	config := fmt.Sprintf(`
+resource "namecheap_domain_delegation" "test" {
+  domain = %q
+
+  nameserver {
+    host = %q
+    ip   = "93.184.216.34"
+  }
+
+  nameserver {
+    host = "ns1.provider.net"
+  }
+}
+`, delegationTestDomain, ns1)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { mockPreCheck(t, m) },
+		ProviderFactories: mockProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				PreConfig: func() {
+					m.resetDelegation(delegationTestDomain, ns1)
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					mockCheckNameserverIP(m, delegationTestDomain, ns1, "93.184.216.34"),
+					mockCheckNameservers(m, delegationTestDomain, ns1, "ns1.provider.net"),
+				),
+			},
+		},
+	})
+}
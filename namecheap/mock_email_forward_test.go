@@ -0,0 +1,124 @@
+//go:build testacc
+
+package namecheap_provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+// Mock-backed acceptance coverage for namecheap_email_forward. As for
+// namecheap_domain_host_record, the property to prove is that the
+// read-modify-write leaves the aliases this resource does not own alone.
+
+func emailForwardConfig(aliases map[string]string) string {
+	var blocks string
+	for mailbox, dest := range aliases {
+		name := mailbox
+		if name == "*" {
+			name = "catchall"
+		}
+		blocks += fmt.Sprintf(`
+resource "namecheap_email_forward" %q {
+  domain     = %q
+  mailbox    = %q
+  forward_to = %q
+}
+`, name, mockEmailForwardingDomain, mailbox, dest)
+	}
+	return blocks
+}
+
+// mockCheckForwardAbsent asserts the mock holds no rule for mailbox.
+func mockCheckForwardAbsent(m *namecheapMock, domain, mailbox string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		if st := m.state(domain); st != nil {
+			if dest, ok := st.forwards[mailbox]; ok {
+				return fmt.Errorf("mock still forwards %s/%s to %q", domain, mailbox, dest)
+			}
+		}
+		return nil
+	}
+}
+
+// TestAccMockEmailForwardLifecycle walks create (two aliases in one apply),
+// update, import and destroy of individual aliases, asserting at every step
+// that a pre-seeded alias nobody manages survives.
+func TestAccMockEmailForwardLifecycle(t *testing.T) {
+	m := newNamecheapMock(t)
+	m.seedForwards(mockEmailForwardingDomain, map[string]string{
+		"legacy": "legacy-dest@example.com",
+	})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { mockPreCheck(t, m) },
+		ProviderFactories: mockProviderFactories(),
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			mockCheckForwardAbsent(m, mockEmailForwardingDomain, "info"),
+			mockCheckForward(m, mockEmailForwardingDomain, "legacy", "legacy-dest@example.com"),
+			mockCheckForwardCount(m, mockEmailForwardingDomain, 1),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: emailForwardConfig(map[string]string{
+					"info":  "info-dest@example.com",
+					"sales": "sales-dest@example.com",
+				}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("namecheap_email_forward.info", "id", mockEmailForwardingDomain+"/info"),
+					mockCheckForwardCount(m, mockEmailForwardingDomain, 3),
+					mockCheckForward(m, mockEmailForwardingDomain, "info", "info-dest@example.com"),
+					mockCheckForward(m, mockEmailForwardingDomain, "sales", "sales-dest@example.com"),
+					mockCheckForward(m, mockEmailForwardingDomain, "legacy", "legacy-dest@example.com"),
+				),
+			},
+			{
+				// Change one alias and drop the other.
+				Config: emailForwardConfig(map[string]string{
+					"info": "changed@example.com",
+				}),
+				Check: resource.ComposeTestCheckFunc(
+					mockCheckForwardCount(m, mockEmailForwardingDomain, 2),
+					mockCheckForward(m, mockEmailForwardingDomain, "info", "changed@example.com"),
+					mockCheckForwardAbsent(m, mockEmailForwardingDomain, "sales"),
+					mockCheckForward(m, mockEmailForwardingDomain, "legacy", "legacy-dest@example.com"),
+				),
+			},
+			{
+				ResourceName:      "namecheap_email_forward.info",
+				ImportState:       true,
+				ImportStateId:     mockEmailForwardingDomain + "/info",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+// TestAccMockEmailForwardDrift covers a destination changed outside Terraform:
+// the next refresh surfaces it as drift on this alias alone.
+func TestAccMockEmailForwardDrift(t *testing.T) {
+	m := newNamecheapMock(t)
+	config := emailForwardConfig(map[string]string{"info": "info-dest@example.com"})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { mockPreCheck(t, m) },
+		ProviderFactories: mockProviderFactories(),
+		CheckDestroy:      mockCheckForwardAbsent(m, mockEmailForwardingDomain, "info"),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				PreConfig: func() {
+					m.addForward(mockEmailForwardingDomain, "info", "elsewhere@example.com")
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
@@ -6,6 +6,8 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"slices"
+	"sort"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
@@ -39,7 +41,7 @@ func mockCheckForward(m *namecheapMock, domain, mailbox, wantDest string) resour
 		if !ok {
 			return fmt.Errorf("mock state for %q missing forward %q (have %+v)", domain, mailbox, st.forwards)
 		}
-		if got != wantDest {
+		if len(got) != 1 || got[0] != wantDest {
 			return fmt.Errorf("mock forward %s/%s = %q, want %q", domain, mailbox, got, wantDest)
 		}
 		return nil
@@ -47,15 +49,19 @@ func mockCheckForward(m *namecheapMock, domain, mailbox, wantDest string) resour
 }
 
 // mockCheckForwardCount asserts the mock persisted exactly n forwarding rules
-// for the domain.
+// for the domain, counting each destination of a mailbox as a rule.
 func mockCheckForwardCount(m *namecheapMock, domain string, n int) resource.TestCheckFunc {
 	return func(*terraform.State) error {
 		st := m.state(domain)
 		if st == nil {
 			return fmt.Errorf("mock has no state for %q", domain)
 		}
-		if len(st.forwards) != n {
-			return fmt.Errorf("mock forward count for %q = %d, want %d (have %+v)", domain, len(st.forwards), n, st.forwards)
+		rules := 0
+		for _, destinations := range st.forwards {
+			rules += len(destinations)
+		}
+		if rules != n {
+			return fmt.Errorf("mock forward count for %q = %d, want %d (have %+v)", domain, rules, n, st.forwards)
 		}
 		return nil
 	}
@@ -299,3 +305,157 @@ func TestAccMockEmailForwardingSetAPIError(t *testing.T) {
 		},
 	})
 }
+
+// mockCheckForwardDestinations asserts the mock holds exactly the given
+// destinations for a mailbox, in any order.
+func mockCheckForwardDestinations(m *namecheapMock, domain, mailbox string, want ...string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		st := m.state(domain)
+		if st == nil {
+			return fmt.Errorf("mock has no state for %q", domain)
+		}
+		got := slices.Clone(st.forwards[mailbox])
+		sort.Strings(got)
+		want = slices.Clone(want)
+		sort.Strings(want)
+		if !slices.Equal(got, want) {
+			return fmt.Errorf("mock forward %s/%s = %q, want %q", domain, mailbox, got, want)
+		}
+		return nil
+	}
+}
+
+const emailForwardingBlocksConfig = `
+resource "namecheap_email_forwarding" "test" {
+  domain = %q
+
+  forward {
+    mailbox      = "ops"
+    destinations = ["carol@example.com", "alice@example.com", "bob@example.com"]
+  }
+
+  forward {
+    mailbox      = "info"
+    destinations = ["info-dest@example.com"]
+  }
+}
+`
+
+// TestAccMockEmailForwardingMultipleDestinations covers distribution-list
+// aliases: one forwarding rule per destination is written, the API's rule
+// order never reads back as drift, a member added out-of-band does, and the
+// blocks survive an import.
+func TestAccMockEmailForwardingMultipleDestinations(t *testing.T) {
+	m := newNamecheapMock(t)
+	const resourceName = "namecheap_email_forwarding.test"
+	config := fmt.Sprintf(emailForwardingBlocksConfig, mockEmailForwardingDomain)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { mockPreCheck(t, m) },
+		ProviderFactories: mockProviderFactories(),
+		CheckDestroy:      mockCheckForwardsCleared(m, mockEmailForwardingDomain),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "forward.#", "2"),
+					mockCheckForwardCount(m, mockEmailForwardingDomain, 4),
+					mockCheckForwardDestinations(m, mockEmailForwardingDomain, "ops", "alice@example.com", "bob@example.com", "carol@example.com"),
+					mockCheckForward(m, mockEmailForwardingDomain, "info", "info-dest@example.com"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateId:     mockEmailForwardingDomain,
+				ImportStateVerify: true,
+			},
+			{
+				PreConfig: func() {
+					m.appendForward(mockEmailForwardingDomain, "ops", "dave@example.com")
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				// Applying the unchanged config removes the out-of-band member.
+				Config: config,
+				Check: mockCheckForwardDestinations(m, mockEmailForwardingDomain, "ops",
+					"alice@example.com", "bob@example.com", "carol@example.com"),
+			},
+		},
+	})
+}
+
+// mockCheckForwardingZoneKept asserts switching the email type left the one
+// seeded record of the domain's zone in place.
+func mockCheckForwardingZoneKept(m *namecheapMock, domain string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		st := m.state(domain)
+		if st == nil || len(st.hosts) != 1 || st.hosts[0].Address != "203.0.113.10" {
+			return fmt.Errorf("switching the email type must keep the zone's records; mock now has %+v", st)
+		}
+		return nil
+	}
+}
+
+// TestAccMockEmailForwardingManageEmailType covers manage_email_type: apply
+// switches the domain to FWD keeping its records, a type changed out-of-band
+// shows as drift, and destroy hands the domain back in its previous type.
+func TestAccMockEmailForwardingManageEmailType(t *testing.T) {
+	m := newNamecheapMock(t)
+	const resourceName = "namecheap_email_forwarding.test"
+	m.seed(mockEmailForwardingDomain, []hostEntry{
+		{Name: "@", Type: "A", Address: "203.0.113.10", TTL: 1800, MXPref: 10},
+	}, "NONE", nil)
+
+	config := fmt.Sprintf(`
+resource "namecheap_email_forwarding" "test" {
+  domain            = %q
+  manage_email_type = true
+
+  forwards = {
+    info = "info-dest@example.com"
+  }
+}
+`, mockEmailForwardingDomain)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { mockPreCheck(t, m) },
+		ProviderFactories: mockProviderFactories(),
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			mockCheckForwardsCleared(m, mockEmailForwardingDomain),
+			mockCheckEmailType(m, mockEmailForwardingDomain, "NONE"),
+			mockCheckForwardingZoneKept(m, mockEmailForwardingDomain),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "previous_email_type", "NONE"),
+					mockCheckEmailType(m, mockEmailForwardingDomain, "FWD"),
+					mockCheckForwardingZoneKept(m, mockEmailForwardingDomain),
+					mockCheckForward(m, mockEmailForwardingDomain, "info", "info-dest@example.com"),
+				),
+			},
+			{
+				PreConfig: func() {
+					m.seed(mockEmailForwardingDomain, []hostEntry{
+						{Name: "@", Type: "A", Address: "203.0.113.10", TTL: 1800, MXPref: 10},
+					}, "NONE", nil)
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "previous_email_type", "NONE"),
+					mockCheckEmailType(m, mockEmailForwardingDomain, "FWD"),
+				),
+			},
+		},
+	})
+}
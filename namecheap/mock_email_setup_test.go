@@ -0,0 +1,178 @@
+//go:build testacc
+
+package namecheap_provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+// Mock-backed acceptance coverage for namecheap_email_setup. As for
+// namecheap_domain_host_record, the property to prove is that the records the
+// resource does not own - here, an apex A and a site verification TXT living
+// next to the SPF policy - survive every write.
+
+const emailSetupTestDomain = "email-setup-example.com"
+
+func seedEmailSetupZone(m *namecheapMock) {
+	m.seed(emailSetupTestDomain, []hostEntry{
+		{Name: "@", Type: "A", Address: "203.0.113.10", TTL: 1800, MXPref: 10},
+		{Name: "@", Type: "TXT", Address: "google-site-verification=abc123", TTL: 1800, MXPref: 10},
+		{Name: "@", Type: "TXT", Address: "v=spf1 -all", TTL: 1800, MXPref: 10},
+	}, "NONE", nil)
+}
+
+// mockCheckHostAbsent asserts the mock holds no name/type record at address.
+func mockCheckHostAbsent(m *namecheapMock, domain, name, recordType, address string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		if st := m.state(domain); st != nil {
+			for _, h := range st.hosts {
+				if h.Name == name && h.Type == recordType && h.Address == address {
+					return fmt.Errorf("mock still holds %s %s -> %q for %s", name, recordType, address, domain)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func mockCheckEmailSetupBystanders(m *namecheapMock) resource.TestCheckFunc {
+	return resource.ComposeTestCheckFunc(
+		mockCheckHostContains(m, emailSetupTestDomain, "@", "A", "203.0.113.10"),
+		mockCheckHostContains(m, emailSetupTestDomain, "@", "TXT", "google-site-verification=abc123"),
+	)
+}
+
+// TestAccMockEmailSetupLifecycle walks a Google setup, a move to custom mail
+// servers that drops DMARC and turns the DKIM key into a CNAME, and destroy.
+func TestAccMockEmailSetupLifecycle(t *testing.T) {
+	m := newNamecheapMock(t)
+	seedEmailSetupZone(m)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { mockPreCheck(t, m) },
+		ProviderFactories: mockProviderFactories(),
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			mockCheckHostAbsent(m, emailSetupTestDomain, "@", "MX", "mx1.example.net."),
+			mockCheckHostAbsent(m, emailSetupTestDomain, "@", "TXT", "v=spf1 mx ~all"),
+			mockCheckHostAbsent(m, emailSetupTestDomain, "s1._domainkey", "CNAME", "s1.dkim.example.net."),
+			mockCheckEmailType(m, emailSetupTestDomain, "NONE"),
+			mockCheckHostCount(m, emailSetupTestDomain, 2),
+			mockCheckEmailSetupBystanders(m),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "namecheap_email_setup" "mail" {
+  domain         = %q
+  email_provider = "google"
+
+  dkim {
+    selector   = "s1"
+    public_key = "MIIBIjAN"
+  }
+
+  dmarc_policy = "none"
+  dmarc_rua    = ["dmarc@example.com"]
+}
+`, emailSetupTestDomain),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("namecheap_email_setup.mail", "id", emailSetupTestDomain),
+					resource.TestCheckResourceAttr("namecheap_email_setup.mail", "email_type", "MX"),
+					resource.TestCheckResourceAttr("namecheap_email_setup.mail", "records.#", "4"),
+					mockCheckEmailType(m, emailSetupTestDomain, "MX"),
+					mockCheckHostContains(m, emailSetupTestDomain, "@", "MX", "smtp.google.com."),
+					mockCheckHostContains(m, emailSetupTestDomain, "@", "TXT", "v=spf1 include:_spf.google.com ~all"),
+					mockCheckHostContains(m, emailSetupTestDomain, "s1._domainkey", "TXT", "v=DKIM1; k=rsa; p=MIIBIjAN"),
+					mockCheckHostContains(m, emailSetupTestDomain, "_dmarc", "TXT", "v=DMARC1; p=none; rua=mailto:dmarc@example.com"),
+					// The old SPF policy was replaced, not joined by a second one.
+					mockCheckHostAbsent(m, emailSetupTestDomain, "@", "TXT", "v=spf1 -all"),
+					mockCheckHostCount(m, emailSetupTestDomain, 6),
+					mockCheckEmailSetupBystanders(m),
+				),
+			},
+			{
+				Config: fmt.Sprintf(`
+resource "namecheap_email_setup" "mail" {
+  domain         = %q
+  email_provider = "custom"
+
+  mx {
+    address = "mx1.example.net"
+    mx_pref = 10
+  }
+
+  dkim {
+    selector = "s1"
+    target   = "s1.dkim.example.net"
+  }
+}
+`, emailSetupTestDomain),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("namecheap_email_setup.mail", "records.#", "3"),
+					mockCheckHostContains(m, emailSetupTestDomain, "@", "MX", "mx1.example.net."),
+					mockCheckHostAbsent(m, emailSetupTestDomain, "@", "MX", "smtp.google.com."),
+					mockCheckHostContains(m, emailSetupTestDomain, "@", "TXT", "v=spf1 mx ~all"),
+					mockCheckHostContains(m, emailSetupTestDomain, "s1._domainkey", "CNAME", "s1.dkim.example.net."),
+					mockCheckHostAbsent(m, emailSetupTestDomain, "s1._domainkey", "TXT", "v=DKIM1; k=rsa; p=MIIBIjAN"),
+					mockCheckHostAbsent(m, emailSetupTestDomain, "_dmarc", "TXT", "v=DMARC1; p=none; rua=mailto:dmarc@example.com"),
+					mockCheckHostCount(m, emailSetupTestDomain, 5),
+					mockCheckEmailSetupBystanders(m),
+				),
+			},
+		},
+	})
+}
+
+// TestAccMockEmailSetupDrift covers a record deleted and an email type changed
+// outside Terraform: each plans a write that puts it back.
+func TestAccMockEmailSetupDrift(t *testing.T) {
+	m := newNamecheapMock(t)
+	seedEmailSetupZone(m)
+	config := fmt.Sprintf(`
+resource "namecheap_email_setup" "mail" {
+  domain         = %q
+  email_provider = "private_email"
+  dmarc_policy   = "reject"
+}
+`, emailSetupTestDomain)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { mockPreCheck(t, m) },
+		ProviderFactories: mockProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				PreConfig: func() {
+					m.removeHost(emailSetupTestDomain, "_dmarc", "TXT")
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					mockCheckHostContains(m, emailSetupTestDomain, "_dmarc", "TXT", "v=DMARC1; p=reject"),
+					mockCheckEmailSetupBystanders(m),
+				),
+			},
+			{
+				PreConfig: func() {
+					m.seed(emailSetupTestDomain, m.state(emailSetupTestDomain).hosts, "MXE", nil)
+				},
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					mockCheckEmailType(m, emailSetupTestDomain, "MX"),
+					mockCheckHostContains(m, emailSetupTestDomain, "@", "MX", "mx1.privateemail.com."),
+					mockCheckHostContains(m, emailSetupTestDomain, "@", "MX", "mx2.privateemail.com."),
+				),
+			},
+		},
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
@@ -375,3 +376,61 @@ func assertNoCommands(m *namecheapMock, commands ...string) resource.TestCheckFu
 		return nil
 	}
 }
+
+// TestAccMockDataSourceRenewalForecast forecasts the renewals inside a 90-day
+// horizon and gates on the balance the way the docs recommend.
+func TestAccMockDataSourceRenewalForecast(t *testing.T) {
+	m := newNamecheapMock(t)
+	expires := func(days int) string { return time.Now().UTC().AddDate(0, 0, days).Format("01/02/2006") }
+	m.seedPortfolio(0,
+		mockPortfolioDomain{ID: "1", Name: "soon-example.com", Created: "06/02/2021", Expires: expires(10), AutoRenew: true},
+		mockPortfolioDomain{ID: "2", Name: "later-example.net", Created: "06/02/2021", Expires: expires(60)},
+		mockPortfolioDomain{ID: "3", Name: "far-example.com", Created: "06/02/2021", Expires: expires(200), AutoRenew: true},
+	)
+	m.seedPricing("RENEW", "com", mockPriceTier{Duration: 1, DurationType: "YEAR", Price: "14.58", RegularPrice: "14.58", YourPrice: "14.58", Currency: "USD"})
+	m.seedPricing("RENEW", "net", mockPriceTier{Duration: 1, DurationType: "YEAR", Price: "16.10", RegularPrice: "16.10", YourPrice: "16.10", Currency: "USD"})
+	m.seedBalances(mockAccountBalance{Currency: "USD", AvailableBalance: "10.00", AccountBalance: "10.00", EarnedAmount: "0.00", WithdrawableAmount: "0.00", FundsRequiredForAutoRenew: "14.58"})
+
+	const dataSource = "data.namecheap_renewal_forecast.next_quarter"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { mockPreCheck(t, m) },
+		ProviderFactories: mockProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "namecheap_renewal_forecast" "next_quarter" {
+  horizon_days = 90
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSource, "currency", "USD"),
+					resource.TestCheckResourceAttr(dataSource, "domains.#", "2"),
+					resource.TestCheckResourceAttr(dataSource, "domains.0.name", "soon-example.com"),
+					resource.TestCheckResourceAttr(dataSource, "domains.0.renewal_price", "14.58"),
+					resource.TestCheckResourceAttr(dataSource, "domains.1.auto_renew", "false"),
+					resource.TestCheckResourceAttr(dataSource, "total", "30.68"),
+					resource.TestCheckResourceAttr(dataSource, "auto_renew_total", "14.58"),
+					resource.TestCheckResourceAttr(dataSource, "unpriced_domains.#", "0"),
+					resource.TestCheckResourceAttr(dataSource, "funds_required_for_auto_renew", "14.58"),
+					resource.TestCheckResourceAttr(dataSource, "auto_renew_shortfall", "4.58"),
+					resource.TestCheckResourceAttr(dataSource, "balance_covers_auto_renew", "false"),
+				),
+			},
+			{
+				Config: `
+data "namecheap_renewal_forecast" "next_quarter" {
+  horizon_days = 90
+
+  lifecycle {
+    postcondition {
+      condition     = self.balance_covers_auto_renew
+      error_message = "Top up ${self.auto_renew_shortfall} ${self.currency} before the next auto-renewals."
+    }
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`Top up 4.58 USD`),
+			},
+		},
+	})
+}
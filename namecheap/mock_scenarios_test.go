@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
 // This file ports the representative MERGE/OVERWRITE record and nameserver
@@ -340,3 +341,71 @@ resource "namecheap_domain_records" "test" {
 }
 `, mockScenarioDomain, mode, list)
 }
+
+// TestAccMockProviderDefaults covers the provider's defaults block: unset record
+// attributes are written with the provider's values, and reading them back plans
+// no change — including the preference of a non-MX record, which Namecheap
+// reports as a fixed 10 whatever the default says.
+func TestAccMockProviderDefaults(t *testing.T) {
+	const resourceName = "namecheap_domain_records.test"
+
+	m := newNamecheapMock(t)
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { mockPreCheck(t, m) },
+		ProviderFactories: mockProviderFactories(),
+		CheckDestroy:      mockCheckHostsCleared(m, mockScenarioDomain),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "namecheap" {
+  defaults {
+    ttl          = 300
+    mx_pref      = 20
+    records_mode = "OVERWRITE"
+  }
+}
+
+resource "namecheap_domain_records" "test" {
+  domain = "%s"
+
+  record {
+    hostname = "www"
+    type     = "A"
+    address  = "10.11.12.13"
+  }
+
+  record {
+    hostname = "@"
+    type     = "MX"
+    address  = "mail.example.com"
+  }
+}
+`, mockScenarioDomain),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "mode", "OVERWRITE"),
+					resource.TestCheckTypeSetElemNestedAttrs(resourceName, "record.*", map[string]string{
+						"hostname": "www",
+						"ttl":      "300",
+						"mx_pref":  "20",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs(resourceName, "record.*", map[string]string{
+						"hostname": "@",
+						"ttl":      "300",
+						"mx_pref":  "20",
+					}),
+					func(*terraform.State) error {
+						for _, h := range m.state(mockScenarioDomain).hosts {
+							if h.TTL != 300 {
+								return fmt.Errorf("host %s/%s written with TTL %d, want the provider default 300", h.Name, h.Type, h.TTL)
+							}
+							if h.Type == "MX" && h.MXPref != 20 {
+								return fmt.Errorf("MX host written with preference %d, want the provider default 20", h.MXPref)
+							}
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
@@ -7,11 +7,13 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 // mockDefaultNameservers are the registrar nameservers the Namecheap API reports
@@ -37,9 +39,10 @@ type mockDomainState struct {
 	// is called.
 	contacts map[string]map[string]string
 	// forwards holds the domain's email forwarding table (mailbox alias ->
-	// destination address), keyed exactly as setEmailForwarding received it.
-	// nil until setEmailForwarding is called.
-	forwards map[string]string
+	// destination addresses, in the order received), keyed exactly as
+	// setEmailForwarding received it. A mailbox with several rules has several
+	// destinations. nil until setEmailForwarding is called.
+	forwards map[string][]string
 }
 
 // namecheapMock is a minimal STATEFUL mock of the Namecheap DNS API, sufficient
@@ -220,14 +223,15 @@ func (m *namecheapMock) seedForwards(domain string, forwards map[string]string)
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	st := m.stateFor(domain)
-	copied := make(map[string]string, len(forwards))
+	copied := make(map[string][]string, len(forwards))
 	for mailbox, dest := range forwards {
-		copied[mailbox] = dest
+		copied[mailbox] = []string{dest}
 	}
 	st.forwards = copied
 }
 
-// addForward adds or overwrites a single forwarding rule for a domain,
+// addForward adds or overwrites the forwarding rules of one mailbox, leaving it
+// with the single given destination,
 // simulating an out-of-band change (e.g. made through the dashboard) after
 // Terraform has already taken ownership of the resource. Safe to call
 // concurrently with the mock server handling requests, unlike mutating the
@@ -237,9 +241,22 @@ func (m *namecheapMock) addForward(domain, mailbox, destination string) {
 	defer m.mu.Unlock()
 	st := m.stateFor(domain)
 	if st.forwards == nil {
-		st.forwards = map[string]string{}
+		st.forwards = map[string][]string{}
 	}
-	st.forwards[mailbox] = destination
+	st.forwards[mailbox] = []string{destination}
+}
+
+// appendForward adds one more forwarding rule to a mailbox, keeping the
+// destinations it already has - the out-of-band way a distribution list
+// gains a member.
+func (m *namecheapMock) appendForward(domain, mailbox, destination string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := m.stateFor(domain)
+	if st.forwards == nil {
+		st.forwards = map[string][]string{}
+	}
+	st.forwards[mailbox] = append(st.forwards[mailbox], destination)
 }
 
 // removeHost deletes a record from a domain's zone, simulating an out-of-band
@@ -260,6 +277,19 @@ func (m *namecheapMock) removeHost(domain, host, recordType string) {
 	st.hosts = kept
 }
 
+// resetDelegation returns a domain to the default nameservers and deletes the
+// given personal nameservers, simulating a delegation torn down out of band.
+// Unlike the provider's own writes, it bypasses the mock's ordering checks.
+func (m *namecheapMock) resetDelegation(domain string, nameservers ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := m.stateFor(domain)
+	st.nameservers = nil
+	for _, ns := range nameservers {
+		delete(st.personalNS, ns)
+	}
+}
+
 // seedPortfolio sets the account portfolio returned by getList. cap, when >0,
 // caps the per-page size so a small seed still spans multiple pages (used to
 // exercise pagination end-to-end).
@@ -356,7 +386,7 @@ func (m *namecheapMock) handler(w http.ResponseWriter, r *http.Request) {
 		if pageSize <= 0 {
 			pageSize = 20
 		}
-		_, _ = io.WriteString(w, m.renderGetPortfolioXML(page, pageSize))
+		_, _ = io.WriteString(w, m.renderGetPortfolioXML(page, pageSize, r.FormValue("SortBy")))
 		return
 	case "namecheap.domains.getInfo":
 		_, _ = io.WriteString(w, m.renderGetInfoXML(r.FormValue("DomainName")))
@@ -383,7 +413,15 @@ func (m *namecheapMock) handler(w http.ResponseWriter, r *http.Request) {
 	case "namecheap.domains.dns.getList":
 		resp = renderGetListXML(domain, st)
 	case "namecheap.domains.dns.setCustom":
-		st.nameservers = splitNameservers(r.FormValue("Nameservers"))
+		// A nameserver under the domain itself needs its glue registered
+		// first; one without is refused, so a provider writing the delegation
+		// before the glue fails here instead of passing.
+		nameservers := splitNameservers(r.FormValue("Nameservers"))
+		if missing := missingNameserverGlue(domain, nameservers, st); missing != "" {
+			resp = apiErrorXML("2011146", "mock: nameserver "+missing+" has no glue registered")
+			break
+		}
+		st.nameservers = nameservers
 		resp = renderResultXML("DomainDNSSetCustomResult", domain, `Updated="true"`)
 	case "namecheap.domains.dns.setDefault":
 		st.nameservers = nil
@@ -406,14 +444,27 @@ func (m *namecheapMock) handler(w http.ResponseWriter, r *http.Request) {
 		resp = renderNSInfoXML(domain, ns, ip)
 	case "namecheap.domains.ns.update":
 		ns := r.FormValue("Nameserver")
-		ip := r.FormValue("IP")
-		if st.personalNS == nil {
-			st.personalNS = map[string]string{}
+		current, ok := st.personalNS[ns]
+		if !ok {
+			resp = apiErrorXML("5013160", "Nameserver not found")
+			break
 		}
-		st.personalNS[ns] = ip
+		// An OldIP that is not the nameserver's current glue is refused, so a
+		// provider sending a stale one from state fails here instead of passing.
+		if current != r.FormValue("OldIP") {
+			resp = apiErrorXML("2011280", "mock: OldIP does not match the nameserver's current IP")
+			break
+		}
+		st.personalNS[ns] = r.FormValue("IP")
 		resp = renderResultXML("DomainNSUpdateResult", domain, fmt.Sprintf(`Nameserver="%s" IsSuccess="true"`, ns))
 	case "namecheap.domains.ns.delete":
 		ns := r.FormValue("Nameserver")
+		// Likewise, the glue of a nameserver the domain still delegates to
+		// cannot be deleted out from under the delegation.
+		if containsFold(st.nameservers, ns) {
+			resp = apiErrorXML("2011146", "mock: nameserver "+ns+" is still in use by "+domain)
+			break
+		}
 		delete(st.personalNS, ns)
 		resp = renderResultXML("DomainNSDeleteResult", domain, fmt.Sprintf(`Nameserver="%s" IsSuccess="true"`, ns))
 	case "namecheap.domains.getContacts":
@@ -434,6 +485,29 @@ func (m *namecheapMock) handler(w http.ResponseWriter, r *http.Request) {
 	_, _ = io.WriteString(w, resp)
 }
 
+// missingNameserverGlue returns the first of nameservers that lives under
+// domain but has no personal nameserver registered, or "".
+func missingNameserverGlue(domain string, nameservers []string, st *mockDomainState) string {
+	for _, ns := range nameservers {
+		if !strings.HasSuffix(strings.ToLower(ns), "."+strings.ToLower(domain)) {
+			continue
+		}
+		if _, ok := st.personalNS[strings.ToLower(ns)]; !ok {
+			return ns
+		}
+	}
+	return ""
+}
+
+func containsFold(values []string, want string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, want) {
+			return true
+		}
+	}
+	return false
+}
+
 // parseSetHostsRequest extracts the 1-indexed HostNameN/RecordTypeN/AddressN/
 // MXPrefN/TTLN parameters the SDK sends for SetHosts into hostEntry values.
 func parseSetHostsRequest(r *http.Request) []hostEntry {
@@ -576,12 +650,13 @@ func renderNSInfoXML(domain, nameserver, ip string) string {
 // requested page. It honors pageSizeCap (when set) to force multi-page results
 // from a small seed, and reports TotalItems/CurrentPage/PageSize so the provider
 // can paginate to completion.
-func (m *namecheapMock) renderGetPortfolioXML(page, pageSize int) string {
+func (m *namecheapMock) renderGetPortfolioXML(page, pageSize int, sortBy string) string {
 	eff := pageSize
 	if m.pageSizeCap > 0 && m.pageSizeCap < eff {
 		eff = m.pageSizeCap
 	}
-	total := len(m.portfolio)
+	portfolio := sortMockPortfolio(m.portfolio, sortBy)
+	total := len(portfolio)
 	start := (page - 1) * eff
 	if start > total {
 		start = total
@@ -592,7 +667,7 @@ func (m *namecheapMock) renderGetPortfolioXML(page, pageSize int) string {
 	}
 
 	var lines []string
-	for _, d := range m.portfolio[start:end] {
+	for _, d := range portfolio[start:end] {
 		lines = append(lines, fmt.Sprintf(
 			`<Domain ID="%s" Name="%s" User="%s" Created="%s" Expires="%s" IsExpired="%t" IsLocked="%t" AutoRenew="%t" WhoisGuard="%s" IsPremium="%t" IsOurDNS="%t" />`,
 			d.ID, mockXMLAttrEscaper.Replace(d.Name), d.User, d.Created, d.Expires,
@@ -615,6 +690,35 @@ func (m *namecheapMock) renderGetPortfolioXML(page, pageSize int) string {
 </ApiResponse>`, strings.Join(lines, "\n      "), total, page, eff)
 }
 
+// sortMockPortfolio returns the portfolio in getList SortBy order (the seeded
+// order when sortBy is empty), leaving the seeded slice untouched.
+func sortMockPortfolio(portfolio []mockPortfolioDomain, sortBy string) []mockPortfolioDomain {
+	sorted := slices.Clone(portfolio)
+	if sortBy == "" {
+		return sorted
+	}
+	date := func(s string) time.Time {
+		t, _ := time.Parse("01/02/2006", s)
+		return t
+	}
+	key := func(d mockPortfolioDomain) string {
+		switch strings.TrimSuffix(sortBy, "_DESC") {
+		case "EXPIREDATE":
+			return date(d.Expires).Format(time.RFC3339)
+		case "CREATEDATE":
+			return date(d.Created).Format(time.RFC3339)
+		}
+		return d.Name
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if strings.HasSuffix(sortBy, "_DESC") {
+			return key(sorted[i]) > key(sorted[j])
+		}
+		return key(sorted[i]) < key(sorted[j])
+	})
+	return sorted
+}
+
 // renderGetInfoXML renders a namecheap.domains.getInfo response for the given
 // domain, or a "Domain not found" API error when the domain was not seeded.
 func (m *namecheapMock) renderGetInfoXML(domain string) string {
@@ -786,8 +890,8 @@ func renderGetContactsXML(domain string, st *mockDomainState) string {
 
 // parseSetEmailForwardingRequest extracts the 1-indexed mailboxN/ForwardToN
 // parameters the SDK's SetEmailForwardingWithContext sends.
-func parseSetEmailForwardingRequest(r *http.Request) map[string]string {
-	forwards := map[string]string{}
+func parseSetEmailForwardingRequest(r *http.Request) map[string][]string {
+	forwards := map[string][]string{}
 	for i := 1; ; i++ {
 		idx := strconv.Itoa(i)
 		mailbox := r.FormValue("mailbox" + idx)
@@ -795,7 +899,7 @@ func parseSetEmailForwardingRequest(r *http.Request) map[string]string {
 		if mailbox == "" && forwardTo == "" {
 			break
 		}
-		forwards[mailbox] = forwardTo
+		forwards[mailbox] = append(forwards[mailbox], forwardTo)
 	}
 	return forwards
 }
@@ -813,10 +917,12 @@ func renderGetEmailForwardingXML(domain string, st *mockDomainState) string {
 
 	var lines []string
 	for _, mailbox := range mailboxes {
-		lines = append(lines, fmt.Sprintf(
-			`<Forward mailbox="%s" ForwardTo="%s" />`,
-			mockXMLAttrEscaper.Replace(mailbox), mockXMLAttrEscaper.Replace(st.forwards[mailbox]),
-		))
+		for _, forwardTo := range st.forwards[mailbox] {
+			lines = append(lines, fmt.Sprintf(
+				`<Forward mailbox="%s" ForwardTo="%s" />`,
+				mockXMLAttrEscaper.Replace(mailbox), mockXMLAttrEscaper.Replace(forwardTo),
+			))
+		}
 	}
 
 	return fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
@@ -0,0 +1,179 @@
+package namecheap_provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+)
+
+// resourceNamecheapAddFundsRequest creates a credit-card add-funds request
+// (namecheap.users.createaddfundsrequest) and exports the page the payment is
+// made on. Creating it charges nothing: the funds are added only once someone
+// completes the payment at redirect_url, outside Terraform. Refresh follows the
+// request's status, which is how a configuration waits on the payment.
+//
+// The create call is not idempotent and the SDK never retries it, so a failed
+// create may still have opened a request on Namecheap's side. That is harmless:
+// an unpaid request expires.
+func resourceNamecheapAddFundsRequest() *schema.Resource {
+	return &schema.Resource{
+		Description: "Creates a Namecheap add-funds request and exports the URL the credit-card payment is completed at. Nothing is charged until the payment is completed there.",
+
+		CreateContext: resourceAddFundsRequestCreate,
+		ReadContext:   resourceAddFundsRequestRead,
+		DeleteContext: resourceAddFundsRequestDelete,
+
+		Timeouts: resourceTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"amount": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "The amount to add to the account balance, as a decimal in the account's currency (e.g. `\"25.00\"`). Changing this creates a new request.",
+				ValidateFunc: validateAddFundsAmount,
+			},
+			"return_url": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "The URL the payer is sent back to once the payment is made. Changing this creates a new request.",
+				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			},
+			"token_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The token Namecheap identifies the request by. It is also the resource ID.",
+			},
+			"redirect_url": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The URL the credit-card payment is completed at. Open it in a browser to add the funds.",
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The status of the request as of the last refresh: `CREATED`, `SUBMITTED`, `COMPLETED`, `FAILED` or `EXPIRED`.",
+			},
+			"transaction_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The ID of the payment transaction, once Namecheap reports one.",
+			},
+		},
+	}
+}
+
+// validateAddFundsAmount accepts a positive decimal amount.
+func validateAddFundsAmount(val interface{}, key string) (warns []string, errs []error) {
+	value := val.(string)
+	amount, err := parseAmount(namecheap.Amount(strings.TrimSpace(value)))
+	if err != nil || amount.Sign() <= 0 {
+		errs = append(errs, fmt.Errorf("%q must be a positive decimal amount (e.g. \"25.00\"), got %q", key, value))
+	}
+	return
+}
+
+func resourceAddFundsRequestCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+
+	resp, err := client.Users.CreateAddFundsRequestWithContext(ctx, &namecheap.UsersCreateAddFundsRequestArgs{
+		PaymentType: namecheap.PaymentTypeCreditcard,
+		Amount:      namecheap.Amount(strings.TrimSpace(data.Get("amount").(string))),
+		ReturnURL:   data.Get("return_url").(string),
+	})
+	if err != nil {
+		return diagFromClientError(err)
+	}
+	if resp == nil || resp.CreateAddFundsRequestResult == nil || derefString(resp.CreateAddFundsRequestResult.TokenID) == "" {
+		return diag.Errorf("Namecheap returned no token for the add-funds request. Check the account's add-funds history before applying again: the request may have been created")
+	}
+	result := resp.CreateAddFundsRequestResult
+
+	data.SetId(*result.TokenID)
+	_ = data.Set("token_id", *result.TokenID)
+	_ = data.Set("redirect_url", derefString(result.RedirectURL))
+
+	return resourceAddFundsRequestRead(ctx, data, meta)
+}
+
+func resourceAddFundsRequestRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+
+	resp, err := client.Users.GetAddFundsStatusWithContext(ctx, data.Id())
+	if err != nil {
+		// Only the response saying the token is unknown or expired drops the
+		// resource, so the next apply opens a new request; any other error may
+		// be passing and fails the refresh instead. A request already paid stays:
+		// recreating it would ask for the same funds again.
+		if isAddFundsRequestGoneError(err) {
+			if data.Get("status").(string) == string(namecheap.AddFundsStatusCompleted) {
+				log.Printf("[INFO] add-funds request %s is no longer known to Namecheap (%s); it was completed, so it stays in state", data.Id(), err)
+				return nil
+			}
+			log.Printf("[WARN] add-funds request %s is no longer known to Namecheap (%s), removing it from state", data.Id(), err)
+			data.SetId("")
+			return nil
+		}
+		return diagFromClientError(err)
+	}
+	if resp == nil || resp.GetAddFundsStatusResult == nil {
+		return diag.Errorf("Namecheap returned no status for add-funds request %s", data.Id())
+	}
+	result := resp.GetAddFundsStatusResult
+
+	transactionID := ""
+	if result.TransactionID != nil {
+		transactionID = strconv.Itoa(*result.TransactionID)
+	}
+
+	_ = data.Set("token_id", data.Id())
+	_ = data.Set("status", string(result.Status))
+	_ = data.Set("transaction_id", transactionID)
+
+	return nil
+}
+
+// addFundsTokenGoneErrors are the getAddFundsStatus error numbers that say
+// the token is unknown or has expired.
+var addFundsTokenGoneErrors = map[int]bool{
+	2011280: true, // TokenId is invalid
+}
+
+// addFundsTokenGoneMessage matches the text of an error about the token
+// itself, for the numbers addFundsTokenGoneErrors does not list: the API
+// documents no error codes for getAddFundsStatus.
+var addFundsTokenGoneMessage = regexp.MustCompile(`(?i)token\s*id\b.*\b(invalid|expired|not found|does not exist)`)
+
+// isAddFundsRequestGoneError reports whether err, from getAddFundsStatus, says
+// the request's token is unknown or expired. Every other error, the server
+// and rate-limit errors among them, is not about the request.
+func isAddFundsRequestGoneError(err error) bool {
+	var apiErr *namecheap.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return addFundsTokenGoneErrors[apiErr.Number] || addFundsTokenGoneMessage.MatchString(apiErr.Message)
+}
+
+func resourceAddFundsRequestDelete(_ context.Context, data *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	data.SetId("")
+
+	return diag.Diagnostics{
+		{
+			Severity: diag.Warning,
+			Summary:  "Add-funds requests cannot be deleted",
+			Detail: "The Namecheap API has no operation to cancel an add-funds request. Removing this resource only removes it from " +
+				"the Terraform state: funds already added stay in the account, and an unpaid request expires on its own.",
+		},
+	}
+}
@@ -0,0 +1,158 @@
+package namecheap_provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const xmlCreateAddFundsRequest = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse Type="namecheap.users.createaddfundsrequest">
+    <CreateAddFundsRequestResult TokenID="tok-123" RedirectURL="https://www.namecheap.com/pay/tok-123" ReturnURL="https://example.com/back" />
+  </CommandResponse>
+</ApiResponse>`
+
+const xmlGetAddFundsStatus = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse Type="namecheap.users.getAddFundsStatus">
+    <GetAddFundsStatusResult TransactionID="987" Amount="25.00" Status="COMPLETED" />
+  </CommandResponse>
+</ApiResponse>`
+
+func TestResourceAddFundsRequestCreate(t *testing.T) {
+	var createCalls int
+	client := startDataSourceServer(t, func(command string, r *http.Request) string {
+		switch command {
+		case "namecheap.users.createaddfundsrequest":
+			createCalls++
+			assert.Equal(t, "Creditcard", r.FormValue("PaymentType"))
+			assert.Equal(t, "25.00", r.FormValue("Amount"))
+			assert.Equal(t, "https://example.com/back", r.FormValue("ReturnUrl"))
+			return xmlCreateAddFundsRequest
+		case "namecheap.users.getAddFundsStatus":
+			assert.Equal(t, "tok-123", r.FormValue("TokenId"))
+			return xmlGetAddFundsStatus
+		}
+		return apiErrorXML("1010101", "unexpected command "+command)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceNamecheapAddFundsRequest().Schema, map[string]interface{}{
+		"amount":     "25.00",
+		"return_url": "https://example.com/back",
+	})
+	diags := resourceAddFundsRequestCreate(context.Background(), d, testMeta(client))
+	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
+	assert.Equal(t, 1, createCalls)
+	assert.Equal(t, "tok-123", d.Id())
+	assert.Equal(t, "tok-123", d.Get("token_id"))
+	assert.Equal(t, "https://www.namecheap.com/pay/tok-123", d.Get("redirect_url"))
+	assert.Equal(t, "COMPLETED", d.Get("status"))
+	assert.Equal(t, "987", d.Get("transaction_id"))
+}
+
+func TestResourceAddFundsRequestCreate_APIError(t *testing.T) {
+	client := startDataSourceServer(t, func(command string, _ *http.Request) string {
+		return apiErrorXML("2011170", "Amount is invalid")
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceNamecheapAddFundsRequest().Schema, map[string]interface{}{
+		"amount":     "25.00",
+		"return_url": "https://example.com/back",
+	})
+	diags := resourceAddFundsRequestCreate(context.Background(), d, testMeta(client))
+	require.True(t, diags.HasError())
+	assert.Empty(t, d.Id())
+}
+
+func TestResourceAddFundsRequestRead_UnknownToken(t *testing.T) {
+	client := startDataSourceServer(t, func(command string, _ *http.Request) string {
+		return apiErrorXML("2011280", "TokenId is invalid")
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceNamecheapAddFundsRequest().Schema, map[string]interface{}{})
+	d.SetId("tok-expired")
+	diags := resourceAddFundsRequestRead(context.Background(), d, testMeta(client))
+	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
+	assert.Empty(t, d.Id(), "an unknown token is removed from state")
+}
+
+func TestResourceAddFundsRequestRead_ExpiredTokenMessage(t *testing.T) {
+	client := startDataSourceServer(t, func(command string, _ *http.Request) string {
+		return apiErrorXML("2011281", "TokenID has expired")
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceNamecheapAddFundsRequest().Schema, map[string]interface{}{})
+	d.SetId("tok-expired")
+	diags := resourceAddFundsRequestRead(context.Background(), d, testMeta(client))
+	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
+	assert.Empty(t, d.Id(), "an expired token is removed from state")
+}
+
+// A completed request whose token Namecheap has since forgotten stays in
+// state: dropping it would have the next apply ask for the funds again.
+func TestResourceAddFundsRequestRead_UnknownTokenCompleted(t *testing.T) {
+	client := startDataSourceServer(t, func(command string, _ *http.Request) string {
+		return apiErrorXML("2011280", "TokenId is invalid")
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceNamecheapAddFundsRequest().Schema, map[string]interface{}{})
+	d.SetId("tok-123")
+	require.NoError(t, d.Set("status", "COMPLETED"))
+	diags := resourceAddFundsRequestRead(context.Background(), d, testMeta(client))
+	require.False(t, diags.HasError(), "unexpected diagnostics: %+v", diags)
+	assert.Equal(t, "tok-123", d.Id())
+	assert.Equal(t, "COMPLETED", d.Get("status"))
+}
+
+func TestResourceAddFundsRequestRead_GenericAPIError(t *testing.T) {
+	client := startDataSourceServer(t, func(command string, _ *http.Request) string {
+		return apiErrorXML("5050900", "Unknown exception")
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceNamecheapAddFundsRequest().Schema, map[string]interface{}{})
+	d.SetId("tok-123")
+	diags := resourceAddFundsRequestRead(context.Background(), d, testMeta(client))
+	require.True(t, diags.HasError())
+	assert.Equal(t, "tok-123", d.Id(), "an error that is not about the token keeps the request")
+}
+
+func TestResourceAddFundsRequestRead_CredentialError(t *testing.T) {
+	client := startDataSourceServer(t, func(command string, _ *http.Request) string {
+		return apiErrorXML("1011102", "API Key is invalid or API access has not been enabled")
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceNamecheapAddFundsRequest().Schema, map[string]interface{}{})
+	d.SetId("tok-123")
+	diags := resourceAddFundsRequestRead(context.Background(), d, testMeta(client))
+	require.True(t, diags.HasError())
+	assert.Equal(t, "tok-123", d.Id(), "a rejected caller says nothing about the token")
+}
+
+func TestResourceAddFundsRequestDelete(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceNamecheapAddFundsRequest().Schema, map[string]interface{}{})
+	d.SetId("tok-123")
+
+	diags := resourceAddFundsRequestDelete(context.Background(), d, nil)
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Empty(t, d.Id())
+}
+
+func TestValidateAddFundsAmount(t *testing.T) {
+	for _, value := range []string{"25", "25.00", "0.01"} {
+		_, errs := validateAddFundsAmount(value, "amount")
+		assert.Empty(t, errs, value)
+	}
+	for _, value := range []string{"", "0", "-5.00", "ten"} {
+		_, errs := validateAddFundsAmount(value, "amount")
+		assert.Len(t, errs, 1, value)
+	}
+}
@@ -0,0 +1,240 @@
+package namecheap_provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+)
+
+// addressDefaultStateProvinceChoice is the StateProvinceChoice sent when the
+// configuration does not set one: "S", marking state_province as a state.
+const addressDefaultStateProvinceChoice = "S"
+
+// resourceNamecheapAddress manages one entry of the account's address book via
+// the namecheap.users.address.* API family. An entry holds the same twelve
+// fields as a domain contact block, so a namecheap_domain_contacts block can
+// name it with address_id instead of repeating them per domain.
+//
+// The address book is account-wide and entries are keyed by a numeric id the
+// API assigns, which is the resource ID. Marking an entry as the default goes
+// through address.setDefault; an entry loses the flag when another one takes it,
+// which surfaces as drift on default.
+func resourceNamecheapAddress() *schema.Resource {
+	s := contactBlockSchema()
+	s["name"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Required:     true,
+		ValidateFunc: validation.StringIsNotEmpty,
+		Description:  "The label the entry is listed under in the address book, and which namecheap_addresses filters on.",
+	}
+	s["default"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Whether this entry is the account's default address. Setting it to true takes the flag from whichever entry held it. Defaults to false.",
+	}
+	s["state_province_choice"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      addressDefaultStateProvinceChoice,
+		ValidateFunc: validation.StringIsNotEmpty,
+		Description:  "How Namecheap labels state_province: `S` for a state, `P` for a province. Defaults to `S`.",
+	}
+	s["phone_ext"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "The phone extension. Not carried over into domain contacts, which have no such field.",
+	}
+	s["fax"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "The fax number, in the same +NNN.NNNNNNNNNN format as phone. Not carried over into domain contacts, which have no such field.",
+	}
+
+	return &schema.Resource{
+		Description:   "Manages an entry of the account's address book: a reusable contact profile that namecheap_domain_contacts blocks can reference by address_id.",
+		CreateContext: resourceAddressCreate,
+		ReadContext:   resourceAddressRead,
+		UpdateContext: resourceAddressUpdate,
+		DeleteContext: resourceAddressDelete,
+
+		Timeouts: resourceTimeouts(),
+
+		ValidateRawResourceConfigFuncs: []schema.ValidateRawResourceConfigFunc{validateContactRawConfig},
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceAddressImport,
+		},
+
+		Schema: s,
+	}
+}
+
+// addressDetailsFromData builds the SDK address from the configuration. The
+// default flag is left unset: it is applied through setDefault, never as a side
+// effect of a create or update.
+func addressDetailsFromData(data *schema.ResourceData) *namecheap.UsersAddressDetails {
+	contact, _ := expandContactBlock([]interface{}{contactFieldsFromData(data)}, nil)
+	details := contact.ToAddressDetails(data.Get("name").(string))
+	details.StateProvinceChoice = data.Get("state_province_choice").(string)
+	details.PhoneExt = data.Get("phone_ext").(string)
+	details.Fax = data.Get("fax").(string)
+	return &details
+}
+
+// contactFieldsFromData collects the contact-block attributes of an address
+// into the map shape expandContactBlock reads.
+func contactFieldsFromData(data *schema.ResourceData) map[string]interface{} {
+	m := make(map[string]interface{}, len(contactSchemaFields))
+	for _, f := range contactSchemaFields {
+		m[f.attr] = data.Get(f.attr)
+	}
+	return m
+}
+
+// addressIDFromData parses the numeric address id the resource ID holds.
+func addressIDFromData(data *schema.ResourceData) (int, error) {
+	id, err := strconv.Atoi(data.Id())
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid address id %q: expected a positive integer", data.Id())
+	}
+	return id, nil
+}
+
+// addressExists reports whether id is still in the address book. The API
+// documents no error code for an unknown address id, so a failed getInfo is
+// told apart from a deleted entry by listing the book.
+func addressExists(ctx context.Context, client *namecheap.Client, id int) (bool, error) {
+	entries, err := client.UsersAddress.ListAllSlice(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if entry != nil && entry.AddressID != nil && *entry.AddressID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func resourceAddressCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+
+	resp, err := client.UsersAddress.CreateWithContext(ctx, addressDetailsFromData(data))
+	if err != nil {
+		return diagFromClientError(err)
+	}
+	if resp == nil || resp.AddressCreateResult == nil || resp.AddressCreateResult.AddressID == nil {
+		return diag.Errorf("Namecheap did not return the id of the new address %q", data.Get("name").(string))
+	}
+	id := *resp.AddressCreateResult.AddressID
+	data.SetId(strconv.Itoa(id))
+
+	if data.Get("default").(bool) {
+		if _, err := client.UsersAddress.SetDefaultWithContext(ctx, id); err != nil {
+			return diagFromClientError(err)
+		}
+	}
+
+	return resourceAddressRead(ctx, data, meta)
+}
+
+func resourceAddressRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+
+	id, err := addressIDFromData(data)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	resp, err := client.UsersAddress.GetInfoWithContext(ctx, id)
+	if err != nil {
+		var apiErr *namecheap.APIError
+		if errors.As(err, &apiErr) {
+			if exists, listErr := addressExists(ctx, client, id); listErr == nil && !exists {
+				data.SetId("")
+				return nil
+			}
+		}
+		return diagFromClientError(err)
+	}
+	if resp == nil || resp.GetAddressInfoResult == nil {
+		data.SetId("")
+		return nil
+	}
+
+	result := resp.GetAddressInfoResult
+	contact := result.ToContactInfo()
+	for attr, value := range flattenContactInfo(&contact)[0].(map[string]interface{}) {
+		_ = data.Set(attr, value)
+	}
+	_ = data.Set("name", derefString(result.AddressName))
+	_ = data.Set("state_province_choice", derefString(result.StateProvinceChoice))
+	_ = data.Set("phone_ext", derefString(result.PhoneExt))
+	_ = data.Set("fax", derefString(result.Fax))
+	_ = data.Set("default", result.DefaultYN != nil && *result.DefaultYN)
+
+	return nil
+}
+
+func resourceAddressUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+
+	id, err := addressIDFromData(data)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	details := addressDetailsFromData(data)
+	// Giving up the flag has no command of its own; address.update's DefaultYN
+	// is the only way to clear it.
+	if data.HasChange("default") && !data.Get("default").(bool) {
+		details.DefaultYN = namecheap.Bool(false)
+	}
+	if _, err := client.UsersAddress.UpdateWithContext(ctx, id, details); err != nil {
+		return diagFromClientError(err)
+	}
+
+	if data.HasChange("default") && data.Get("default").(bool) {
+		if _, err := client.UsersAddress.SetDefaultWithContext(ctx, id); err != nil {
+			return diagFromClientError(err)
+		}
+	}
+
+	return resourceAddressRead(ctx, data, meta)
+}
+
+func resourceAddressDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+
+	id, err := addressIDFromData(data)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if _, err := client.UsersAddress.DeleteWithContext(ctx, id); err != nil {
+		// Deleting an entry already removed out-of-band keeps destroy
+		// idempotent, as for the other resources.
+		if exists, listErr := addressExists(ctx, client, id); listErr == nil && !exists {
+			return nil
+		}
+		return diagFromClientError(err)
+	}
+
+	return nil
+}
+
+// resourceAddressImport accepts the numeric address id, as listed by the
+// namecheap_addresses data source.
+func resourceAddressImport(_ context.Context, data *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	if _, err := addressIDFromData(data); err != nil {
+		return nil, fmt.Errorf("invalid import ID %q: expected the numeric address id (e.g. \"12345\"); list ids with the namecheap_addresses data source", data.Id())
+	}
+	return []*schema.ResourceData{data}, nil
+}
@@ -0,0 +1,226 @@
+package namecheap_provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addressTestServer routes on the request Command, recording every request's
+// form so a test can assert what was sent.
+func addressTestServer(t *testing.T, respond func(command string, form url.Values) string) (string, *[]url.Values) {
+	t.Helper()
+	var requests []url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		requests = append(requests, r.Form)
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = io.WriteString(w, respond(r.FormValue("Command"), r.Form))
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, &requests
+}
+
+func xmlAddressResult(element string, id int) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse>
+    <%s Success="true" AddressID="%d" />
+  </CommandResponse>
+</ApiResponse>`, element, id)
+}
+
+func xmlAddressGetList(entries map[int]string) string {
+	list := ""
+	for id, name := range entries {
+		list += fmt.Sprintf(`<List AddressId="%d" AddressName="%s" />`, id, name)
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse>
+    <AddressGetListResult>%s</AddressGetListResult>
+  </CommandResponse>
+</ApiResponse>`, list)
+}
+
+// addressRaw is a complete namecheap_address configuration.
+func addressRaw() map[string]interface{} {
+	raw := registrantRaw()["registrant"].([]interface{})[0].(map[string]interface{})
+	raw["name"] = "Corporate"
+	raw["state_province_choice"] = "P"
+	return raw
+}
+
+func commandsOf(requests []url.Values) []string {
+	commands := make([]string, 0, len(requests))
+	for _, r := range requests {
+		commands = append(commands, r.Get("Command"))
+	}
+	return commands
+}
+
+func TestResourceAddressCreate(t *testing.T) {
+	url, requests := addressTestServer(t, func(command string, _ url.Values) string {
+		switch command {
+		case "namecheap.users.address.create":
+			return xmlAddressResult("AddressCreateResult", 42)
+		case "namecheap.users.address.setDefault":
+			return xmlAddressResult("AddressSetDefaultResult", 42)
+		case "namecheap.users.address.getInfo":
+			return xmlAddressGetInfo(42, "Lisbon")
+		}
+		return apiErrorXML("1010101", "unexpected "+command)
+	})
+
+	raw := addressRaw()
+	raw["default"] = true
+	d := schema.TestResourceDataRaw(t, resourceNamecheapAddress().Schema, raw)
+	diags := resourceAddressCreate(context.Background(), d, testMeta(newTestClient(url)))
+	require.False(t, diags.HasError(), "unexpected diags: %+v", diags)
+
+	assert.Equal(t, "42", d.Id())
+	assert.Equal(t, []string{
+		"namecheap.users.address.create",
+		"namecheap.users.address.setDefault",
+		"namecheap.users.address.getInfo",
+	}, commandsOf(*requests))
+
+	create := (*requests)[0]
+	assert.Equal(t, "Corporate", create.Get("AddressName"))
+	assert.Equal(t, "1000-001", create.Get("Zip"))
+	assert.Equal(t, "Example Corp", create.Get("Organization"))
+	assert.Equal(t, "P", create.Get("StateProvinceChoice"))
+	assert.Empty(t, create.Get("DefaultYN"), "the default flag goes through setDefault")
+}
+
+func TestResourceAddressRead_Gone(t *testing.T) {
+	url, _ := addressTestServer(t, func(command string, _ url.Values) string {
+		switch command {
+		case "namecheap.users.address.getInfo":
+			return apiErrorXML("2011170", "Address not found")
+		case "namecheap.users.address.getList":
+			return xmlAddressGetList(map[int]string{7: "Other"})
+		}
+		return apiErrorXML("1010101", "unexpected "+command)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceNamecheapAddress().Schema, addressRaw())
+	d.SetId("42")
+	diags := resourceAddressRead(context.Background(), d, testMeta(newTestClient(url)))
+
+	require.False(t, diags.HasError(), "unexpected diags: %+v", diags)
+	assert.Empty(t, d.Id(), "an entry missing from the address book is dropped from state")
+}
+
+func TestResourceAddressRead_ErrorOnListedEntry(t *testing.T) {
+	url, _ := addressTestServer(t, func(command string, _ url.Values) string {
+		switch command {
+		case "namecheap.users.address.getInfo":
+			return apiErrorXML("5050900", "Unknown error")
+		case "namecheap.users.address.getList":
+			return xmlAddressGetList(map[int]string{42: "Corporate"})
+		}
+		return apiErrorXML("1010101", "unexpected "+command)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceNamecheapAddress().Schema, addressRaw())
+	d.SetId("42")
+	diags := resourceAddressRead(context.Background(), d, testMeta(newTestClient(url)))
+
+	assert.True(t, diags.HasError())
+	assert.Equal(t, "42", d.Id())
+}
+
+func TestResourceAddressUpdate_ClearsDefault(t *testing.T) {
+	url, requests := addressTestServer(t, func(command string, _ url.Values) string {
+		switch command {
+		case "namecheap.users.address.update":
+			return xmlAddressResult("AddressUpdateResult", 42)
+		case "namecheap.users.address.getInfo":
+			return xmlAddressGetInfo(42, "Lisbon")
+		}
+		return apiErrorXML("1010101", "unexpected "+command)
+	})
+
+	resource := resourceNamecheapAddress()
+	state := addressRaw()
+	state["default"] = true
+	d := schema.TestResourceDataRaw(t, resource.Schema, state)
+	d.SetId("42")
+	d.MarkNewResource()
+	_ = d.Set("default", false)
+
+	diags := resourceAddressUpdate(context.Background(), d, testMeta(newTestClient(url)))
+	require.False(t, diags.HasError(), "unexpected diags: %+v", diags)
+	require.NotEmpty(t, *requests)
+
+	update := (*requests)[0]
+	assert.Equal(t, "namecheap.users.address.update", update.Get("Command"))
+	assert.Equal(t, "42", update.Get("AddressId"))
+	assert.Equal(t, "0", update.Get("DefaultYN"))
+	assert.NotContains(t, commandsOf(*requests), "namecheap.users.address.setDefault")
+}
+
+func TestResourceAddressDelete_AlreadyGone(t *testing.T) {
+	url, _ := addressTestServer(t, func(command string, _ url.Values) string {
+		switch command {
+		case "namecheap.users.address.delete":
+			return apiErrorXML("2011170", "Address not found")
+		case "namecheap.users.address.getList":
+			return xmlAddressGetList(nil)
+		}
+		return apiErrorXML("1010101", "unexpected "+command)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceNamecheapAddress().Schema, addressRaw())
+	d.SetId("42")
+	assert.False(t, resourceAddressDelete(context.Background(), d, testMeta(newTestClient(url))).HasError())
+}
+
+func TestResourceAddressImport(t *testing.T) {
+	resource := resourceNamecheapAddress()
+
+	d := resource.TestResourceData()
+	d.SetId("42")
+	_, err := resourceAddressImport(context.Background(), d, nil)
+	assert.NoError(t, err)
+
+	for _, id := range []string{"", "Corporate", "0", "-3"} {
+		d := resource.TestResourceData()
+		d.SetId(id)
+		_, err := resourceAddressImport(context.Background(), d, nil)
+		assert.Errorf(t, err, "import ID %q", id)
+	}
+}
+
+func TestAddressResourceSchemaValid(t *testing.T) {
+	assert.NoError(t, resourceNamecheapAddress().InternalValidate(nil, true))
+}
+
+func TestDataSourceAddressesRead(t *testing.T) {
+	url, _ := addressTestServer(t, func(command string, _ url.Values) string {
+		if command == "namecheap.users.address.getList" {
+			return xmlAddressGetList(map[int]string{42: "Corporate"})
+		}
+		return apiErrorXML("1010101", "unexpected "+command)
+	})
+	client := newTestClient(url)
+
+	d := schema.TestResourceDataRaw(t, dataSourceNamecheapAddresses().Schema, map[string]interface{}{"name": "corporate"})
+	require.False(t, dataSourceNamecheapAddressesRead(context.Background(), d, testMeta(client)).HasError())
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": 42, "name": "Corporate"}}, d.Get("addresses"))
+
+	d = schema.TestResourceDataRaw(t, dataSourceNamecheapAddresses().Schema, map[string]interface{}{"name": "Billing"})
+	require.False(t, dataSourceNamecheapAddressesRead(context.Background(), d, testMeta(client)).HasError())
+	assert.Empty(t, d.Get("addresses"))
+}
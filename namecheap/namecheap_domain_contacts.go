@@ -3,8 +3,10 @@ package namecheap_provider
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
@@ -42,6 +44,21 @@ func isDomainGoneError(err error) bool {
 //     omitted, default to the Registrant values. That defaulting is applied in
 //     CustomizeDiff so it is visible in the plan rather than happening invisibly
 //     server-side.
+//   - Any block may name an address book entry (namecheap_address) with
+//     address_id instead of setting its fields inline. The entry is resolved at
+//     apply time, and Read keeps the reference for as long as the domain still
+//     matches it, so an edit to the entry plans an update of every domain that
+//     uses it.
+//   - extended_attributes carries the registry-specific attributes some ccTLDs
+//     require with the contacts (.us nexus, .ca legal type, ...). They are
+//     checked at plan time against extendedAttributeTables. getContacts does
+//     not return them, so Read leaves them as configured.
+//   - On a gTLD, a change to the registrant's name, organization or e-mail is
+//     a material change of registrant under ICANN's Transfer Policy: it must be
+//     confirmed by e-mail and locks the domain against transfer for 60 days.
+//     The plan fails on one unless acknowledge_transfer_lock is set (see
+//     checkRegistrantChange). A create counts too: its plan compares the
+//     registrant with the one the domain holds.
 //
 // This resource is mutually exclusive, per domain, with an inline contacts block
 // on the domain resource: manage a domain's contacts in exactly one place.
@@ -53,6 +70,10 @@ func resourceNamecheapDomainContacts() *schema.Resource {
 		UpdateContext: resourceContactsUpdate,
 		DeleteContext: resourceContactsDelete,
 
+		Timeouts: resourceTimeouts(),
+
+		ValidateRawResourceConfigFuncs: []schema.ValidateRawResourceConfigFunc{validateContactBlocksRawConfig("registrant", "tech", "admin", "aux_billing")},
+
 		CustomizeDiff: customizeContactsDiff,
 
 		Importer: &schema.ResourceImporter{
@@ -82,7 +103,7 @@ func resourceNamecheapDomainContacts() *schema.Resource {
 				Required:    true,
 				MaxItems:    1,
 				Description: "Registrant contact. Required.",
-				Elem:        &schema.Resource{Schema: contactBlockSchema()},
+				Elem:        &schema.Resource{Schema: domainContactBlockSchema()},
 			},
 			"tech": {
 				Type:        schema.TypeList,
@@ -90,7 +111,7 @@ func resourceNamecheapDomainContacts() *schema.Resource {
 				Computed:    true,
 				MaxItems:    1,
 				Description: "Tech contact. Optional; defaults to the registrant contact when omitted.",
-				Elem:        &schema.Resource{Schema: contactBlockSchema()},
+				Elem:        &schema.Resource{Schema: domainContactBlockSchema()},
 			},
 			"admin": {
 				Type:        schema.TypeList,
@@ -98,7 +119,7 @@ func resourceNamecheapDomainContacts() *schema.Resource {
 				Computed:    true,
 				MaxItems:    1,
 				Description: "Admin contact. Optional; defaults to the registrant contact when omitted.",
-				Elem:        &schema.Resource{Schema: contactBlockSchema()},
+				Elem:        &schema.Resource{Schema: domainContactBlockSchema()},
 			},
 			"aux_billing": {
 				Type:        schema.TypeList,
@@ -106,7 +127,26 @@ func resourceNamecheapDomainContacts() *schema.Resource {
 				Computed:    true,
 				MaxItems:    1,
 				Description: "AuxBilling contact. Optional; defaults to the registrant contact when omitted.",
-				Elem:        &schema.Resource{Schema: contactBlockSchema()},
+				Elem:        &schema.Resource{Schema: domainContactBlockSchema()},
+			},
+			"acknowledge_transfer_lock": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Allow a change to the registrant's name, organization or e-mail on a gTLD domain. Such a change must be confirmed by e-mail and locks the domain against transfer to another registrar for 60 days; without this set, the plan fails instead. Defaults to false.",
+			},
+			"contacts_read_only": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether Namecheap currently refuses changes to the domain's contacts, as reported by getContacts. It is true while a change of registrant awaits confirmation, among other reasons.",
+			},
+			"extended_attributes": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Description: "Registry-specific attributes sent with the contacts, keyed by their Namecheap name (e.g. CIRALegalType for .ca, " +
+					"RegistrantNexus for .us). Checked at plan time for the TLDs the provider knows the requirements of. " +
+					"Namecheap does not return them, so changes made outside Terraform are not detected.",
 			},
 		},
 	}
@@ -124,38 +164,75 @@ func resourceContactsUpdate(ctx context.Context, data *schema.ResourceData, meta
 // blocks (defaulting the optional ones to the registrant) and issues a single
 // setContacts call.
 func setDomainContacts(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 	domain := strings.ToLower(data.Get("domain").(string))
 
-	registrant := expandContactBlock(data.Get("registrant"))
+	args, diags := contactsArgsFromData(data, domain, newContactAddressBook(ctx, client))
+	if diags != nil {
+		return diags
+	}
+
+	extended := expandExtendedAttributes(data.Get("extended_attributes"))
+	if err := sendDomainContacts(ctx, client, args, extended); err != nil {
+		return diagFromClientError(err)
+	}
+
+	data.SetId(domain)
+
+	return append(registrantChangeWarning(data), resourceContactsRead(ctx, data, meta)...)
+}
+
+// contactsArgsFromData builds the setContacts arguments for domain from the
+// registrant, tech, admin and aux_billing blocks of data, defaulting the
+// omitted ones to the registrant. Blocks naming an address_id are resolved
+// through addresses.
+func contactsArgsFromData(data *schema.ResourceData, domain string, addresses contactAddressBook) (*namecheap.DomainsSetContactsArgs, diag.Diagnostics) {
+	registrant, err := expandContactBlock(data.Get("registrant"), addresses)
+	if err != nil {
+		return nil, contactAddressError("registrant", err)
+	}
 
 	args := &namecheap.DomainsSetContactsArgs{
 		DomainName: domain,
 		Registrant: registrant,
-		Tech:       contactOrDefault(data.Get("tech"), registrant),
-		Admin:      contactOrDefault(data.Get("admin"), registrant),
-		AuxBilling: contactOrDefault(data.Get("aux_billing"), registrant),
 	}
+	for block, target := range map[string]*namecheap.ContactInfo{
+		"tech":        &args.Tech,
+		"admin":       &args.Admin,
+		"aux_billing": &args.AuxBilling,
+	} {
+		if *target, err = contactOrDefault(data.Get(block), registrant, addresses); err != nil {
+			return nil, contactAddressError(block, err)
+		}
+	}
+	return args, nil
+}
 
-	resp, err := client.Domains.SetContactsWithContext(ctx, args)
+// sendDomainContacts issues the setContacts call for args, carrying extended
+// when it is not empty.
+func sendDomainContacts(ctx context.Context, client *namecheap.Client, args *namecheap.DomainsSetContactsArgs, extended map[string]string) error {
+	var resp *namecheap.DomainsSetContactsCommandResponse
+	var err error
+	if len(extended) > 0 {
+		resp, err = setContactsWithExtendedAttributes(ctx, client, args, extended)
+	} else {
+		resp, err = client.Domains.SetContactsWithContext(ctx, args)
+	}
 	if err != nil {
-		return diagFromClientError(err)
+		return err
 	}
 	// Guard against a Status=OK response that nonetheless reports the update did
 	// not take effect, so the provider does not claim success and write state
 	// for contacts the API rejected.
 	if resp != nil && resp.DomainSetContactResult != nil &&
 		resp.DomainSetContactResult.IsSuccess != nil && !*resp.DomainSetContactResult.IsSuccess {
-		return diag.Errorf("Namecheap reported the contact update for %q was not successful (setContacts returned IsSuccess=false)", domain)
+		return fmt.Errorf("Namecheap reported the contact update for %q was not successful (setContacts returned IsSuccess=false)", args.DomainName)
 	}
-
-	data.SetId(domain)
-
-	return resourceContactsRead(ctx, data, meta)
+	return nil
 }
 
 func resourceContactsRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 	domain := strings.ToLower(data.Get("domain").(string))
 
 	resp, err := client.Domains.GetContactsWithContext(ctx, domain)
@@ -180,21 +257,45 @@ func resourceContactsRead(ctx context.Context, data *schema.ResourceData, meta i
 	}
 
 	result := resp.DomainContactsResult
+	_ = data.Set("contacts_read_only", result.ReadOnly != nil && *result.ReadOnly)
+	addresses := newContactAddressBook(ctx, client)
 
-	if err := data.Set("registrant", flattenContactInfo(result.Registrant)); err != nil {
-		return diag.FromErr(err)
-	}
-	if err := data.Set("tech", flattenContactInfo(result.Tech)); err != nil {
-		return diag.FromErr(err)
-	}
-	if err := data.Set("admin", flattenContactInfo(result.Admin)); err != nil {
-		return diag.FromErr(err)
+	var diags diag.Diagnostics
+	for block, live := range map[string]*namecheap.ContactInfo{
+		"registrant":  result.Registrant,
+		"tech":        result.Tech,
+		"admin":       result.Admin,
+		"aux_billing": result.AuxBilling,
+	} {
+		value, blockDiags := contactBlockState(data.Get(block), live, addresses)
+		diags = append(diags, blockDiags...)
+		if err := data.Set(block, value); err != nil {
+			return diag.FromErr(err)
+		}
 	}
-	if err := data.Set("aux_billing", flattenContactInfo(result.AuxBilling)); err != nil {
-		return diag.FromErr(err)
+
+	// The plan only requires the extended attributes when it changes the
+	// contacts, so an imported domain without them is reported here.
+	if err := validateExtendedAttributes(domain, expandExtendedAttributes(data.Get("extended_attributes"))); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Extended attributes missing for " + domain,
+			Detail: err.Error() + "\n\nThe contacts are left as they are, but the next change to them will be refused " +
+				"until extended_attributes sets these.",
+		})
 	}
 
-	return nil
+	return diags
+}
+
+// contactAddressError reports a block whose address_id could not be resolved.
+func contactAddressError(block string, err error) diag.Diagnostics {
+	return diag.Diagnostics{{
+		Severity:      diag.Error,
+		Summary:       "Unable to resolve " + block + " address_id",
+		Detail:        err.Error(),
+		AttributePath: cty.Path{cty.GetAttrStep{Name: block}, cty.IndexStep{Key: cty.NumberIntVal(0)}, cty.GetAttrStep{Name: contactAddressIDAttr}},
+	}}
 }
 
 // resourceContactsDelete removes the resource from state without calling the
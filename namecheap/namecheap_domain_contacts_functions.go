@@ -2,8 +2,13 @@ package namecheap_provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"regexp"
+	"strings"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
@@ -52,7 +57,10 @@ var (
 
 // contactBlockSchema builds the per-block nested schema shared by the
 // registrant/tech/admin/aux_billing blocks from contactSchemaFields, attaching
-// plan-time validators to the fields the API constrains.
+// plan-time validators to the fields the API constrains. Checks that span
+// fields, such as a postal code against the country, cannot be attached to a
+// single attribute; resources using the schema run them with
+// validateContactBlocksRawConfig.
 func contactBlockSchema() map[string]*schema.Schema {
 	m := make(map[string]*schema.Schema, len(contactSchemaFields))
 	for _, f := range contactSchemaFields {
@@ -67,8 +75,7 @@ func contactBlockSchema() map[string]*schema.Schema {
 		}
 		switch f.attr {
 		case "phone":
-			s.ValidateFunc = validation.StringMatch(contactPhoneRegexp,
-				"must be in international format +NNN.NNNNNNNNNN (e.g. +1.6613102107)")
+			s.ValidateFunc = validateContactPhone
 		case "country":
 			s.ValidateFunc = validation.StringMatch(contactCountryRegexp,
 				"must be a two-letter ISO 3166-1 alpha-2 country code (e.g. US, PT)")
@@ -81,12 +88,82 @@ func contactBlockSchema() map[string]*schema.Schema {
 	return m
 }
 
+// contactAddressIDAttr is the contact-block attribute that names an address
+// book entry in place of the inline contact fields.
+const contactAddressIDAttr = "address_id"
+
+// domainContactBlockSchema is contactBlockSchema as the namecheap_domain_contacts
+// blocks use it: with address_id added as the alternative to the inline fields,
+// which makes the otherwise required ones optional. customizeContactsDiff
+// enforces that a block sets exactly one of the two.
+func domainContactBlockSchema() map[string]*schema.Schema {
+	m := contactBlockSchema()
+	for _, f := range contactSchemaFields {
+		if f.required {
+			m[f.attr].Required = false
+			m[f.attr].Optional = true
+			m[f.attr].Description = f.description + " Required unless address_id is set."
+		}
+	}
+	m[contactAddressIDAttr] = &schema.Schema{
+		Type:         schema.TypeInt,
+		Optional:     true,
+		ValidateFunc: validation.IntAtLeast(1),
+		Description:  "The id of a namecheap_address (an address book entry) to take the contact from, instead of setting the fields inline. Conflicts with the inline fields.",
+	}
+	return m
+}
+
+// contactAddressBook resolves an address book id to the contact it holds.
+type contactAddressBook func(addressID int) (namecheap.ContactInfo, error)
+
+// newContactAddressBook returns a contactAddressBook backed by
+// namecheap.users.address.getInfo. Lookups are cached, so the four blocks of one
+// domain sharing an entry cost a single call.
+func newContactAddressBook(ctx context.Context, client *namecheap.Client) contactAddressBook {
+	cache := map[int]namecheap.ContactInfo{}
+	return func(addressID int) (namecheap.ContactInfo, error) {
+		if contact, ok := cache[addressID]; ok {
+			return contact, nil
+		}
+		resp, err := client.UsersAddress.GetInfoWithContext(ctx, addressID)
+		if err != nil {
+			return namecheap.ContactInfo{}, fmt.Errorf("reading address %d from the address book: %w", addressID, err)
+		}
+		if resp == nil || resp.GetAddressInfoResult == nil {
+			return namecheap.ContactInfo{}, fmt.Errorf("address %d is not in the account's address book", addressID)
+		}
+		contact := resp.GetAddressInfoResult.ToContactInfo()
+		cache[addressID] = contact
+		return contact, nil
+	}
+}
+
+// contactAddressID returns the address_id of a schema block value, or 0 when
+// the block sets its fields inline.
+func contactAddressID(raw interface{}) int {
+	list, ok := raw.([]interface{})
+	if !ok || len(list) == 0 || list[0] == nil {
+		return 0
+	}
+	id, _ := list[0].(map[string]interface{})[contactAddressIDAttr].(int)
+	return id
+}
+
 // expandContactBlock converts a schema block value (a one-element TypeList of a
-// map) into a namecheap.ContactInfo. A nil/empty block yields the zero value.
-func expandContactBlock(raw interface{}) namecheap.ContactInfo {
+// map) into a namecheap.ContactInfo. A block naming an address_id is resolved
+// through addresses; any other block is read from its inline fields. A nil/empty
+// block yields the zero value.
+func expandContactBlock(raw interface{}, addresses contactAddressBook) (namecheap.ContactInfo, error) {
 	list, ok := raw.([]interface{})
 	if !ok || len(list) == 0 || list[0] == nil {
-		return namecheap.ContactInfo{}
+		return namecheap.ContactInfo{}, nil
+	}
+	if id := contactAddressID(raw); id > 0 {
+		if addresses == nil {
+			return namecheap.ContactInfo{}, fmt.Errorf("address %d cannot be resolved here", id)
+		}
+		return addresses(id)
 	}
 	m := list[0].(map[string]interface{})
 	getString := func(key string) string {
@@ -108,7 +185,7 @@ func expandContactBlock(raw interface{}) namecheap.ContactInfo {
 		OrganizationName: getString("organization"),
 		JobTitle:         getString("job_title"),
 		Address2:         getString("address2"),
-	}
+	}, nil
 }
 
 // contactIsEmpty reports whether a schema block value carries no contact (an
@@ -120,11 +197,11 @@ func contactIsEmpty(raw interface{}) bool {
 
 // contactOrDefault returns the expanded block, or the registrant fallback when
 // the block is omitted — the default-to-registrant rule.
-func contactOrDefault(raw interface{}, fallback namecheap.ContactInfo) namecheap.ContactInfo {
+func contactOrDefault(raw interface{}, fallback namecheap.ContactInfo, addresses contactAddressBook) (namecheap.ContactInfo, error) {
 	if contactIsEmpty(raw) {
-		return fallback
+		return fallback, nil
 	}
-	return expandContactBlock(raw)
+	return expandContactBlock(raw, addresses)
 }
 
 // flattenContactInfo converts a namecheap.ContactInfo from a getContacts
@@ -150,16 +227,101 @@ func flattenContactInfo(c *namecheap.ContactInfo) []interface{} {
 	}}
 }
 
+// contactBlockState is the state value of a block read back as live. A block
+// that prior state records as an address_id reference keeps the reference, and
+// while the live contact still equals the address book entry it stores nothing
+// else — the same as its configuration, so the plan stays empty. Once the two
+// differ (the domain was edited elsewhere, or the entry was) the live fields are
+// stored alongside, which plans rewriting the domain from the entry.
+func contactBlockState(prior interface{}, live *namecheap.ContactInfo, addresses contactAddressBook) ([]interface{}, diag.Diagnostics) {
+	flattened := flattenContactInfo(live)
+	id := contactAddressID(prior)
+	if id == 0 || len(flattened) == 0 {
+		return flattened, nil
+	}
+
+	var diags diag.Diagnostics
+	resolved, err := addresses(id)
+	if err == nil && resolved == *live {
+		return []interface{}{map[string]interface{}{contactAddressIDAttr: id}}, nil
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Unable to compare contact with address %d", id),
+			Detail:   err.Error() + ". The contact is shown as it is on the domain; applying will fail until address_id names an existing address book entry.",
+		})
+	}
+	flattened[0].(map[string]interface{})[contactAddressIDAttr] = id
+	return flattened, diags
+}
+
+// validateContactBlocks checks that every configured block either names an
+// address_id or sets the required inline fields, but not both. Unknown values
+// count as set, since they may resolve either way.
+func validateContactBlocks(rawConfig cty.Value) error {
+	var errs []error
+	for _, block := range []string{"registrant", "tech", "admin", "aux_billing"} {
+		cfg := rawConfig.GetAttr(block)
+		if !cfg.IsKnown() || cfg.IsNull() || cfg.LengthInt() == 0 {
+			continue
+		}
+		elem := cfg.Index(cty.NumberIntVal(0))
+		if !elem.IsKnown() || elem.IsNull() {
+			continue
+		}
+
+		hasAddressID := !elem.GetAttr(contactAddressIDAttr).IsNull()
+		var inline, missing []string
+		for _, f := range contactSchemaFields {
+			if elem.GetAttr(f.attr).IsNull() {
+				if f.required {
+					missing = append(missing, f.attr)
+				}
+				continue
+			}
+			inline = append(inline, f.attr)
+		}
+
+		switch {
+		case hasAddressID && len(inline) > 0:
+			errs = append(errs, fmt.Errorf("%s: address_id conflicts with the inline contact fields (%s); set one or the other", block, strings.Join(inline, ", ")))
+		case !hasAddressID && len(missing) > 0:
+			errs = append(errs, fmt.Errorf("%s: missing required contact fields %s; set them, or set address_id instead", block, strings.Join(missing, ", ")))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // customizeContactsDiff makes the default-to-registrant behavior explicit in the
 // plan: for each optional block the user did not set, it plans the registrant's
 // values rather than leaving the (Computed) block unknown. Reading intent from
 // the raw config lets it distinguish "user omitted the block" from "user set it
 // equal to the registrant".
-func customizeContactsDiff(_ context.Context, diff *schema.ResourceDiff, _ interface{}) error {
+//
+// Before that it rejects an unacknowledged material change of registrant
+// (checkRegistrantChange) and invalid contact blocks or extended_attributes.
+// The extended attributes are only required when setContacts is going to be
+// called: on create, or when the contacts or the attributes change. A domain
+// already in state without them, from an import or from before they were
+// checked, keeps planning; Read warns about it instead.
+func customizeContactsDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if err := checkRegistrantChange(ctx, diff, meta); err != nil {
+		return err
+	}
+
 	rawConfig := diff.GetRawConfig()
 	if rawConfig.IsNull() {
 		return nil
 	}
+	if err := validateContactBlocks(rawConfig); err != nil {
+		return err
+	}
+	if diff.Id() == "" || diff.HasChanges("registrant", "tech", "admin", "aux_billing", "extended_attributes") {
+		if err := validateExtendedAttributesConfig(rawConfig); err != nil {
+			return err
+		}
+	}
 
 	// The registrant (or one of its fields) may be interpolated from another
 	// resource and not yet known at plan time. When it is unknown, the optional
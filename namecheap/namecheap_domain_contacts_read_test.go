@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -113,7 +114,7 @@ func TestResourceContactsRead_Success(t *testing.T) {
 
 	d := schema.TestResourceDataRaw(t, resourceNamecheapDomainContacts().Schema, map[string]interface{}{"domain": "example.com"})
 	d.SetId("example.com")
-	diags := resourceContactsRead(context.Background(), d, client)
+	diags := resourceContactsRead(context.Background(), d, testMeta(client))
 
 	require.False(t, diags.HasError(), "unexpected diags: %+v", diags)
 	reg := d.Get("registrant").([]interface{})
@@ -131,7 +132,7 @@ func TestResourceContactsRead_NotFound(t *testing.T) {
 
 	d := schema.TestResourceDataRaw(t, resourceNamecheapDomainContacts().Schema, map[string]interface{}{"domain": "example.com"})
 	d.SetId("example.com")
-	diags := resourceContactsRead(context.Background(), d, client)
+	diags := resourceContactsRead(context.Background(), d, testMeta(client))
 
 	require.False(t, diags.HasError(), "a missing domain must not error; got %+v", diags)
 	assert.Empty(t, d.Id(), "a domain absent from the account should be dropped from state")
@@ -148,7 +149,7 @@ func TestResourceContactsRead_DomainGone(t *testing.T) {
 
 			d := schema.TestResourceDataRaw(t, resourceNamecheapDomainContacts().Schema, map[string]interface{}{"domain": "example.com"})
 			d.SetId("example.com")
-			diags := resourceContactsRead(context.Background(), d, client)
+			diags := resourceContactsRead(context.Background(), d, testMeta(client))
 
 			require.False(t, diags.HasError(), "a domain-gone error must not fail the refresh; got %+v", diags)
 			assert.Empty(t, d.Id(), "a removed domain should be dropped from state")
@@ -163,7 +164,7 @@ func TestResourceContactsRead_Error(t *testing.T) {
 
 	d := schema.TestResourceDataRaw(t, resourceNamecheapDomainContacts().Schema, map[string]interface{}{"domain": "example.com"})
 	d.SetId("example.com")
-	diags := resourceContactsRead(context.Background(), d, client)
+	diags := resourceContactsRead(context.Background(), d, testMeta(client))
 
 	assert.True(t, diags.HasError(), "a non-not-found getContacts API error should surface")
 	assert.Equal(t, "example.com", d.Id(), "state must be left intact on a hard error")
@@ -182,7 +183,7 @@ func TestResourceContactsCreate_Success(t *testing.T) {
 	client := newTestClient(url)
 
 	d := schema.TestResourceDataRaw(t, resourceNamecheapDomainContacts().Schema, registrantRaw())
-	diags := resourceContactsCreate(context.Background(), d, client)
+	diags := resourceContactsCreate(context.Background(), d, testMeta(client))
 
 	require.False(t, diags.HasError(), "unexpected diags: %+v", diags)
 	assert.Equal(t, "example.com", d.Id(), "create should set the ID to the domain")
@@ -200,7 +201,7 @@ func TestResourceContactsCreate_Error(t *testing.T) {
 	client := newTestClient(url)
 
 	d := schema.TestResourceDataRaw(t, resourceNamecheapDomainContacts().Schema, registrantRaw())
-	diags := resourceContactsCreate(context.Background(), d, client)
+	diags := resourceContactsCreate(context.Background(), d, testMeta(client))
 
 	assert.True(t, diags.HasError(), "a setContacts API error should surface")
 }
@@ -219,7 +220,7 @@ func TestResourceContactsUpdate_Success(t *testing.T) {
 
 	d := schema.TestResourceDataRaw(t, resourceNamecheapDomainContacts().Schema, registrantRaw())
 	d.SetId("example.com")
-	diags := resourceContactsUpdate(context.Background(), d, client)
+	diags := resourceContactsUpdate(context.Background(), d, testMeta(client))
 
 	require.False(t, diags.HasError(), "unexpected diags: %+v", diags)
 	assert.Equal(t, "example.com", d.Id())
@@ -243,7 +244,7 @@ func TestResourceContactsCreate_IsSuccessFalse(t *testing.T) {
 	client := newTestClient(url)
 
 	d := schema.TestResourceDataRaw(t, resourceNamecheapDomainContacts().Schema, registrantRaw())
-	diags := resourceContactsCreate(context.Background(), d, client)
+	diags := resourceContactsCreate(context.Background(), d, testMeta(client))
 
 	assert.True(t, diags.HasError(), "IsSuccess=false should surface as an error")
 }
@@ -261,3 +262,116 @@ func TestResourceContactsDelete(t *testing.T) {
 	require.Len(t, diags, 1)
 	assert.Equal(t, diag.Warning, diags[0].Severity)
 }
+
+// xmlAddressGetInfo renders a users.address.getInfo response holding the same
+// contact xmlGetContacts reports for every block.
+func xmlAddressGetInfo(id int, city string) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse>
+    <GetAddressInfoResult>
+      <AddressId>%d</AddressId>
+      <AddressName>Corporate</AddressName>
+      <DefaultYN>false</DefaultYN>
+      <EmailAddress>jane@example.com</EmailAddress>
+      <FirstName>Jane</FirstName>
+      <LastName>Doe</LastName>
+      <Organization>Example Corp</Organization>
+      <Address1>1 Main St</Address1>
+      <City>%s</City>
+      <StateProvince>Lisboa</StateProvince>
+      <StateProvinceChoice>P</StateProvinceChoice>
+      <Zip>1000-001</Zip>
+      <Country>PT</Country>
+      <Phone>+351.123456789</Phone>
+    </GetAddressInfoResult>
+  </CommandResponse>
+</ApiResponse>`, id, city)
+}
+
+func TestResourceContactsCreate_AddressID(t *testing.T) {
+	var sent url.Values
+	getInfoCalls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "text/xml")
+		switch r.FormValue("Command") {
+		case "namecheap.users.address.getInfo":
+			getInfoCalls++
+			_, _ = io.WriteString(w, xmlAddressGetInfo(42, "Lisbon"))
+		case "namecheap.domains.setContacts":
+			sent = r.Form
+			_, _ = io.WriteString(w, xmlSetContactsOK("example.com"))
+		case "namecheap.domains.getContacts":
+			_, _ = io.WriteString(w, xmlGetContacts("example.com"))
+		default:
+			_, _ = io.WriteString(w, apiErrorXML("1010101", "unexpected "+r.FormValue("Command")))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	d := schema.TestResourceDataRaw(t, resourceNamecheapDomainContacts().Schema, map[string]interface{}{
+		"domain":     "example.com",
+		"registrant": []interface{}{map[string]interface{}{"address_id": 42}},
+	})
+	diags := resourceContactsCreate(context.Background(), d, testMeta(newTestClient(srv.URL)))
+	require.False(t, diags.HasError(), "unexpected diags: %+v", diags)
+
+	// Every block defaults to the registrant, so each is sent with the entry's
+	// fields. The four blocks share one lookup, as do the four of the read back.
+	for _, prefix := range []string{"Registrant", "Tech", "Admin", "AuxBilling"} {
+		assert.Equal(t, "Jane", sent.Get(prefix+"FirstName"), prefix)
+		assert.Equal(t, "1000-001", sent.Get(prefix+"PostalCode"), prefix)
+		assert.Equal(t, "Example Corp", sent.Get(prefix+"OrganizationName"), prefix)
+	}
+	assert.Equal(t, 2, getInfoCalls)
+
+	// The domain matches the entry, so state keeps the bare reference.
+	assert.Equal(t, 42, d.Get("registrant.0.address_id"))
+	assert.Equal(t, "", d.Get("registrant.0.first_name"))
+}
+
+func TestResourceContactsRead_AddressIDDrift(t *testing.T) {
+	url := contactsTestServer(t, func(command string) string {
+		switch command {
+		case "namecheap.users.address.getInfo":
+			// The entry has moved on; the domain still has the old city.
+			return xmlAddressGetInfo(42, "Porto")
+		case "namecheap.domains.getContacts":
+			return xmlGetContacts("example.com")
+		}
+		return apiErrorXML("1010101", "unexpected "+command)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceNamecheapDomainContacts().Schema, map[string]interface{}{
+		"domain":     "example.com",
+		"registrant": []interface{}{map[string]interface{}{"address_id": 42}},
+	})
+	d.SetId("example.com")
+	diags := resourceContactsRead(context.Background(), d, testMeta(newTestClient(url)))
+	require.False(t, diags.HasError(), "unexpected diags: %+v", diags)
+
+	assert.Equal(t, 42, d.Get("registrant.0.address_id"))
+	assert.Equal(t, "Lisbon", d.Get("registrant.0.city"), "the live fields are exposed so the plan shows the drift")
+}
+
+func TestResourceContactsCreate_UnknownAddressID(t *testing.T) {
+	url := contactsTestServer(t, func(command string) string {
+		if command == "namecheap.users.address.getInfo" {
+			return apiErrorXML("2011170", "Address not found")
+		}
+		return apiErrorXML("1010101", "unexpected "+command)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceNamecheapDomainContacts().Schema, map[string]interface{}{
+		"domain":     "example.com",
+		"registrant": []interface{}{map[string]interface{}{"address_id": 7}},
+	})
+	diags := resourceContactsCreate(context.Background(), d, testMeta(newTestClient(url)))
+
+	require.True(t, diags.HasError())
+	assert.Equal(t, "Unable to resolve registrant address_id", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "address 7")
+	assert.Empty(t, d.Id())
+}
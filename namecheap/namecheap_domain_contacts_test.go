@@ -1,9 +1,13 @@
 package namecheap_provider
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
 	"github.com/stretchr/testify/assert"
 )
@@ -45,13 +49,17 @@ func fullContactInfo() namecheap.ContactInfo {
 }
 
 func TestExpandContactBlock(t *testing.T) {
-	got := expandContactBlock([]interface{}{fullContactMap()})
+	got, err := expandContactBlock([]interface{}{fullContactMap()}, nil)
+	assert.NoError(t, err)
 	assert.Equal(t, fullContactInfo(), got)
 }
 
 func TestExpandContactBlockEmpty(t *testing.T) {
-	assert.Equal(t, namecheap.ContactInfo{}, expandContactBlock([]interface{}{}))
-	assert.Equal(t, namecheap.ContactInfo{}, expandContactBlock(nil))
+	for _, raw := range []interface{}{[]interface{}{}, nil} {
+		got, err := expandContactBlock(raw, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, namecheap.ContactInfo{}, got)
+	}
 	assert.True(t, contactIsEmpty([]interface{}{}))
 	assert.True(t, contactIsEmpty(nil))
 	assert.False(t, contactIsEmpty([]interface{}{fullContactMap()}))
@@ -68,21 +76,28 @@ func TestFlattenContactInfo(t *testing.T) {
 func TestExpandFlattenRoundTrip(t *testing.T) {
 	c := fullContactInfo()
 	flattened := flattenContactInfo(&c)
-	assert.Equal(t, c, expandContactBlock(flattened))
+	got, err := expandContactBlock(flattened, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, c, got)
 }
 
 func TestContactOrDefault(t *testing.T) {
 	registrant := fullContactInfo()
 
 	// Omitted block falls back to the registrant.
-	assert.Equal(t, registrant, contactOrDefault([]interface{}{}, registrant))
-	assert.Equal(t, registrant, contactOrDefault(nil, registrant))
+	for _, raw := range []interface{}{[]interface{}{}, nil} {
+		got, err := contactOrDefault(raw, registrant, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, registrant, got)
+	}
 
 	// Present block is used verbatim.
 	tech := fullContactInfo()
 	tech.FirstName = "Tech"
 	techBlock := flattenContactInfo(&tech)
-	assert.Equal(t, tech, contactOrDefault(techBlock, registrant))
+	got, err := contactOrDefault(techBlock, registrant, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, tech, got)
 }
 
 // TestContactSchemaCompleteness enumerates every field on the SDK ContactInfo
@@ -172,3 +187,126 @@ func TestContactFieldValidators(t *testing.T) {
 func TestDomainContactsResourceSchemaValid(t *testing.T) {
 	assert.NoError(t, resourceNamecheapDomainContacts().InternalValidate(nil, true))
 }
+
+// staticAddressBook resolves ids from a fixed map, counting lookups.
+func staticAddressBook(entries map[int]namecheap.ContactInfo, lookups *int) contactAddressBook {
+	return func(addressID int) (namecheap.ContactInfo, error) {
+		*lookups++
+		contact, ok := entries[addressID]
+		if !ok {
+			return namecheap.ContactInfo{}, fmt.Errorf("address %d is not in the account's address book", addressID)
+		}
+		return contact, nil
+	}
+}
+
+func TestExpandContactBlockResolvesAddressID(t *testing.T) {
+	lookups := 0
+	addresses := staticAddressBook(map[int]namecheap.ContactInfo{42: fullContactInfo()}, &lookups)
+
+	got, err := expandContactBlock([]interface{}{map[string]interface{}{"address_id": 42}}, addresses)
+	assert.NoError(t, err)
+	assert.Equal(t, fullContactInfo(), got)
+	assert.Equal(t, 1, lookups)
+
+	_, err = expandContactBlock([]interface{}{map[string]interface{}{"address_id": 7}}, addresses)
+	assert.ErrorContains(t, err, "address 7")
+
+	// An inline block never consults the address book.
+	inline := fullContactMap()
+	inline["address_id"] = 0
+	_, err = expandContactBlock([]interface{}{inline}, addresses)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, lookups)
+}
+
+func TestDomainContactBlockSchema(t *testing.T) {
+	block := domainContactBlockSchema()
+
+	for _, f := range contactSchemaFields {
+		s := block[f.attr]
+		assert.Falsef(t, s.Required, "attr %q must not be Required once address_id can stand in for it", f.attr)
+		assert.Truef(t, s.Optional, "attr %q must be Optional", f.attr)
+	}
+	assert.Equal(t, schema.TypeInt, block["address_id"].Type)
+	assert.True(t, block["address_id"].Optional)
+
+	// The address book keeps its required fields.
+	assert.True(t, contactBlockSchema()["first_name"].Required)
+}
+
+func TestValidateContactBlocks(t *testing.T) {
+	blockType := cty.List(cty.Object(func() map[string]cty.Type {
+		types := map[string]cty.Type{"address_id": cty.Number}
+		for _, f := range contactSchemaFields {
+			types[f.attr] = cty.String
+		}
+		return types
+	}()))
+	block := func(addressID cty.Value, fields map[string]string) cty.Value {
+		attrs := map[string]cty.Value{"address_id": addressID}
+		for _, f := range contactSchemaFields {
+			attrs[f.attr] = cty.NullVal(cty.String)
+			if v, ok := fields[f.attr]; ok {
+				attrs[f.attr] = cty.StringVal(v)
+			}
+		}
+		return cty.ListVal([]cty.Value{cty.ObjectVal(attrs)})
+	}
+	config := func(registrant cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"registrant":  registrant,
+			"tech":        cty.NullVal(blockType),
+			"admin":       cty.NullVal(blockType),
+			"aux_billing": cty.NullVal(blockType),
+		})
+	}
+	full := map[string]string{}
+	for attr, v := range fullContactMap() {
+		full[attr] = v.(string)
+	}
+
+	assert.NoError(t, validateContactBlocks(config(block(cty.NullVal(cty.Number), full))))
+	assert.NoError(t, validateContactBlocks(config(block(cty.NumberIntVal(42), nil))))
+	assert.NoError(t, validateContactBlocks(config(block(cty.UnknownVal(cty.Number), nil))))
+
+	err := validateContactBlocks(config(block(cty.NumberIntVal(42), map[string]string{"first_name": "Jane"})))
+	assert.ErrorContains(t, err, "registrant: address_id conflicts with the inline contact fields (first_name)")
+
+	err = validateContactBlocks(config(block(cty.NullVal(cty.Number), map[string]string{"first_name": "Jane"})))
+	assert.ErrorContains(t, err, "registrant: missing required contact fields last_name, address1")
+}
+
+func TestContactBlockState(t *testing.T) {
+	live := fullContactInfo()
+	lookups := 0
+	addresses := staticAddressBook(map[int]namecheap.ContactInfo{42: fullContactInfo()}, &lookups)
+	reference := []interface{}{map[string]interface{}{"address_id": 42}}
+
+	// An inline block reads back inline.
+	got, diags := contactBlockState(flattenContactInfo(&live), &live, addresses)
+	assert.Empty(t, diags)
+	assert.Equal(t, flattenContactInfo(&live), got)
+	assert.Zero(t, lookups)
+
+	// A reference the domain still matches stays a bare reference.
+	got, diags = contactBlockState(reference, &live, addresses)
+	assert.Empty(t, diags)
+	assert.Equal(t, reference, got)
+
+	// Drift keeps the reference and exposes the live fields.
+	drifted := fullContactInfo()
+	drifted.City = "Porto"
+	got, diags = contactBlockState(reference, &drifted, addresses)
+	assert.Empty(t, diags)
+	want := flattenContactInfo(&drifted)
+	want[0].(map[string]interface{})["address_id"] = 42
+	assert.Equal(t, want, got)
+
+	// A reference to a deleted entry warns rather than failing the refresh.
+	got, diags = contactBlockState([]interface{}{map[string]interface{}{"address_id": 7}}, &live, addresses)
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, diag.Warning, diags[0].Severity)
+	}
+	assert.Equal(t, 7, got[0].(map[string]interface{})["address_id"])
+}
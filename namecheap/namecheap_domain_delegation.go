@@ -0,0 +1,444 @@
+package namecheap_provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+)
+
+// resourceNamecheapDomainDelegation delegates a domain to custom nameservers
+// together with the glue for the ones that live under the domain itself
+// (in-bailiwick hosts such as ns1.example.com for example.com).
+//
+// Without it, the glue is a namecheap_personal_nameserver and the delegation a
+// namecheap_domain_records with nameservers, and nothing orders the two: a
+// setCustom naming a host whose glue does not exist yet fails with an error
+// that does not say why. This resource owns both and sequences them:
+//   - Create and Update write the glue (domains.ns.create or update) before the
+//     delegation (domains.dns.setCustom), and delete the glue of hosts dropped
+//     from the configuration only after the delegation no longer names them.
+//   - Delete goes the other way: the domain returns to Namecheap's nameservers
+//     (domains.dns.setDefault) first, then the glue it owned is deleted.
+//
+// The domains.ns.* commands carry one IP per nameserver host, so each
+// in-bailiwick host has a single glue address.
+func resourceNamecheapDomainDelegation() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Delegates a domain to custom nameservers, registering the glue addresses of the nameservers that live under the domain itself before the delegation points at them.",
+		CreateContext: resourceDomainDelegationCreate,
+		ReadContext:   resourceDomainDelegationRead,
+		UpdateContext: resourceDomainDelegationUpdate,
+		DeleteContext: resourceDomainDelegationDelete,
+
+		CustomizeDiff: resourceDomainDelegationCustomizeDiff,
+
+		Timeouts: resourceTimeouts(),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: func(_ context.Context, data *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+				domain := strings.ToLower(data.Id())
+				if err := data.Set("domain", domain); err != nil {
+					return nil, err
+				}
+				if err := data.Set("verify_nameservers", false); err != nil {
+					return nil, err
+				}
+				data.SetId(domain)
+				return []*schema.ResourceData{data}, nil
+			},
+		},
+
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "The registered root domain to delegate (e.g. `example.com`). Changing this forces a new resource.",
+				ValidateFunc: validateDomainIsNotSubdomain,
+			},
+			"nameserver": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    2,
+				Description: "The nameservers to delegate the domain to, in order. At least two are required.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"host": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "The fully qualified hostname of the nameserver (e.g. `ns1.example.com`).",
+							ValidateFunc: validation.StringIsNotEmpty,
+						},
+						"ip": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "The glue address of a nameserver under `domain`, IPv4 or IPv6. Namecheap holds one address per nameserver host. Required for such a nameserver and not allowed for any other, whose glue belongs to its own domain.",
+							ValidateFunc: validation.IsIPAddress,
+						},
+					},
+				},
+			},
+			"verify_nameservers": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Before changing the delegation, ask each nameserver for the domain's SOA record and fail the apply, leaving everything unchanged, if any does not answer with authority. A nameserver under `domain` is asked at its `ip`; any other is looked up with the provider's `dns_resolver`. Defaults to `false`.",
+			},
+		},
+	}
+}
+
+// delegationNameserver is one nameserver block: a lower-cased host without a
+// trailing dot and, for an in-bailiwick host, its glue address.
+type delegationNameserver struct {
+	host string
+	ip   string
+}
+
+// delegationHost is the form hosts are compared and sent in.
+func delegationHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// delegationInBailiwick reports whether host lives under domain, which makes
+// its glue this domain's to register.
+func delegationInBailiwick(domain, host string) bool {
+	return strings.HasSuffix(delegationHost(host), "."+strings.ToLower(domain))
+}
+
+// delegationNameservers converts the nameserver blocks.
+func delegationNameservers(raw []interface{}) []delegationNameserver {
+	nameservers := make([]delegationNameserver, 0, len(raw))
+	for _, item := range raw {
+		block, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		ip, _ := block["ip"].(string)
+		nameservers = append(nameservers, delegationNameserver{host: delegationHost(block["host"].(string)), ip: ip})
+	}
+	return nameservers
+}
+
+func delegationHosts(nameservers []delegationNameserver) []string {
+	hosts := make([]string, 0, len(nameservers))
+	for _, ns := range nameservers {
+		hosts = append(hosts, ns.host)
+	}
+	return hosts
+}
+
+// validateDelegation refuses what setCustom would fail on later, or what would
+// leave glue nobody owns: a host named twice, an in-bailiwick host without a
+// glue address, and a glue address on a host under some other domain.
+func validateDelegation(domain string, nameservers []delegationNameserver) error {
+	seen := map[string]bool{}
+	for _, ns := range nameservers {
+		if seen[ns.host] {
+			return fmt.Errorf("nameserver %q is listed more than once", ns.host)
+		}
+		seen[ns.host] = true
+
+		inBailiwick := delegationInBailiwick(domain, ns.host)
+		if inBailiwick && ns.ip == "" {
+			return fmt.Errorf("nameserver %q is under %s, so the registry needs its glue: set ip to the address it answers on", ns.host, domain)
+		}
+		if !inBailiwick && ns.ip != "" {
+			return fmt.Errorf("nameserver %q is not under %s, so its glue cannot be set here: remove ip, and register it with the domain it belongs to (namecheap_personal_nameserver)", ns.host, domain)
+		}
+	}
+	return nil
+}
+
+// resourceDomainDelegationCustomizeDiff runs validateDelegation at plan time
+// when every host and address is known; otherwise Create and Update run it at
+// apply.
+func resourceDomainDelegationCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, _ interface{}) error {
+	if rawConfig := diff.GetRawConfig(); rawConfig.IsNull() || !rawConfig.IsWhollyKnown() {
+		return nil
+	}
+	domain := strings.ToLower(diff.Get("domain").(string))
+	return validateDelegation(domain, delegationNameservers(diff.Get("nameserver").([]interface{})))
+}
+
+// delegationEnsureGlue registers host's glue, or corrects it when Namecheap
+// holds another address. The current value is read live, so it is also the
+// OldIP an update needs.
+func delegationEnsureGlue(ctx context.Context, client *namecheap.Client, sld, tld string, ns delegationNameserver) error {
+	resp, err := client.DomainsNS.GetInfoWithContext(ctx, sld, tld, ns.host)
+	if err != nil {
+		if !isNameserverNotFoundError(err) {
+			return fmt.Errorf("read glue of %s: %w", ns.host, err)
+		}
+		if _, err := client.DomainsNS.CreateWithContext(ctx, sld, tld, ns.host, ns.ip); err != nil {
+			return fmt.Errorf("create glue of %s: %w", ns.host, err)
+		}
+		return nil
+	}
+
+	current := ""
+	if resp != nil && resp.DomainNameserverInfoResult != nil && resp.DomainNameserverInfoResult.IP != nil {
+		current = strings.TrimSpace(*resp.DomainNameserverInfoResult.IP)
+	}
+	if sameIP(current, ns.ip) {
+		return nil
+	}
+	if _, err := client.DomainsNS.UpdateWithContext(ctx, sld, tld, ns.host, current, ns.ip); err != nil {
+		return fmt.Errorf("update glue of %s: %w", ns.host, err)
+	}
+	return nil
+}
+
+// delegationDeleteGlue deletes host's glue. Glue already gone is not an error.
+func delegationDeleteGlue(ctx context.Context, client *namecheap.Client, sld, tld, host string) error {
+	if _, err := client.DomainsNS.DeleteWithContext(ctx, sld, tld, host); err != nil && !isNameserverNotFoundError(err) {
+		return fmt.Errorf("delete glue of %s: %w", host, err)
+	}
+	return nil
+}
+
+// delegationTargets returns what verify_nameservers asks: an in-bailiwick
+// host at its glue address, since it cannot resolve before the delegation
+// exists, and any other host by name.
+func delegationTargets(domain string, nameservers []delegationNameserver) []nameserverTarget {
+	targets := make([]nameserverTarget, 0, len(nameservers))
+	for _, ns := range nameservers {
+		target := nameserverTarget{host: ns.host}
+		if delegationInBailiwick(domain, ns.host) {
+			target.addresses = []string{ns.ip}
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// delegationApply brings Namecheap from previous (the nameservers in state,
+// empty on create) to nameservers: glue first, then the delegation, then the
+// glue of in-bailiwick hosts no longer delegated to. With verify_nameservers
+// set, the check runs before any of it.
+func delegationApply(ctx context.Context, data *schema.ResourceData, meta interface{}, domain string, previous, nameservers []delegationNameserver) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+
+	if err := validateDelegation(domain, nameservers); err != nil {
+		return diag.FromErr(err)
+	}
+
+	var diags diag.Diagnostics
+	if data.Get("verify_nameservers").(bool) {
+		if diags = verifyNameservers(ctx, meta.(*providerMeta).config, domain, delegationTargets(domain, nameservers)); diags.HasError() {
+			return diags
+		}
+	}
+
+	sld, tld, err := nameserverSplitDomain(domain)
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	for _, ns := range nameservers {
+		if !delegationInBailiwick(domain, ns.host) {
+			continue
+		}
+		if err := delegationEnsureGlue(ctx, client, sld, tld, ns); err != nil {
+			return append(diags, diagFromClientError(err)...)
+		}
+	}
+
+	if _, err := client.DomainsDNS.SetCustomWithContext(ctx, domain, delegationHosts(nameservers)); err != nil {
+		return append(diags, diagFromClientError(err)...)
+	}
+
+	kept := map[string]bool{}
+	for _, ns := range nameservers {
+		kept[ns.host] = true
+	}
+	for _, ns := range previous {
+		if kept[ns.host] || !delegationInBailiwick(domain, ns.host) {
+			continue
+		}
+		if err := delegationDeleteGlue(ctx, client, sld, tld, ns.host); err != nil {
+			return append(diags, diagFromClientError(err)...)
+		}
+	}
+
+	return diags
+}
+
+func resourceDomainDelegationCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	domain := strings.ToLower(data.Get("domain").(string))
+
+	if diags := lockDomain(ctx, domain); diags != nil {
+		return diags
+	}
+	defer ncMutexKV.Unlock(domain)
+
+	nameservers := delegationNameservers(data.Get("nameserver").([]interface{}))
+	diags := delegationApply(ctx, data, meta, domain, nil, nameservers)
+	if diags.HasError() {
+		return diags
+	}
+
+	data.SetId(domain)
+
+	return append(diags, resourceDomainDelegationReadLocked(ctx, data, client, domain)...)
+}
+
+func resourceDomainDelegationRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	domain := strings.ToLower(data.Get("domain").(string))
+
+	if diags := lockDomain(ctx, domain); diags != nil {
+		return diags
+	}
+	defer ncMutexKV.Unlock(domain)
+
+	return resourceDomainDelegationReadLocked(ctx, data, client, domain)
+}
+
+// resourceDomainDelegationReadLocked records the live delegation. A domain
+// back on Namecheap's nameservers reads as no nameservers at all, and an
+// in-bailiwick host whose glue is gone as one without ip, so either plans the
+// write that restores it.
+func resourceDomainDelegationReadLocked(ctx context.Context, data *schema.ResourceData, client *namecheap.Client, domain string) diag.Diagnostics {
+	resp, err := client.DomainsDNS.GetListWithContext(ctx, domain)
+	if err != nil {
+		if isDomainGoneError(err) {
+			data.SetId("")
+			return nil
+		}
+		return diagFromClientError(err)
+	}
+	if err := validateGetListResponse(resp); err != nil {
+		return diagFromClientError(err)
+	}
+
+	configured := delegationNameservers(data.Get("nameserver").([]interface{}))
+
+	var live []string
+	result := resp.DomainDNSGetListResult
+	if !*result.IsUsingOurDNS && result.Nameservers != nil {
+		for _, host := range *result.Nameservers {
+			live = append(live, delegationHost(host))
+		}
+	}
+	live = delegationConfiguredOrder(live, delegationHosts(configured))
+
+	sld, tld, err := nameserverSplitDomain(domain)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	blocks := make([]interface{}, 0, len(live))
+	for _, host := range live {
+		block := map[string]interface{}{"host": host, "ip": ""}
+		if delegationInBailiwick(domain, host) {
+			info, err := client.DomainsNS.GetInfoWithContext(ctx, sld, tld, host)
+			if err != nil && !isNameserverNotFoundError(err) {
+				return diagFromClientError(err)
+			}
+			if err == nil && info != nil && info.DomainNameserverInfoResult != nil && info.DomainNameserverInfoResult.IP != nil {
+				ip := strings.TrimSpace(*info.DomainNameserverInfoResult.IP)
+				// An IPv6 address has many spellings; keep the configured one
+				// while it denotes the same address.
+				for _, ns := range configured {
+					if ns.host == host && sameIP(ip, ns.ip) {
+						ip = ns.ip
+					}
+				}
+				block["ip"] = ip
+			}
+		}
+		blocks = append(blocks, block)
+	}
+
+	if err := data.Set("domain", domain); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := data.Set("nameserver", blocks); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+// delegationConfiguredOrder returns live in the configured order when both
+// name the same hosts, since the order Namecheap lists them in carries no
+// meaning; any other difference is returned as read, to show up as drift.
+func delegationConfiguredOrder(live, configured []string) []string {
+	if len(live) != len(configured) {
+		return live
+	}
+	remaining := map[string]int{}
+	for _, host := range live {
+		remaining[host]++
+	}
+	for _, host := range configured {
+		if remaining[host] == 0 {
+			return live
+		}
+		remaining[host]--
+	}
+	return configured
+}
+
+func resourceDomainDelegationUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	domain := strings.ToLower(data.Get("domain").(string))
+
+	if diags := lockDomain(ctx, domain); diags != nil {
+		return diags
+	}
+	defer ncMutexKV.Unlock(domain)
+
+	// Toggling verify_nameservers alone changes nothing on Namecheap.
+	var diags diag.Diagnostics
+	if data.HasChange("nameserver") {
+		oldRaw, newRaw := data.GetChange("nameserver")
+		previous := delegationNameservers(oldRaw.([]interface{}))
+		nameservers := delegationNameservers(newRaw.([]interface{}))
+		if diags = delegationApply(ctx, data, meta, domain, previous, nameservers); diags.HasError() {
+			return diags
+		}
+	}
+
+	return append(diags, resourceDomainDelegationReadLocked(ctx, data, client, domain)...)
+}
+
+func resourceDomainDelegationDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	domain := strings.ToLower(data.Get("domain").(string))
+
+	if diags := lockDomain(ctx, domain); diags != nil {
+		return diags
+	}
+	defer ncMutexKV.Unlock(domain)
+
+	// The glue of a nameserver the domain still delegates to cannot go first,
+	// so the domain returns to Namecheap's nameservers before it is deleted.
+	if _, err := client.DomainsDNS.SetDefaultWithContext(ctx, domain); err != nil {
+		if isDomainGoneError(err) {
+			return nil
+		}
+		return diagFromClientError(err)
+	}
+
+	sld, tld, err := nameserverSplitDomain(domain)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	for _, ns := range delegationNameservers(data.Get("nameserver").([]interface{})) {
+		if !delegationInBailiwick(domain, ns.host) {
+			continue
+		}
+		if err := delegationDeleteGlue(ctx, client, sld, tld, ns.host); err != nil {
+			return diagFromClientError(err)
+		}
+	}
+
+	return nil
+}
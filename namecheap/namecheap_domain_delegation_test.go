@@ -0,0 +1,215 @@
+package namecheap_provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// These cover what namecheap_domain_delegation accepts and the order of its
+// API calls; the mock acceptance suite (mock_domain_delegation_test.go) covers
+// the lifecycle against a server that refuses the wrong order.
+
+func delegationTestData(t *testing.T, nameservers ...map[string]interface{}) *schema.ResourceData {
+	t.Helper()
+	raw := make([]interface{}, 0, len(nameservers))
+	for _, ns := range nameservers {
+		raw = append(raw, ns)
+	}
+	return schema.TestResourceDataRaw(t, resourceNamecheapDomainDelegation().Schema, map[string]interface{}{
+		"domain":     "example.com",
+		"nameserver": raw,
+	})
+}
+
+func dnsGetListCustomXML(domain string, nameservers ...string) string {
+	var lines []string
+	for _, ns := range nameservers {
+		lines = append(lines, fmt.Sprintf(`<Nameserver>%s</Nameserver>`, ns))
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse>
+    <DomainDNSGetListResult Domain="%s" IsUsingOurDNS="false" IsPremiumDNS="false" IsUsingFreeDNS="false">
+      %s
+    </DomainDNSGetListResult>
+  </CommandResponse>
+</ApiResponse>`, domain, strings.Join(lines, "\n      "))
+}
+
+func dnsUpdatedXML(result, domain string) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse>
+    <%s Domain="%s" Updated="true" />
+  </CommandResponse>
+</ApiResponse>`, result, domain)
+}
+
+// commandLog records "command nameserver" lines in the order a server sees them.
+type commandLog struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *commandLog) add(command string, form url.Values) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, strings.TrimSpace(strings.TrimPrefix(command, "namecheap.")+" "+form.Get("Nameserver")))
+}
+
+func (l *commandLog) writes() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var writes []string
+	for _, line := range l.lines {
+		if !strings.Contains(line, "getInfo") && !strings.Contains(line, "getList") {
+			writes = append(writes, line)
+		}
+	}
+	return writes
+}
+
+func TestDelegationInBailiwick(t *testing.T) {
+	assert.True(t, delegationInBailiwick("example.com", "ns1.example.com"))
+	assert.True(t, delegationInBailiwick("example.com", "NS1.Example.COM."))
+	assert.True(t, delegationInBailiwick("example.com", "a.ns.example.com"))
+	assert.False(t, delegationInBailiwick("example.com", "example.com"))
+	assert.False(t, delegationInBailiwick("example.com", "ns1.notexample.com"))
+	assert.False(t, delegationInBailiwick("example.com", "ns1.example.com.au"))
+}
+
+func TestValidateDelegation(t *testing.T) {
+	ok := []delegationNameserver{
+		{host: "ns1.example.com", ip: "93.184.216.34"},
+		{host: "ns1.provider.net"},
+	}
+	assert.NoError(t, validateDelegation("example.com", ok))
+
+	tests := []struct {
+		name        string
+		nameservers []delegationNameserver
+		want        string
+	}{
+		{"in-bailiwick without glue", []delegationNameserver{{host: "ns1.example.com"}, {host: "ns1.provider.net"}},
+			`nameserver "ns1.example.com" is under example.com, so the registry needs its glue`},
+		{"glue on another domain's host", []delegationNameserver{{host: "ns1.provider.net", ip: "93.184.216.34"}, {host: "ns2.provider.net"}},
+			`nameserver "ns1.provider.net" is not under example.com, so its glue cannot be set here`},
+		{"duplicate host", []delegationNameserver{{host: "ns1.provider.net"}, {host: "ns1.provider.net"}},
+			`nameserver "ns1.provider.net" is listed more than once`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorContains(t, validateDelegation("example.com", tc.nameservers), tc.want)
+		})
+	}
+}
+
+func TestDelegationConfiguredOrder(t *testing.T) {
+	configured := []string{"ns2.example.com", "ns1.example.com"}
+	assert.Equal(t, configured, delegationConfiguredOrder([]string{"ns1.example.com", "ns2.example.com"}, configured))
+	live := []string{"ns1.example.com", "ns3.example.com"}
+	assert.Equal(t, live, delegationConfiguredOrder(live, configured), "a different set is drift and is kept as read")
+}
+
+// TestResourceDomainDelegationCreateOrder proves the glue is written before
+// the delegation: the missing ns1 glue is created, the stale ns2 glue updated
+// from the address Namecheap holds, and only then is setCustom sent.
+func TestResourceDomainDelegationCreateOrder(t *testing.T) {
+	log := &commandLog{}
+	var updateForm url.Values
+	srv := newNSMockServer(t, func(command string, form url.Values) string {
+		log.add(command, form)
+		switch command {
+		case "namecheap.domains.ns.getInfo":
+			if form.Get("Nameserver") == "ns2.example.com" {
+				return nsGetInfoXML("example.com", "ns2.example.com", "93.184.216.99")
+			}
+			return apiErrorXML("5013160", "Nameserver not found")
+		case "namecheap.domains.ns.create":
+			return nsCreateSuccessXML("example.com", form.Get("Nameserver"), form.Get("IP"))
+		case "namecheap.domains.ns.update":
+			updateForm = form
+			return nsUpdateSuccessXML("example.com", form.Get("Nameserver"))
+		case "namecheap.domains.dns.setCustom":
+			return dnsUpdatedXML("DomainDNSSetCustomResult", "example.com")
+		case "namecheap.domains.dns.getList":
+			return dnsGetListCustomXML("example.com", "ns1.example.com", "ns2.example.com")
+		}
+		return apiErrorXML("1010101", "unexpected command "+command)
+	})
+
+	data := delegationTestData(t,
+		map[string]interface{}{"host": "ns1.example.com", "ip": "93.184.216.34"},
+		map[string]interface{}{"host": "ns2.example.com", "ip": "93.184.216.35"},
+	)
+	diags := resourceDomainDelegationCreate(context.Background(), data, testMeta(newTestClient(srv.server.URL)))
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, []string{
+		"domains.ns.create ns1.example.com",
+		"domains.ns.update ns2.example.com",
+		"domains.dns.setCustom",
+	}, log.writes())
+	assert.Equal(t, "93.184.216.99", updateForm.Get("OldIP"))
+	assert.Equal(t, "93.184.216.35", updateForm.Get("IP"))
+	assert.Equal(t, "example.com", data.Id())
+}
+
+func TestResourceDomainDelegationCreateRefusesMissingGlue(t *testing.T) {
+	srv := newNSMockServer(t, func(command string, _ url.Values) string {
+		return apiErrorXML("1010101", "unexpected command "+command)
+	})
+
+	data := delegationTestData(t,
+		map[string]interface{}{"host": "ns1.example.com"},
+		map[string]interface{}{"host": "ns1.provider.net"},
+	)
+	diags := resourceDomainDelegationCreate(context.Background(), data, testMeta(newTestClient(srv.server.URL)))
+	require.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "needs its glue")
+}
+
+// TestResourceDomainDelegationDeleteOrder proves the teardown is the reverse:
+// the domain goes back to Namecheap's nameservers before the glue it owned is
+// deleted, and a nameserver under another domain keeps its glue.
+func TestResourceDomainDelegationDeleteOrder(t *testing.T) {
+	log := &commandLog{}
+	srv := newNSMockServer(t, func(command string, form url.Values) string {
+		log.add(command, form)
+		switch command {
+		case "namecheap.domains.dns.setDefault":
+			return dnsUpdatedXML("DomainDNSSetDefaultResult", "example.com")
+		case "namecheap.domains.ns.delete":
+			if form.Get("Nameserver") == "ns2.example.com" {
+				return apiErrorXML("5013160", "Nameserver not found")
+			}
+			return nsDeleteSuccessXML("example.com", form.Get("Nameserver"))
+		}
+		return apiErrorXML("1010101", "unexpected command "+command)
+	})
+
+	data := delegationTestData(t,
+		map[string]interface{}{"host": "ns1.example.com", "ip": "93.184.216.34"},
+		map[string]interface{}{"host": "ns2.example.com", "ip": "93.184.216.35"},
+		map[string]interface{}{"host": "ns1.provider.net"},
+	)
+	data.SetId("example.com")
+	diags := resourceDomainDelegationDelete(context.Background(), data, testMeta(newTestClient(srv.server.URL)))
+	require.False(t, diags.HasError(), "%v", diags)
+
+	assert.Equal(t, []string{
+		"domains.dns.setDefault",
+		"domains.ns.delete ns1.example.com",
+		"domains.ns.delete ns2.example.com",
+	}, log.writes(), "glue already gone is not an error")
+}
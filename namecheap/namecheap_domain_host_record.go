@@ -52,7 +52,10 @@ const (
 // between that read and the setHosts is inside the replaced set — overwritten,
 // verified as correct, and lost. Narrowed, not closed; see the warning on the
 // resource page.
-func resourceNamecheapDomainHostRecord() *schema.Resource {
+//
+// The unset mx_pref and ttl come from defaults; a nil source gives the built-in
+// defaults.
+func resourceNamecheapDomainHostRecord(defaults *providerDefaultsSource) *schema.Resource {
 	return &schema.Resource{
 		Description: "Manages a single DNS host record on a domain, leaving all other records untouched. Mutually exclusive with namecheap_domain_records for the same domain.",
 
@@ -60,6 +63,9 @@ func resourceNamecheapDomainHostRecord() *schema.Resource {
 		ReadContext:   resourceNamecheapDomainHostRecordRead,
 		UpdateContext: resourceNamecheapDomainHostRecordUpdate,
 		DeleteContext: resourceNamecheapDomainHostRecordDelete,
+
+		Timeouts: resourceTimeouts(),
+
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceNamecheapDomainHostRecordImport,
 		},
@@ -100,17 +106,17 @@ func resourceNamecheapDomainHostRecord() *schema.Resource {
 			"mx_pref": {
 				Type:         schema.TypeInt,
 				Optional:     true,
-				Default:      hostRecordFixedMXPref,
+				DefaultFunc:  defaults.mxPref,
 				ValidateFunc: validation.IntBetween(0, 255),
 				Description: fmt.Sprintf("The MX preference, lower being preferred, between 0 and 255. Applies to MX records only — Namecheap stores a fixed %d for every other type, so the value is ignored there rather than fought over. "+
-					"For MX records it is part of what identifies the record, so a primary and a backup mail server can name the same host.", hostRecordFixedMXPref),
+					"For MX records it is part of what identifies the record, so a primary and a backup mail server can name the same host. Defaults to the provider's defaults.mx_pref, or %d.", hostRecordFixedMXPref, defaultRecordMXPref),
 			},
 			"ttl": {
 				Type:         schema.TypeInt,
 				Optional:     true,
-				Default:      1800,
+				DefaultFunc:  defaults.ttl,
 				ValidateFunc: validation.IntBetween(namecheap.MinTTL, namecheap.MaxTTL),
-				Description:  fmt.Sprintf("Time to live in seconds, between %d and %d. Changing this edits the existing record rather than forcing a new resource.", namecheap.MinTTL, namecheap.MaxTTL),
+				Description:  fmt.Sprintf("Time to live in seconds, between %d and %d. Defaults to the provider's defaults.ttl, or %d. Changing this edits the existing record rather than forcing a new resource.", namecheap.MinTTL, namecheap.MaxTTL, defaultRecordTTL),
 			},
 		},
 	}
@@ -256,10 +262,12 @@ func hostRecordID(domain, recordType, hostname, address string) string {
 }
 
 func resourceNamecheapDomainHostRecordCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 	domain := strings.ToLower(data.Get("domain").(string))
 
-	ncMutexKV.Lock(domain)
+	if diags := lockDomain(ctx, domain); diags != nil {
+		return diags
+	}
 	defer ncMutexKV.Unlock(domain)
 
 	record := hostRecordFromData(data)
@@ -286,7 +294,7 @@ func resourceNamecheapDomainHostRecordCreate(ctx context.Context, data *schema.R
 }
 
 func resourceNamecheapDomainHostRecordRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 	domain := strings.ToLower(data.Get("domain").(string))
 
 	record := hostRecordFromData(data)
@@ -324,13 +332,18 @@ func resourceNamecheapDomainHostRecordRead(ctx context.Context, data *schema.Res
 }
 
 func resourceNamecheapDomainHostRecordUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 	domain := strings.ToLower(data.Get("domain").(string))
 
-	ncMutexKV.Lock(domain)
+	// A timed-out wait for the lock is a failure too, and leaves state to be
+	// restored like any other.
+	if diags := lockDomain(ctx, domain); diags != nil {
+		hostRecordRestoreBeforeChange(data)
+		return diags
+	}
 	defer ncMutexKV.Unlock(domain)
 
-	// Every failure funnels through here, because SDKv2 persists the planned values
+	// Every other failure funnels through here, because SDKv2 persists the planned values
 	// whenever an update returns an error — whichever step failed. Restoring them in
 	// one place is what stops a refused or failed update from orphaning the record
 	// this resource still owns. See hostRecordRestoreBeforeChange.
@@ -394,10 +407,12 @@ func hostRecordApplyUpdate(ctx context.Context, client *namecheap.Client, data *
 }
 
 func resourceNamecheapDomainHostRecordDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 	domain := strings.ToLower(data.Get("domain").(string))
 
-	ncMutexKV.Lock(domain)
+	if diags := lockDomain(ctx, domain); diags != nil {
+		return diags
+	}
 	defer ncMutexKV.Unlock(domain)
 
 	record := hostRecordFromData(data)
@@ -451,7 +466,7 @@ func resourceNamecheapDomainHostRecordImport(ctx context.Context, data *schema.R
 		Address:    namecheap.String(address),
 	}
 
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 	found, live, diags := hostRecordLookup(ctx, client, strings.ToLower(domain), want)
 	if diags.HasError() {
 		return nil, hostRecordImportError(domain, diags)
@@ -474,7 +489,14 @@ func resourceNamecheapDomainHostRecordImport(ctx context.Context, data *schema.R
 	_ = data.Set("type", derefString(live.Type))
 	_ = data.Set("address", address)
 	_ = data.Set("ttl", derefInt(live.TTL))
-	_ = data.Set("mx_pref", derefInt(live.MXPref))
+	// A type without a preference reads back as Namecheap's fixed 10, which is
+	// not what a configuration leaving mx_pref unset plans once the provider's
+	// defaults block says otherwise; take the default so the import settles.
+	if hostRecordMXPrefIsIdentity(derefString(live.Type)) {
+		_ = data.Set("mx_pref", derefInt(live.MXPref))
+	} else {
+		_ = data.Set("mx_pref", meta.(*providerMeta).config.defaults.mxPref)
+	}
 	data.SetId(hostRecordID(domain, derefString(live.Type), hostname, address))
 
 	return []*schema.ResourceData{data}, nil
@@ -201,7 +201,7 @@ func TestDomainHostRecordEffectiveMXPref(t *testing.T) {
 // Required alone accepts. An empty hostname would mean the apex through SDK
 // normalization while rendering an ID the importer refuses to parse.
 func TestDomainHostRecordSchemaRejectsEmptyIdentityFields(t *testing.T) {
-	s := resourceNamecheapDomainHostRecord().Schema
+	s := resourceNamecheapDomainHostRecord(nil).Schema
 	for _, field := range []string{"hostname", "address"} {
 		validate := s[field].ValidateFunc
 		if !assert.NotNil(t, validate, "%s must reject an empty value", field) {
@@ -282,7 +282,7 @@ func TestDomainHostRecordLookupMatchesNormalized(t *testing.T) {
 // and which happen in place. Getting this wrong is a data-loss bug: an in-place
 // "update" of the hostname would rewrite a different record.
 func TestDomainHostRecordSchemaForcesNewOnIdentity(t *testing.T) {
-	s := resourceNamecheapDomainHostRecord().Schema
+	s := resourceNamecheapDomainHostRecord(nil).Schema
 
 	for _, field := range []string{"domain", "hostname", "type"} {
 		assert.True(t, s[field].ForceNew, "%s is part of the record's identity and must force replacement", field)
@@ -297,7 +297,7 @@ func TestDomainHostRecordSchemaForcesNewOnIdentity(t *testing.T) {
 // truncated on the way out — 256 arriving as 0, the *most* preferred, which is the
 // opposite of what was asked for. It has to be rejected at plan time instead.
 func TestDomainHostRecordSchemaRejectsUnrepresentableMXPref(t *testing.T) {
-	validate := resourceNamecheapDomainHostRecord().Schema["mx_pref"].ValidateFunc
+	validate := resourceNamecheapDomainHostRecord(nil).Schema["mx_pref"].ValidateFunc
 	if !assert.NotNil(t, validate, "mx_pref must be validated, not silently truncated") {
 		return
 	}
@@ -314,7 +314,7 @@ func TestDomainHostRecordSchemaRejectsUnrepresentableMXPref(t *testing.T) {
 
 // TestDomainHostRecordSchemaShape guards the contract the docs describe.
 func TestDomainHostRecordSchemaShape(t *testing.T) {
-	r := resourceNamecheapDomainHostRecord()
+	r := resourceNamecheapDomainHostRecord(nil)
 
 	assert.NotNil(t, r.Importer, "the resource must be importable")
 	assert.NotEmpty(t, r.Description, "the registry page summary is generated from this")
@@ -324,10 +324,12 @@ func TestDomainHostRecordSchemaShape(t *testing.T) {
 	}
 	for _, field := range []string{"ttl", "mx_pref"} {
 		assert.True(t, r.Schema[field].Optional, "%s should be optional", field)
-		assert.NotNil(t, r.Schema[field].Default, "%s should have a default so plans stay empty", field)
+		assert.NotNil(t, r.Schema[field].DefaultFunc, "%s should have a default so plans stay empty", field)
 	}
 	// Defaults must match what the API returns for an unset value, or every read
 	// would report drift.
-	assert.Equal(t, 1800, r.Schema["ttl"].Default)
-	assert.Equal(t, 10, r.Schema["mx_pref"].Default)
+	ttl, _ := r.Schema["ttl"].DefaultValue()
+	mxPref, _ := r.Schema["mx_pref"].DefaultValue()
+	assert.Equal(t, 1800, ttl)
+	assert.Equal(t, 10, mxPref)
 }
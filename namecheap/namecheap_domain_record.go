@@ -17,7 +17,9 @@ const (
 	ncModeImport    = "IMPORT"
 )
 
-func resourceNamecheapDomainRecords() *schema.Resource {
+// resourceNamecheapDomainRecords takes the unset mode, email_type, ttl and
+// mx_pref from defaults; a nil source gives the built-in defaults.
+func resourceNamecheapDomainRecords(defaults *providerDefaultsSource) *schema.Resource {
 	return &schema.Resource{
 		Description:   "Manages the DNS host records of a domain, or delegates the domain to custom nameservers. In MERGE mode it manages only the records it declares; in OVERWRITE mode it owns the whole zone and deletes anything absent from the configuration.",
 		CreateContext: resourceRecordCreate,
@@ -25,6 +27,8 @@ func resourceNamecheapDomainRecords() *schema.Resource {
 		ReadContext:   resourceRecordRead,
 		DeleteContext: resourceRecordDelete,
 
+		Timeouts: resourceTimeouts(),
+
 		Importer: &schema.ResourceImporter{
 			StateContext: func(ctx context.Context, data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 				if err := data.Set("domain", data.Id()); err != nil {
@@ -33,6 +37,9 @@ func resourceNamecheapDomainRecords() *schema.Resource {
 				if err := data.Set("mode", ncModeImport); err != nil {
 					return nil, err
 				}
+				if err := data.Set("verify_nameservers", false); err != nil {
+					return nil, err
+				}
 
 				return []*schema.ResourceData{data}, nil
 			},
@@ -50,15 +57,16 @@ func resourceNamecheapDomainRecords() *schema.Resource {
 				ConflictsWith: []string{"nameservers"},
 				Type:          schema.TypeString,
 				Optional:      true,
+				DefaultFunc:   defaults.emailType,
 				ValidateFunc:  validation.StringInSlice(namecheap.AllowedEmailTypeValues, false),
-				Description:   fmt.Sprintf("Possible values: %s", strings.TrimSpace(strings.Join(namecheap.AllowedEmailTypeValues, ", "))),
+				Description:   fmt.Sprintf("Possible values: %s. Defaults to the provider's defaults.email_type; when that is unset too, the domain's email setting is left as it is.", strings.TrimSpace(strings.Join(namecheap.AllowedEmailTypeValues, ", "))),
 			},
 			"mode": {
 				Type:         schema.TypeString,
 				Optional:     true,
-				Default:      ncModeMerge,
+				DefaultFunc:  defaults.recordsMode,
 				ValidateFunc: validation.StringInSlice([]string{ncModeMerge, ncModeOverwrite}, true),
-				Description:  fmt.Sprintf("Possible values: %s (default), %s", ncModeMerge, ncModeOverwrite),
+				Description:  fmt.Sprintf("Possible values: %s (default), %s. The provider's defaults.records_mode overrides the default.", ncModeMerge, ncModeOverwrite),
 			},
 			"record": {
 				ConflictsWith: []string{"nameservers"},
@@ -86,14 +94,14 @@ func resourceNamecheapDomainRecords() *schema.Resource {
 						"mx_pref": {
 							Type:        schema.TypeInt,
 							Optional:    true,
-							Default:     10,
-							Description: "MX preference for host. Applicable for MX records only",
+							DefaultFunc: defaults.mxPref,
+							Description: fmt.Sprintf("MX preference for host. Applicable for MX records only. Defaults to the provider's defaults.mx_pref, or %d.", defaultRecordMXPref),
 						},
 						"ttl": {
 							Type:        schema.TypeInt,
 							Optional:    true,
-							Default:     1800,
-							Description: fmt.Sprintf("Time to live for all record types. Possible values: any value between %d to %d", namecheap.MinTTL, namecheap.MaxTTL),
+							DefaultFunc: defaults.ttl,
+							Description: fmt.Sprintf("Time to live for all record types. Possible values: any value between %d to %d. Defaults to the provider's defaults.ttl, or %d.", namecheap.MinTTL, namecheap.MaxTTL, defaultRecordTTL),
 						},
 					},
 				},
@@ -108,6 +116,12 @@ func resourceNamecheapDomainRecords() *schema.Resource {
 					ValidateFunc: validation.StringIsNotEmpty,
 				},
 			},
+			"verify_nameservers": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Before delegating the domain to `nameservers`, ask each of them for the domain's SOA record and fail the apply, leaving the delegation unchanged, if any does not resolve, does not answer, or answers without authority. Nameserver hostnames are looked up with the provider's `dns_resolver`. Defaults to `false`.",
+			},
 		},
 	}
 }
@@ -135,7 +149,7 @@ func validateDomainIsNotSubdomain(val interface{}, key string) (warns []string,
 }
 
 func resourceRecordCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 
 	domain := strings.ToLower(data.Get("domain").(string))
 	mode := strings.ToUpper(data.Get("mode").(string))
@@ -158,12 +172,21 @@ func resourceRecordCreate(ctx context.Context, data *schema.ResourceData, meta i
 	}
 
 	if mode == ncModeMerge {
-		ncMutexKV.Lock(domain)
+		if diags := lockDomain(ctx, domain); diags != nil {
+			return diags
+		}
 		defer ncMutexKV.Unlock(domain)
 	}
 
 	var diags diag.Diagnostics
 
+	if nameservers != nil && data.Get("verify_nameservers").(bool) {
+		targets := nameserverTargetsFromHosts(convertInterfacesToString(nameservers))
+		if diags = verifyNameservers(ctx, meta.(*providerMeta).config, domain, targets); diags.HasError() {
+			return diags
+		}
+	}
+
 	if mode == ncModeMerge && records != nil {
 		recordDiags := createRecordsMerge(ctx, domain, emailType, records, client)
 		if recordDiags.HasError() {
@@ -202,7 +225,8 @@ func resourceRecordCreate(ctx context.Context, data *schema.ResourceData, meta i
 }
 
 func resourceRecordRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
+	defaultMXPref := meta.(*providerMeta).config.defaults.mxPref
 
 	domain := strings.ToLower(data.Get("domain").(string))
 	mode := strings.ToUpper(data.Get("mode").(string))
@@ -225,7 +249,9 @@ func resourceRecordRead(ctx context.Context, data *schema.ResourceData, meta int
 	}
 
 	if mode == ncModeMerge {
-		ncMutexKV.Lock(domain)
+		if diags := lockDomain(ctx, domain); diags != nil {
+			return diags
+		}
 		defer ncMutexKV.Unlock(domain)
 	}
 
@@ -265,7 +291,7 @@ func resourceRecordRead(ctx context.Context, data *schema.ResourceData, meta int
 		_ = data.Set("record", []interface{}{})
 	} else {
 		if mode == ncModeMerge {
-			realRecords, realEmailType, recordDiags := readRecordsMerge(ctx, domain, records, client)
+			realRecords, realEmailType, recordDiags := readRecordsMerge(ctx, domain, records, client, defaultMXPref)
 			if recordDiags.HasError() {
 				return recordDiags
 			}
@@ -278,7 +304,7 @@ func resourceRecordRead(ctx context.Context, data *schema.ResourceData, meta int
 		}
 
 		if mode == ncModeOverwrite || mode == ncModeImport {
-			realRecords, realEmailType, unmanagedRecords, recordDiags := readRecordsOverwrite(ctx, domain, records, client)
+			realRecords, realEmailType, unmanagedRecords, recordDiags := readRecordsOverwrite(ctx, domain, records, client, defaultMXPref)
 			if recordDiags.HasError() {
 				return recordDiags
 			}
@@ -308,7 +334,7 @@ func resourceRecordRead(ctx context.Context, data *schema.ResourceData, meta int
 }
 
 func resourceRecordUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 
 	domain := strings.ToLower(data.Get("domain").(string))
 	mode := strings.ToUpper(data.Get("mode").(string))
@@ -336,11 +362,21 @@ func resourceRecordUpdate(ctx context.Context, data *schema.ResourceData, meta i
 	}
 
 	if mode == ncModeMerge {
-		ncMutexKV.Lock(domain)
+		if diags := lockDomain(ctx, domain); diags != nil {
+			return diags
+		}
 		defer ncMutexKV.Unlock(domain)
 	}
 
+	// Checked before any write, so a failed check leaves the domain exactly
+	// as it was.
 	var diags diag.Diagnostics
+	if newNameserversLen != 0 && data.HasChange("nameservers") && data.Get("verify_nameservers").(bool) {
+		targets := nameserverTargetsFromHosts(convertInterfacesToString(newNameservers))
+		if diags = verifyNameservers(ctx, meta.(*providerMeta).config, domain, targets); diags.HasError() {
+			return diags
+		}
+	}
 
 	nsResponse, err := client.DomainsDNS.GetListWithContext(ctx, domain)
 	if err != nil {
@@ -441,7 +477,7 @@ func resourceRecordUpdate(ctx context.Context, data *schema.ResourceData, meta i
 }
 
 func resourceRecordDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 
 	domain := strings.ToLower(data.Get("domain").(string))
 	mode := strings.ToUpper(data.Get("mode").(string))
@@ -461,7 +497,9 @@ func resourceRecordDelete(ctx context.Context, data *schema.ResourceData, meta i
 	nameserversLen := len(nameservers)
 
 	if mode == ncModeMerge {
-		ncMutexKV.Lock(domain)
+		if diags := lockDomain(ctx, domain); diags != nil {
+			return diags
+		}
 		defer ncMutexKV.Unlock(domain)
 	}
 
@@ -29,13 +29,13 @@ func TestReadImportMode_NamecheapDNS_ConvertsModeToMerge(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	resource := resourceNamecheapDomainRecords()
+	resource := resourceNamecheapDomainRecords(nil)
 	data := resource.TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeImport)
 
-	diags := resourceRecordRead(context.TODO(), data, client)
+	diags := resourceRecordRead(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 	assert.Equal(t, ncModeMerge, data.Get("mode").(string))
 }
@@ -53,13 +53,13 @@ func TestReadImportMode_CustomNameservers_ConvertsModeToMerge(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	resource := resourceNamecheapDomainRecords()
+	resource := resourceNamecheapDomainRecords(nil)
 	data := resource.TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeImport)
 
-	diags := resourceRecordRead(context.TODO(), data, client)
+	diags := resourceRecordRead(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 	assert.Equal(t, ncModeMerge, data.Get("mode").(string))
 }
@@ -77,14 +77,14 @@ func TestResourceRecordCreate_MergeWithRecords(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeMerge)
 	_ = data.Set("record", []interface{}{
 		map[string]interface{}{"hostname": "www", "type": "A", "address": "1.2.3.4", "mx_pref": 10, "ttl": 1800},
 	})
 
-	diags := resourceRecordCreate(context.TODO(), data, client)
+	diags := resourceRecordCreate(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 	assert.Equal(t, "test.com", data.Id())
 }
@@ -102,14 +102,14 @@ func TestResourceRecordCreate_OverwriteWithRecords(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeOverwrite)
 	_ = data.Set("record", []interface{}{
 		map[string]interface{}{"hostname": "www", "type": "A", "address": "1.2.3.4", "mx_pref": 10, "ttl": 1800},
 	})
 
-	diags := resourceRecordCreate(context.TODO(), data, client)
+	diags := resourceRecordCreate(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 	assert.Equal(t, "test.com", data.Id())
 }
@@ -127,12 +127,12 @@ func TestResourceRecordCreate_MergeWithNameservers(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeMerge)
 	_ = data.Set("nameservers", []interface{}{"ns1.example.com", "ns2.example.com"})
 
-	diags := resourceRecordCreate(context.TODO(), data, client)
+	diags := resourceRecordCreate(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 	assert.Equal(t, "test.com", data.Id())
 }
@@ -144,12 +144,12 @@ func TestResourceRecordCreate_OverwriteWithNameservers(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeOverwrite)
 	_ = data.Set("nameservers", []interface{}{"ns1.example.com", "ns2.example.com"})
 
-	diags := resourceRecordCreate(context.TODO(), data, client)
+	diags := resourceRecordCreate(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 	assert.Equal(t, "test.com", data.Id())
 }
@@ -161,14 +161,14 @@ func TestResourceRecordCreate_MergeRecordsAPIError(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeMerge)
 	_ = data.Set("record", []interface{}{
 		map[string]interface{}{"hostname": "www", "type": "A", "address": "1.2.3.4", "mx_pref": 10, "ttl": 1800},
 	})
 
-	diags := resourceRecordCreate(context.TODO(), data, client)
+	diags := resourceRecordCreate(context.TODO(), data, testMeta(client))
 	assert.True(t, diags.HasError())
 }
 
@@ -179,14 +179,14 @@ func TestResourceRecordCreate_OverwriteRecordsAPIError(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeOverwrite)
 	_ = data.Set("record", []interface{}{
 		map[string]interface{}{"hostname": "www", "type": "A", "address": "1.2.3.4", "mx_pref": 10, "ttl": 1800},
 	})
 
-	diags := resourceRecordCreate(context.TODO(), data, client)
+	diags := resourceRecordCreate(context.TODO(), data, testMeta(client))
 	assert.True(t, diags.HasError())
 }
 
@@ -204,7 +204,7 @@ func TestResourceRecordCreate_WithEmailType(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeOverwrite)
 	_ = data.Set("email_type", "MX")
@@ -212,7 +212,7 @@ func TestResourceRecordCreate_WithEmailType(t *testing.T) {
 		map[string]interface{}{"hostname": "@", "type": "MX", "address": "mail.test.com.", "mx_pref": 10, "ttl": 1800},
 	})
 
-	diags := resourceRecordCreate(context.TODO(), data, client)
+	diags := resourceRecordCreate(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 }
 
@@ -232,7 +232,7 @@ func TestResourceRecordDelete_MergeWithRecords(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeMerge)
@@ -240,7 +240,7 @@ func TestResourceRecordDelete_MergeWithRecords(t *testing.T) {
 		map[string]interface{}{"hostname": "www", "type": "A", "address": "1.2.3.4", "mx_pref": 10, "ttl": 1800},
 	})
 
-	diags := resourceRecordDelete(context.TODO(), data, client)
+	diags := resourceRecordDelete(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 }
 
@@ -259,7 +259,7 @@ func TestResourceRecordDelete_OverwriteWithRecords(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeOverwrite)
@@ -267,7 +267,7 @@ func TestResourceRecordDelete_OverwriteWithRecords(t *testing.T) {
 		map[string]interface{}{"hostname": "www", "type": "A", "address": "1.2.3.4", "mx_pref": 10, "ttl": 1800},
 	})
 
-	diags := resourceRecordDelete(context.TODO(), data, client)
+	diags := resourceRecordDelete(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 }
 
@@ -284,13 +284,13 @@ func TestResourceRecordDelete_MergeWithNameservers(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeMerge)
 	_ = data.Set("nameservers", []interface{}{"ns1.example.com"})
 
-	diags := resourceRecordDelete(context.TODO(), data, client)
+	diags := resourceRecordDelete(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 }
 
@@ -301,24 +301,24 @@ func TestResourceRecordDelete_OverwriteWithNameservers(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeOverwrite)
 	_ = data.Set("nameservers", []interface{}{"ns1.example.com", "ns2.example.com"})
 
-	diags := resourceRecordDelete(context.TODO(), data, client)
+	diags := resourceRecordDelete(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 }
 
 func TestResourceRecordDelete_NoRecordsNoNameservers(t *testing.T) {
 	client := newTestClient("http://unused")
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeMerge)
 
-	diags := resourceRecordDelete(context.TODO(), data, client)
+	diags := resourceRecordDelete(context.TODO(), data, testMeta(client))
 	assert.Nil(t, diags)
 }
 
@@ -337,7 +337,7 @@ func TestResourceRecordRead_MergeWithRecords(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeMerge)
@@ -345,7 +345,7 @@ func TestResourceRecordRead_MergeWithRecords(t *testing.T) {
 		map[string]interface{}{"hostname": "www", "type": "A", "address": "1.2.3.4", "mx_pref": 10, "ttl": 1800},
 	})
 
-	diags := resourceRecordRead(context.TODO(), data, client)
+	diags := resourceRecordRead(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 	records := data.Get("record").(*schema.Set).List()
 	assert.Len(t, records, 1)
@@ -366,7 +366,7 @@ func TestResourceRecordRead_OverwriteWithRecords(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeOverwrite)
@@ -374,7 +374,7 @@ func TestResourceRecordRead_OverwriteWithRecords(t *testing.T) {
 		map[string]interface{}{"hostname": "www", "type": "A", "address": "1.2.3.4", "mx_pref": 10, "ttl": 1800},
 	})
 
-	diags := resourceRecordRead(context.TODO(), data, client)
+	diags := resourceRecordRead(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 	records := data.Get("record").(*schema.Set).List()
 	assert.Len(t, records, 1)
@@ -396,7 +396,7 @@ func TestResourceRecordRead_OverwriteWarnsAboutUnmanagedRecords(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeOverwrite)
@@ -406,7 +406,7 @@ func TestResourceRecordRead_OverwriteWarnsAboutUnmanagedRecords(t *testing.T) {
 
 	// Refresh (the terraform plan path) must surface the warning even though
 	// there is no error - this is the plan-time visibility #250 asks for.
-	diags := resourceRecordRead(context.TODO(), data, client)
+	diags := resourceRecordRead(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 	if assert.Len(t, diags, 1) {
 		assert.Equal(t, diag.Warning, diags[0].Severity)
@@ -431,7 +431,7 @@ func TestResourceRecordRead_ImportDoesNotWarnAboutUnmanagedRecords(t *testing.T)
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	// Mimic `terraform import`: the importer only sets domain and mode; there
@@ -439,7 +439,7 @@ func TestResourceRecordRead_ImportDoesNotWarnAboutUnmanagedRecords(t *testing.T)
 	// everything it finds, not about to delete it.
 	_ = data.Set("mode", ncModeImport)
 
-	diags := resourceRecordRead(context.TODO(), data, client)
+	diags := resourceRecordRead(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 	assert.Empty(t, diags, "IMPORT must never warn about unmanaged deletion")
 }
@@ -451,13 +451,13 @@ func TestResourceRecordRead_MergeWithCustomNameservers(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeMerge)
 	_ = data.Set("nameservers", []interface{}{"ns1.custom.com", "ns2.custom.com"})
 
-	diags := resourceRecordRead(context.TODO(), data, client)
+	diags := resourceRecordRead(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 }
 
@@ -468,13 +468,13 @@ func TestResourceRecordRead_OverwriteWithCustomNameservers(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeOverwrite)
 	_ = data.Set("nameservers", []interface{}{"ns1.custom.com", "ns2.custom.com"})
 
-	diags := resourceRecordRead(context.TODO(), data, client)
+	diags := resourceRecordRead(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 }
 
@@ -485,12 +485,12 @@ func TestResourceRecordRead_GetListAPIError(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeMerge)
 
-	diags := resourceRecordRead(context.TODO(), data, client)
+	diags := resourceRecordRead(context.TODO(), data, testMeta(client))
 	assert.True(t, diags.HasError())
 }
 
@@ -507,13 +507,13 @@ func TestResourceRecordRead_UsingOurDNSClearsNameservers(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeMerge)
 	_ = data.Set("nameservers", []interface{}{"ns1.old.com", "ns2.old.com"})
 
-	diags := resourceRecordRead(context.TODO(), data, client)
+	diags := resourceRecordRead(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 	ns := data.Get("nameservers").(*schema.Set)
 	assert.Equal(t, 0, ns.Len())
@@ -536,7 +536,7 @@ func TestResourceRecordUpdate_OverwriteWithRecords(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeOverwrite)
@@ -544,7 +544,7 @@ func TestResourceRecordUpdate_OverwriteWithRecords(t *testing.T) {
 		map[string]interface{}{"hostname": "www", "type": "A", "address": "1.2.3.4", "mx_pref": 10, "ttl": 1800},
 	})
 
-	diags := resourceRecordUpdate(context.TODO(), data, client)
+	diags := resourceRecordUpdate(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 }
 
@@ -565,7 +565,7 @@ func TestResourceRecordUpdate_MergeWithRecords(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeMerge)
@@ -573,7 +573,7 @@ func TestResourceRecordUpdate_MergeWithRecords(t *testing.T) {
 		map[string]interface{}{"hostname": "www", "type": "A", "address": "5.6.7.8", "mx_pref": 10, "ttl": 1800},
 	})
 
-	diags := resourceRecordUpdate(context.TODO(), data, client)
+	diags := resourceRecordUpdate(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 }
 
@@ -584,12 +584,12 @@ func TestResourceRecordUpdate_GetListAPIError(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeMerge)
 
-	diags := resourceRecordUpdate(context.TODO(), data, client)
+	diags := resourceRecordUpdate(context.TODO(), data, testMeta(client))
 	assert.True(t, diags.HasError())
 }
 
@@ -600,12 +600,12 @@ func TestResourceRecordUpdate_GetListNilResponse(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeMerge)
 
-	diags := resourceRecordUpdate(context.TODO(), data, client)
+	diags := resourceRecordUpdate(context.TODO(), data, testMeta(client))
 	assert.True(t, diags.HasError())
 }
 
@@ -624,13 +624,13 @@ func TestResourceRecordUpdate_MergeEmailTypeOnly(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeMerge)
 	_ = data.Set("email_type", "FWD")
 
-	diags := resourceRecordUpdate(context.TODO(), data, client)
+	diags := resourceRecordUpdate(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 }
 
@@ -650,12 +650,12 @@ func TestResourceRecordUpdate_OverwriteNoEmailNoRecordsResetsToNone(t *testing.T
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeOverwrite)
 
-	diags := resourceRecordUpdate(context.TODO(), data, client)
+	diags := resourceRecordUpdate(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 }
 
@@ -681,7 +681,7 @@ func TestResourceRecordUpdate_OverwriteResetNameserversBeforeRecords(t *testing.
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeOverwrite)
@@ -689,7 +689,7 @@ func TestResourceRecordUpdate_OverwriteResetNameserversBeforeRecords(t *testing.
 		map[string]interface{}{"hostname": "www", "type": "A", "address": "1.2.3.4", "mx_pref": 10, "ttl": 1800},
 	})
 
-	diags := resourceRecordUpdate(context.TODO(), data, client)
+	diags := resourceRecordUpdate(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 	// Verify setDefault is called before setHosts to reset nameservers
 	defaultIdx := -1
@@ -720,7 +720,7 @@ func TestResourceRecordUpdate_OverwriteRecordsAPIError(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeOverwrite)
@@ -728,7 +728,7 @@ func TestResourceRecordUpdate_OverwriteRecordsAPIError(t *testing.T) {
 		map[string]interface{}{"hostname": "www", "type": "A", "address": "1.2.3.4", "mx_pref": 10, "ttl": 1800},
 	})
 
-	diags := resourceRecordUpdate(context.TODO(), data, client)
+	diags := resourceRecordUpdate(context.TODO(), data, testMeta(client))
 	assert.True(t, diags.HasError())
 }
 
@@ -747,7 +747,7 @@ func TestResourceRecordRead_OverwriteWithEmailType(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeOverwrite)
@@ -759,7 +759,7 @@ func TestResourceRecordRead_OverwriteWithEmailType(t *testing.T) {
 		map[string]interface{}{"hostname": "@", "type": "MX", "address": "mail.test.com", "mx_pref": 10, "ttl": 1800},
 	})
 
-	diags := resourceRecordRead(context.TODO(), data, client)
+	diags := resourceRecordRead(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 	assert.Equal(t, "MX", data.Get("email_type").(string))
 }
@@ -779,7 +779,7 @@ func TestResourceRecordRead_MergeWithEmailType(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	_ = data.Set("mode", ncModeMerge)
@@ -791,7 +791,7 @@ func TestResourceRecordRead_MergeWithEmailType(t *testing.T) {
 		map[string]interface{}{"hostname": "@", "type": "MX", "address": "mail.test.com", "mx_pref": 10, "ttl": 1800},
 	})
 
-	diags := resourceRecordRead(context.TODO(), data, client)
+	diags := resourceRecordRead(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 	assert.Equal(t, "MX", data.Get("email_type").(string))
 }
@@ -811,14 +811,14 @@ func TestResourceRecordRead_ImportWithoutEmailTypeInState(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	data := resourceNamecheapDomainRecords().TestResourceData()
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
 	data.SetId("test.com")
 	_ = data.Set("domain", "test.com")
 	// Mimic `terraform import`: only domain and mode are populated by the
 	// importer; email_type is absent from state.
 	_ = data.Set("mode", ncModeImport)
 
-	diags := resourceRecordRead(context.TODO(), data, client)
+	diags := resourceRecordRead(context.TODO(), data, testMeta(client))
 	assert.False(t, diags.HasError())
 	// Pins current behavior: email_type is refreshed from the remote response
 	// only when it is already present in state, so the import path leaves it
@@ -31,7 +31,7 @@ func TestReadRecordsMerge_FindsMatchingRecords(t *testing.T) {
 		},
 	}
 
-	foundRecords, emailType, diags := readRecordsMerge(context.Background(), "test.com", currentRecords, client)
+	foundRecords, emailType, diags := readRecordsMerge(context.Background(), "test.com", currentRecords, client, defaultRecordMXPref)
 	assert.False(t, diags.HasError())
 	assert.NotNil(t, foundRecords)
 	assert.Len(t, *foundRecords, 1)
@@ -59,7 +59,7 @@ func TestReadRecordsMerge_NoMatchingRecords(t *testing.T) {
 		},
 	}
 
-	foundRecords, _, diags := readRecordsMerge(context.Background(), "test.com", currentRecords, client)
+	foundRecords, _, diags := readRecordsMerge(context.Background(), "test.com", currentRecords, client, defaultRecordMXPref)
 	assert.False(t, diags.HasError())
 	assert.NotNil(t, foundRecords)
 	assert.Len(t, *foundRecords, 0)
@@ -85,7 +85,7 @@ func TestReadRecordsMerge_CNAMEWithDotFix(t *testing.T) {
 		},
 	}
 
-	foundRecords, _, diags := readRecordsMerge(context.Background(), "test.com", currentRecords, client)
+	foundRecords, _, diags := readRecordsMerge(context.Background(), "test.com", currentRecords, client, defaultRecordMXPref)
 	assert.False(t, diags.HasError())
 	assert.NotNil(t, foundRecords)
 	assert.Len(t, *foundRecords, 1)
@@ -110,7 +110,7 @@ func TestReadRecordsMerge_EmptyRemote(t *testing.T) {
 		},
 	}
 
-	foundRecords, _, diags := readRecordsMerge(context.Background(), "test.com", currentRecords, client)
+	foundRecords, _, diags := readRecordsMerge(context.Background(), "test.com", currentRecords, client, defaultRecordMXPref)
 	assert.False(t, diags.HasError())
 	assert.NotNil(t, foundRecords)
 	assert.Len(t, *foundRecords, 0)
@@ -136,7 +136,7 @@ func TestReadRecordsOverwrite_ReturnsAllRecords(t *testing.T) {
 		},
 	}
 
-	foundRecords, emailType, unmanaged, diags := readRecordsOverwrite(context.Background(), "test.com", currentRecords, client)
+	foundRecords, emailType, unmanaged, diags := readRecordsOverwrite(context.Background(), "test.com", currentRecords, client, defaultRecordMXPref)
 	assert.False(t, diags.HasError())
 	assert.NotNil(t, foundRecords)
 	assert.Len(t, *foundRecords, 2)
@@ -152,7 +152,7 @@ func TestReadRecordsOverwrite_EmptyRemote(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	foundRecords, _, unmanaged, diags := readRecordsOverwrite(context.Background(), "test.com", []interface{}{}, client)
+	foundRecords, _, unmanaged, diags := readRecordsOverwrite(context.Background(), "test.com", []interface{}{}, client, defaultRecordMXPref)
 	assert.False(t, diags.HasError())
 	assert.NotNil(t, foundRecords)
 	assert.Len(t, *foundRecords, 0)
@@ -179,7 +179,7 @@ func TestReadRecordsOverwrite_CNAMEDotFix(t *testing.T) {
 		},
 	}
 
-	foundRecords, _, unmanaged, diags := readRecordsOverwrite(context.Background(), "test.com", currentRecords, client)
+	foundRecords, _, unmanaged, diags := readRecordsOverwrite(context.Background(), "test.com", currentRecords, client, defaultRecordMXPref)
 	assert.False(t, diags.HasError())
 	assert.NotNil(t, foundRecords)
 	assert.Len(t, *foundRecords, 1)
@@ -201,7 +201,7 @@ func TestReadRecordsOverwrite_FiltersDefaultParkingRecords(t *testing.T) {
 
 	// With no records configured, OVERWRITE read must still drop Namecheap's
 	// default parking records so they don't surface as spurious drift (issue #260).
-	foundRecords, _, unmanaged, diags := readRecordsOverwrite(context.Background(), "test.com", []interface{}{}, client)
+	foundRecords, _, unmanaged, diags := readRecordsOverwrite(context.Background(), "test.com", []interface{}{}, client, defaultRecordMXPref)
 	assert.False(t, diags.HasError())
 	assert.NotNil(t, foundRecords)
 	assert.Len(t, *foundRecords, 1)
@@ -233,7 +233,7 @@ func TestReadRecordsOverwrite_KeepsUserManagedParkingRecord(t *testing.T) {
 		map[string]interface{}{"hostname": "@", "type": "URL", "address": "http://www.test.com", "mx_pref": 10, "ttl": 1800},
 	}
 
-	foundRecords, _, unmanaged, diags := readRecordsOverwrite(context.Background(), "test.com", currentRecords, client)
+	foundRecords, _, unmanaged, diags := readRecordsOverwrite(context.Background(), "test.com", currentRecords, client, defaultRecordMXPref)
 	assert.False(t, diags.HasError())
 	assert.NotNil(t, foundRecords)
 	assert.Len(t, *foundRecords, 2)
@@ -258,7 +258,7 @@ func TestReadRecordsOverwrite_GetHostsAPIError(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	result, _, unmanaged, diags := readRecordsOverwrite(context.Background(), "test.com", []interface{}{}, client)
+	result, _, unmanaged, diags := readRecordsOverwrite(context.Background(), "test.com", []interface{}{}, client, defaultRecordMXPref)
 	assert.Nil(t, result)
 	assert.Nil(t, unmanaged)
 	assert.True(t, diags.HasError())
@@ -272,7 +272,7 @@ func TestReadRecordsMerge_APIError(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server.URL)
-	result, _, diags := readRecordsMerge(context.Background(), "test.com", []interface{}{}, client)
+	result, _, diags := readRecordsMerge(context.Background(), "test.com", []interface{}{}, client, defaultRecordMXPref)
 	assert.Nil(t, result)
 	assert.True(t, diags.HasError())
 }
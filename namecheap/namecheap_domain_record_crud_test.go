@@ -20,6 +20,11 @@ func newTestClient(baseURL string) *namecheap.Client {
 	return client
 }
 
+// testMeta wraps client as the provider meta of an empty provider block.
+func testMeta(client *namecheap.Client) *providerMeta {
+	return &providerMeta{client: client, config: defaultProviderConfig()}
+}
+
 // getHostsXML generates a GetHosts API response XML
 func getHostsXML(emailType string, hosts []hostEntry) string {
 	var hostLines []string
@@ -379,7 +379,7 @@ func createRecordsOverwrite(ctx context.Context, domain string, emailType *strin
 
 // readRecordsMerge reads all remote records, return only the currentRecords that are exist in remote records
 // NOTE: method has address fix. Refer to getFixedAddressOfRecord
-func readRecordsMerge(ctx context.Context, domain string, currentRecords []interface{}, client *namecheap.Client) (*[]map[string]interface{}, *string, diag.Diagnostics) {
+func readRecordsMerge(ctx context.Context, domain string, currentRecords []interface{}, client *namecheap.Client, defaultMXPref int) (*[]map[string]interface{}, *string, diag.Diagnostics) {
 	remoteRecordsResponse, err := client.DomainsDNS.GetHostsWithContext(ctx, domain)
 	if err != nil {
 		return nil, nil, diagFromClientError(err)
@@ -405,6 +405,7 @@ func readRecordsMerge(ctx context.Context, domain string, currentRecords []inter
 				remoteRecordHash := hashRecord(*remoteRecord.Name, *remoteRecord.Type, *remoteRecord.Address)
 				if currentRecordHash == remoteRecordHash {
 					remoteRecord.Address = currentRecord.Address
+					remoteRecord.MXPref = stateMXPref(&remoteRecord, currentRecord.MXPref, defaultMXPref)
 					foundRecords = append(foundRecords, *convertDomainRecordDetailedToTypeSetRecord(&remoteRecord))
 					break
 				}
@@ -420,7 +421,7 @@ func readRecordsMerge(ctx context.Context, domain string, currentRecords []inter
 // currentRecords and not a default parking record) - i.e. what OVERWRITE
 // mode would delete on the next apply (#65, #250).
 // NOTE: method has address fix. Refer to getFixedAddressOfRecord
-func readRecordsOverwrite(ctx context.Context, domain string, currentRecords []interface{}, client *namecheap.Client) (*[]map[string]interface{}, *string, []namecheap.DomainsDNSHostRecordDetailed, diag.Diagnostics) {
+func readRecordsOverwrite(ctx context.Context, domain string, currentRecords []interface{}, client *namecheap.Client, defaultMXPref int) (*[]map[string]interface{}, *string, []namecheap.DomainsDNSHostRecordDetailed, diag.Diagnostics) {
 	remoteRecordsResponse, err := client.DomainsDNS.GetHostsWithContext(ctx, domain)
 	if err != nil {
 		return nil, nil, nil, diagFromClientError(err)
@@ -440,6 +441,7 @@ func readRecordsOverwrite(ctx context.Context, domain string, currentRecords []i
 			remoteRecordHash := hashRecord(*remoteRecord.Name, *remoteRecord.Type, *remoteRecord.Address)
 
 			managed := false
+			var configuredMXPref *uint8
 			for _, currentRecord := range *currentRecordsConverted {
 				currentRecordAddressFixed, err := getFixedAddressOfRecord(&currentRecord)
 				if err != nil {
@@ -450,6 +452,7 @@ func readRecordsOverwrite(ctx context.Context, domain string, currentRecords []i
 
 				if currentRecordHash == remoteRecordHash {
 					*remoteRecord.Address = *currentRecord.Address
+					configuredMXPref = currentRecord.MXPref
 					managed = true
 					break
 				}
@@ -467,7 +470,9 @@ func readRecordsOverwrite(ctx context.Context, domain string, currentRecords []i
 				unmanagedRecords = append(unmanagedRecords, remoteRecord)
 			}
 
-			remoteRecords = append(remoteRecords, *convertDomainRecordDetailedToTypeSetRecord(&remoteRecord))
+			stateRecord := remoteRecord
+			stateRecord.MXPref = stateMXPref(&remoteRecord, configuredMXPref, defaultMXPref)
+			remoteRecords = append(remoteRecords, *convertDomainRecordDetailedToTypeSetRecord(&stateRecord))
 		}
 	}
 
@@ -727,6 +732,23 @@ func convertDomainRecordDetailedToTypeSetRecord(record *namecheap.DomainsDNSHost
 	}
 }
 
+// stateMXPref returns the MX preference to store for a live record. Only MX
+// records have one; for every other type Namecheap reports a fixed 10 whatever
+// was sent, so the configured value is kept instead — or, for a record the
+// configuration does not hold (an import, an unmanaged OVERWRITE record), the
+// provider's defaults.mx_pref (defaultMXPref), which is what a config omitting
+// it would plan.
+// Storing the API's 10 would diff forever against any other default.
+func stateMXPref(record *namecheap.DomainsDNSHostRecordDetailed, configured *uint8, defaultMXPref int) *int {
+	if record.Type == nil || hostRecordMXPrefIsIdentity(*record.Type) {
+		return record.MXPref
+	}
+	if configured != nil {
+		return namecheap.Int(int(*configured))
+	}
+	return namecheap.Int(defaultMXPref)
+}
+
 func convertInterfacesToString(stringsRaw []interface{}) []string {
 	var stringList []string
 	for _, stringRaw := range stringsRaw {
@@ -359,7 +359,7 @@ func TestConvertRecordTypeSetToDomainRecords(t *testing.T) {
 }
 
 func TestDomainValidateFunc(t *testing.T) {
-	resource := resourceNamecheapDomainRecords()
+	resource := resourceNamecheapDomainRecords(nil)
 	validateFunc := resource.Schema["domain"].ValidateFunc
 
 	t.Run("valid_root_domain", func(t *testing.T) {
@@ -0,0 +1,316 @@
+package namecheap_provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+)
+
+// emailForwardIDSeparator joins the two components of a namecheap_email_forward
+// ID: domain/mailbox.
+const emailForwardIDSeparator = "/"
+
+// resourceNamecheapEmailForward manages a single forwarding rule, the pair of
+// mailbox and forward_to, leaving every other rule on the domain untouched -
+// including other destinations of the same mailbox, which a mailbox can have
+// several of.
+//
+// This is the per-alias counterpart to namecheap_email_forwarding, which owns a
+// domain's whole forwarding table, in the same way namecheap_domain_host_record
+// is to namecheap_domain_records. The two are mutually exclusive per domain.
+//
+// setEmailForwarding replaces the entire table, so every change here is a
+// read-modify-write: the table is read, this resource's rule is swapped out and
+// the result is written back. Changes to one domain are serialized through
+// ncMutexKV within a Terraform run. Across runs nothing guards the window
+// between the read and the write: a rule written by someone else in between is
+// overwritten. Unlike setHosts there is no SDK verify-and-retry to narrow it.
+func resourceNamecheapEmailForward() *schema.Resource {
+	return &schema.Resource{
+		Description: "Manages a single email forwarding alias on a domain, leaving all other forwarding rules untouched. Mutually exclusive with namecheap_email_forwarding for the same domain.",
+
+		CreateContext: resourceNamecheapEmailForwardCreate,
+		ReadContext:   resourceNamecheapEmailForwardRead,
+		UpdateContext: resourceNamecheapEmailForwardUpdate,
+		DeleteContext: resourceNamecheapEmailForwardDelete,
+
+		Timeouts: resourceTimeouts(),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceNamecheapEmailForwardImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "The registered root domain the alias belongs to (e.g. `example.com`). Must be a root domain, not a subdomain. Changing this forces a new resource.",
+				ValidateFunc: validateDomainIsNotSubdomain,
+			},
+			"mailbox": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "The mailbox alias, a lowercase local part with no `@` (e.g. `info`), or `*` for a catch-all. Changing this forces a new resource.",
+				ValidateFunc: validateForwardMailbox,
+			},
+			"forward_to": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "The address mail to the alias is forwarded to. Changing this rewrites the alias's rule in place.",
+				ValidateFunc: validateForwardDestination,
+			},
+		},
+	}
+}
+
+// validateForwardMailbox is the schema form of isValidForwardMailbox.
+func validateForwardMailbox(val interface{}, key string) (warns []string, errs []error) {
+	if v := val.(string); !isValidForwardMailbox(v) {
+		errs = append(errs, fmt.Errorf("%q must be a non-empty, lowercase local alias (e.g. \"info\", or \"*\" for a catch-all) with no \"@\" or whitespace, got %q", key, v))
+	}
+	return
+}
+
+// validateForwardDestination is the schema form of isPlausibleEmailAddress.
+func validateForwardDestination(val interface{}, key string) (warns []string, errs []error) {
+	if v := val.(string); !isPlausibleEmailAddress(v) {
+		errs = append(errs, fmt.Errorf("%q must look like an email address (exactly one \"@\" with a non-empty local and domain part), got %q", key, v))
+	}
+	return
+}
+
+// emailForwardID renders the resource ID, which doubles as the import ID.
+func emailForwardID(domain, mailbox string) string {
+	return strings.ToLower(domain) + emailForwardIDSeparator + strings.ToLower(mailbox)
+}
+
+// emailForwardTable reads domain's live forwarding table. A response without a
+// result is an empty table, as in resourceEmailForwardingRead.
+func emailForwardTable(ctx context.Context, client *namecheap.Client, domain string) ([]namecheap.EmailForward, error) {
+	resp, err := client.DomainsDNS.GetEmailForwardingWithContext(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil || resp.DomainDNSGetEmailForwardingResult == nil || resp.DomainDNSGetEmailForwardingResult.Forwards == nil {
+		return nil, nil
+	}
+	return *resp.DomainDNSGetEmailForwardingResult.Forwards, nil
+}
+
+// emailForwardDestinations returns the destinations table holds for mailbox.
+// Aliases are case-insensitive, so a rule created in the dashboard as "Info"
+// belongs to mailbox "info".
+func emailForwardDestinations(table []namecheap.EmailForward, mailbox string) []string {
+	var destinations []string
+	for _, fwd := range table {
+		if strings.EqualFold(fwd.Mailbox, mailbox) {
+			destinations = append(destinations, fwd.ForwardTo)
+		}
+	}
+	return destinations
+}
+
+// emailForwardReplace returns table with the rule forwarding mailbox to from
+// removed and, when to is set, one forwarding it to to appended unless the
+// table already holds it. Every other rule, other destinations of mailbox
+// included, is kept as read, in its original order.
+func emailForwardReplace(table []namecheap.EmailForward, mailbox, from, to string) []namecheap.EmailForward {
+	result := make([]namecheap.EmailForward, 0, len(table)+1)
+	held := false
+	for _, fwd := range table {
+		if !strings.EqualFold(fwd.Mailbox, mailbox) {
+			result = append(result, fwd)
+			continue
+		}
+		if from != "" && strings.EqualFold(fwd.ForwardTo, from) {
+			continue
+		}
+		held = held || strings.EqualFold(fwd.ForwardTo, to)
+		result = append(result, fwd)
+	}
+	if to != "" && !held {
+		result = append(result, namecheap.EmailForward{Mailbox: mailbox, ForwardTo: to})
+	}
+	return result
+}
+
+// emailForwardHolds reports whether destinations include forwardTo.
+func emailForwardHolds(destinations []string, forwardTo string) bool {
+	return slices.ContainsFunc(destinations, func(dest string) bool { return strings.EqualFold(dest, forwardTo) })
+}
+
+func resourceNamecheapEmailForwardCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	domain := strings.ToLower(data.Get("domain").(string))
+	mailbox := data.Get("mailbox").(string)
+
+	if diags := lockDomain(ctx, domain); diags != nil {
+		return diags
+	}
+	defer ncMutexKV.Unlock(domain)
+
+	table, err := emailForwardTable(ctx, client, domain)
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	// Taking over an alias someone else created would silently repoint it;
+	// import is the way to take ownership of it.
+	if existing := emailForwardDestinations(table, mailbox); len(existing) > 0 {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Email forwarding alias already exists on %s", domain),
+			Detail: fmt.Sprintf("%s@%s already forwards to %s. Import it instead of creating it:\n\n"+
+				"  terraform import <resource address> %s",
+				mailbox, domain, strings.Join(existing, ", "), emailForwardID(domain, mailbox)),
+		}}
+	}
+
+	table = emailForwardReplace(table, mailbox, "", data.Get("forward_to").(string))
+	if _, err := client.DomainsDNS.SetEmailForwardingWithContext(ctx, domain, table); err != nil {
+		return diagFromClientError(err)
+	}
+
+	data.SetId(emailForwardID(domain, mailbox))
+	return checkEmailForwardingConflict(ctx, domain, client)
+}
+
+func resourceNamecheapEmailForwardRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	domain := strings.ToLower(data.Get("domain").(string))
+	mailbox := data.Get("mailbox").(string)
+
+	table, err := emailForwardTable(ctx, client, domain)
+	if err != nil {
+		if isDomainGoneError(err) {
+			data.SetId("")
+			return nil
+		}
+		return diagFromClientError(err)
+	}
+
+	destinations := emailForwardDestinations(table, mailbox)
+	if len(destinations) == 0 {
+		// Removed outside Terraform: drop it from state so the next plan offers
+		// to recreate it.
+		data.SetId("")
+		return nil
+	}
+
+	// A mailbox can carry several rules, and only the one forwarding to
+	// forward_to is this resource's. When it is gone and the mailbox has a
+	// single other destination, the rule was most likely edited outside
+	// Terraform, and that destination is reported as drift. When it has
+	// several, none of them is claimed: forward_to is emptied, so the plan
+	// shows the rule being added back beside them.
+	switch current := data.Get("forward_to").(string); {
+	case emailForwardHolds(destinations, current):
+	case len(destinations) == 1:
+		_ = data.Set("forward_to", destinations[0])
+	default:
+		_ = data.Set("forward_to", "")
+	}
+
+	data.SetId(emailForwardID(domain, mailbox))
+	return nil
+}
+
+func resourceNamecheapEmailForwardUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	domain := strings.ToLower(data.Get("domain").(string))
+	mailbox := data.Get("mailbox").(string)
+
+	// SDKv2 persists the planned forward_to when an update fails, which would
+	// leave state describing a rule the table does not hold; put the old value
+	// back on every failure path.
+	restore := func(diags diag.Diagnostics) diag.Diagnostics {
+		before, _ := data.GetChange("forward_to")
+		_ = data.Set("forward_to", before)
+		return diags
+	}
+
+	if diags := lockDomain(ctx, domain); diags != nil {
+		return restore(diags)
+	}
+	defer ncMutexKV.Unlock(domain)
+
+	table, err := emailForwardTable(ctx, client, domain)
+	if err != nil {
+		return restore(diagFromClientError(err))
+	}
+
+	before, after := data.GetChange("forward_to")
+	table = emailForwardReplace(table, mailbox, before.(string), after.(string))
+	if _, err := client.DomainsDNS.SetEmailForwardingWithContext(ctx, domain, table); err != nil {
+		return restore(diagFromClientError(err))
+	}
+
+	return checkEmailForwardingConflict(ctx, domain, client)
+}
+
+func resourceNamecheapEmailForwardDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	domain := strings.ToLower(data.Get("domain").(string))
+	mailbox := data.Get("mailbox").(string)
+
+	if diags := lockDomain(ctx, domain); diags != nil {
+		return diags
+	}
+	defer ncMutexKV.Unlock(domain)
+
+	table, err := emailForwardTable(ctx, client, domain)
+	if err != nil {
+		if isDomainGoneError(err) {
+			return nil
+		}
+		return diagFromClientError(err)
+	}
+
+	// Skip the write when the rule is already gone: rewriting the table to
+	// remove nothing only widens the window for losing someone else's change.
+	forwardTo := data.Get("forward_to").(string)
+	if !emailForwardHolds(emailForwardDestinations(table, mailbox), forwardTo) {
+		return nil
+	}
+
+	if _, err := client.DomainsDNS.SetEmailForwardingWithContext(ctx, domain, emailForwardReplace(table, mailbox, forwardTo, "")); err != nil {
+		if isDomainGoneError(err) {
+			return nil
+		}
+		return diagFromClientError(err)
+	}
+	return nil
+}
+
+func resourceNamecheapEmailForwardImport(ctx context.Context, data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	domain, mailbox, ok := strings.Cut(data.Id(), emailForwardIDSeparator)
+	if !ok || strings.TrimSpace(domain) == "" || strings.TrimSpace(mailbox) == "" {
+		return nil, fmt.Errorf("invalid import ID %q: expected %q, e.g. %q",
+			data.Id(), "<domain>/<mailbox>", "example.com/info")
+	}
+	domain, mailbox = strings.ToLower(domain), strings.ToLower(mailbox)
+
+	client := meta.(*providerMeta).client
+	table, err := emailForwardTable(ctx, client, domain)
+	if err != nil {
+		return nil, err
+	}
+	destinations := emailForwardDestinations(table, mailbox)
+	if len(destinations) == 0 {
+		return nil, fmt.Errorf("no email forwarding alias %q exists on %s", mailbox, domain)
+	}
+
+	_ = data.Set("domain", domain)
+	_ = data.Set("mailbox", mailbox)
+	_ = data.Set("forward_to", destinations[0])
+	data.SetId(emailForwardID(domain, mailbox))
+
+	return []*schema.ResourceData{data}, nil
+}
@@ -0,0 +1,382 @@
+package namecheap_provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func emailForwardTestData(t *testing.T, mailbox, forwardTo string) *schema.ResourceData {
+	t.Helper()
+	return schema.TestResourceDataRaw(t, resourceNamecheapEmailForward().Schema, map[string]interface{}{
+		"domain":     "example.com",
+		"mailbox":    mailbox,
+		"forward_to": forwardTo,
+	})
+}
+
+// emailForwardTestServer serves table for getEmailForwarding, FWD for getHosts
+// and records each setEmailForwarding request into *sets.
+func emailForwardTestServer(t *testing.T, table map[string]string, sets *[]url.Values) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		switch r.FormValue("Command") {
+		case "namecheap.domains.dns.getEmailForwarding":
+			_, _ = fmt.Fprint(w, getEmailForwardingXML("example.com", table))
+		case "namecheap.domains.dns.setEmailForwarding":
+			*sets = append(*sets, r.Form)
+			_, _ = fmt.Fprint(w, setEmailForwardingSuccessXML("example.com"))
+		case "namecheap.domains.dns.getHosts":
+			_, _ = fmt.Fprint(w, getHostsXML("FWD", nil))
+		default:
+			t.Fatalf("unexpected command: %s", r.FormValue("Command"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// sentForwards collects the mailboxN/ForwardToN pairs of a setEmailForwarding
+// request.
+func sentForwards(form url.Values) map[string]string {
+	forwards := map[string]string{}
+	for i := 1; form.Get(fmt.Sprintf("mailbox%d", i)) != ""; i++ {
+		forwards[form.Get(fmt.Sprintf("mailbox%d", i))] = form.Get(fmt.Sprintf("ForwardTo%d", i))
+	}
+	return forwards
+}
+
+func TestEmailForwardReplace(t *testing.T) {
+	table := []namecheap.EmailForward{
+		{Mailbox: "sales", ForwardTo: "sales@example.com"},
+		{Mailbox: "Info", ForwardTo: "old@example.com"},
+		{Mailbox: "info", ForwardTo: "older@example.com"},
+		{Mailbox: "*", ForwardTo: "catchall@example.com"},
+	}
+
+	assert.Equal(t, []namecheap.EmailForward{
+		{Mailbox: "sales", ForwardTo: "sales@example.com"},
+		{Mailbox: "info", ForwardTo: "older@example.com"},
+		{Mailbox: "*", ForwardTo: "catchall@example.com"},
+		{Mailbox: "info", ForwardTo: "new@example.com"},
+	}, emailForwardReplace(table, "info", "OLD@example.com", "new@example.com"), "only the owned rule is replaced, whatever its case")
+
+	assert.Equal(t, []namecheap.EmailForward{
+		{Mailbox: "sales", ForwardTo: "sales@example.com"},
+		{Mailbox: "Info", ForwardTo: "old@example.com"},
+		{Mailbox: "*", ForwardTo: "catchall@example.com"},
+	}, emailForwardReplace(table, "info", "older@example.com", ""))
+
+	assert.Equal(t, table, emailForwardReplace(table, "info", "", "older@example.com"), "a rule already held is not added twice")
+}
+
+func TestValidateForwardMailboxAndDestination(t *testing.T) {
+	for _, mailbox := range []string{"info", "*", "first.last"} {
+		_, errs := validateForwardMailbox(mailbox, "mailbox")
+		assert.Empty(t, errs, mailbox)
+	}
+	for _, mailbox := range []string{"", "Info", "info@example.com", "in fo"} {
+		_, errs := validateForwardMailbox(mailbox, "mailbox")
+		assert.Len(t, errs, 1, mailbox)
+	}
+
+	_, errs := validateForwardDestination("me@example.com", "forward_to")
+	assert.Empty(t, errs)
+	_, errs = validateForwardDestination("me@", "forward_to")
+	assert.Len(t, errs, 1)
+}
+
+func TestResourceEmailForwardCreate_LeavesOtherAliases(t *testing.T) {
+	var sets []url.Values
+	server := emailForwardTestServer(t, map[string]string{"sales": "sales@example.com"}, &sets)
+
+	d := emailForwardTestData(t, "info", "me@example.com")
+	diags := resourceNamecheapEmailForwardCreate(context.Background(), d, testMeta(newTestClient(server.URL)))
+
+	assert.Empty(t, diags)
+	assert.Equal(t, "example.com/info", d.Id())
+	if assert.Len(t, sets, 1) {
+		assert.Equal(t, map[string]string{
+			"sales": "sales@example.com",
+			"info":  "me@example.com",
+		}, sentForwards(sets[0]))
+	}
+}
+
+func TestResourceEmailForwardCreate_ExistingAliasRefused(t *testing.T) {
+	var sets []url.Values
+	server := emailForwardTestServer(t, map[string]string{"Info": "someone@example.com"}, &sets)
+
+	d := emailForwardTestData(t, "info", "me@example.com")
+	diags := resourceNamecheapEmailForwardCreate(context.Background(), d, testMeta(newTestClient(server.URL)))
+
+	if assert.True(t, diags.HasError()) {
+		assert.Contains(t, diags[0].Summary, "already exists")
+		assert.Contains(t, diags[0].Detail, "terraform import <resource address> example.com/info")
+	}
+	assert.Empty(t, sets, "an existing alias must not be overwritten")
+	assert.Empty(t, d.Id())
+}
+
+func TestResourceEmailForwardRead(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		var sets []url.Values
+		server := emailForwardTestServer(t, map[string]string{"info": "changed@example.com", "sales": "sales@example.com"}, &sets)
+
+		d := emailForwardTestData(t, "info", "me@example.com")
+		d.SetId("example.com/info")
+		diags := resourceNamecheapEmailForwardRead(context.Background(), d, testMeta(newTestClient(server.URL)))
+
+		assert.Empty(t, diags)
+		assert.Equal(t, "changed@example.com", d.Get("forward_to"), "a destination changed outside Terraform is drift")
+	})
+
+	t.Run("removed", func(t *testing.T) {
+		var sets []url.Values
+		server := emailForwardTestServer(t, map[string]string{"sales": "sales@example.com"}, &sets)
+
+		d := emailForwardTestData(t, "info", "me@example.com")
+		d.SetId("example.com/info")
+		diags := resourceNamecheapEmailForwardRead(context.Background(), d, testMeta(newTestClient(server.URL)))
+
+		assert.Empty(t, diags)
+		assert.Empty(t, d.Id())
+	})
+
+	t.Run("domain_gone", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprint(w, apiErrorXML("2019166", "Domain not found"))
+		}))
+		defer server.Close()
+
+		d := emailForwardTestData(t, "info", "me@example.com")
+		d.SetId("example.com/info")
+		diags := resourceNamecheapEmailForwardRead(context.Background(), d, testMeta(newTestClient(server.URL)))
+
+		assert.Empty(t, diags)
+		assert.Empty(t, d.Id())
+	})
+}
+
+// emailForwardUpdateData plans a change of forward_to from one destination to
+// another, for an update.
+func emailForwardUpdateData(t *testing.T, from, to string) *schema.ResourceData {
+	t.Helper()
+	r := resourceNamecheapEmailForward()
+	prior := emailForwardTestData(t, "info", from)
+	prior.SetId("example.com/info")
+	diff, err := schema.InternalMap(r.Schema).Diff(context.Background(), prior.State(),
+		terraform.NewResourceConfigRaw(map[string]interface{}{
+			"domain":     "example.com",
+			"mailbox":    "info",
+			"forward_to": to,
+		}), nil, nil, true)
+	require.NoError(t, err)
+	d, err := schema.InternalMap(r.Schema).Data(prior.State(), diff)
+	require.NoError(t, err)
+	return d
+}
+
+// sentForwardRules lists the mailboxN=ForwardToN rules of a setEmailForwarding
+// request in order, keeping several destinations of one mailbox apart.
+func sentForwardRules(form url.Values) []string {
+	var rules []string
+	for i := 1; form.Get(fmt.Sprintf("mailbox%d", i)) != ""; i++ {
+		rules = append(rules, form.Get(fmt.Sprintf("mailbox%d", i))+"="+form.Get(fmt.Sprintf("ForwardTo%d", i)))
+	}
+	return rules
+}
+
+// emailForwardTwoDestinationsServer serves a table where info forwards to
+// me@example.com and to other@example.com, recording setEmailForwarding
+// requests into *sets.
+func emailForwardTwoDestinationsServer(t *testing.T, sets *[]url.Values) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		switch r.FormValue("Command") {
+		case "namecheap.domains.dns.getEmailForwarding":
+			_, _ = fmt.Fprint(w, strings.Replace(getEmailForwardingXML("example.com", map[string]string{"info": "me@example.com"}),
+				`ForwardTo="me@example.com" />`, `ForwardTo="me@example.com" /><Forward mailbox="info" ForwardTo="other@example.com" />`, 1))
+		case "namecheap.domains.dns.setEmailForwarding":
+			*sets = append(*sets, r.Form)
+			_, _ = fmt.Fprint(w, setEmailForwardingSuccessXML("example.com"))
+		case "namecheap.domains.dns.getHosts":
+			_, _ = fmt.Fprint(w, getHostsXML("FWD", nil))
+		default:
+			t.Fatalf("unexpected command: %s", r.FormValue("Command"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestResourceEmailForward_OtherDestinationSurvives(t *testing.T) {
+	t.Run("update", func(t *testing.T) {
+		var sets []url.Values
+		server := emailForwardTwoDestinationsServer(t, &sets)
+
+		d := emailForwardUpdateData(t, "me@example.com", "new@example.com")
+		diags := resourceNamecheapEmailForwardUpdate(context.Background(), d, testMeta(newTestClient(server.URL)))
+
+		assert.Empty(t, diags)
+		if assert.Len(t, sets, 1) {
+			assert.Equal(t, []string{"info=other@example.com", "info=new@example.com"}, sentForwardRules(sets[0]))
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		var sets []url.Values
+		server := emailForwardTwoDestinationsServer(t, &sets)
+
+		d := emailForwardTestData(t, "info", "me@example.com")
+		d.SetId("example.com/info")
+		diags := resourceNamecheapEmailForwardDelete(context.Background(), d, testMeta(newTestClient(server.URL)))
+
+		assert.Empty(t, diags)
+		if assert.Len(t, sets, 1) {
+			assert.Equal(t, []string{"info=other@example.com"}, sentForwardRules(sets[0]))
+		}
+	})
+
+	t.Run("read", func(t *testing.T) {
+		var sets []url.Values
+		server := emailForwardTwoDestinationsServer(t, &sets)
+
+		d := emailForwardTestData(t, "info", "me@example.com")
+		d.SetId("example.com/info")
+		assert.Empty(t, resourceNamecheapEmailForwardRead(context.Background(), d, testMeta(newTestClient(server.URL))))
+		assert.Equal(t, "me@example.com", d.Get("forward_to"), "another destination is not drift")
+
+		d = emailForwardTestData(t, "info", "gone@example.com")
+		d.SetId("example.com/info")
+		assert.Empty(t, resourceNamecheapEmailForwardRead(context.Background(), d, testMeta(newTestClient(server.URL))))
+		assert.Equal(t, "", d.Get("forward_to"), "neither remaining destination is claimed")
+	})
+}
+
+func TestResourceEmailForwardUpdate(t *testing.T) {
+	var sets []url.Values
+	server := emailForwardTestServer(t, map[string]string{"info": "me@example.com", "sales": "sales@example.com"}, &sets)
+
+	d := emailForwardUpdateData(t, "me@example.com", "new@example.com")
+	diags := resourceNamecheapEmailForwardUpdate(context.Background(), d, testMeta(newTestClient(server.URL)))
+
+	assert.Empty(t, diags)
+	if assert.Len(t, sets, 1) {
+		assert.Equal(t, map[string]string{
+			"sales": "sales@example.com",
+			"info":  "new@example.com",
+		}, sentForwards(sets[0]))
+	}
+}
+
+func TestResourceEmailForwardUpdate_FailureRestoresState(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.FormValue("Command") == "namecheap.domains.dns.getEmailForwarding" {
+			_, _ = fmt.Fprint(w, getEmailForwardingXML("example.com", map[string]string{"info": "me@example.com"}))
+			return
+		}
+		_, _ = fmt.Fprint(w, apiErrorXML("99999", "Some other error"))
+	}))
+	defer server.Close()
+
+	d := emailForwardUpdateData(t, "me@example.com", "new@example.com")
+	diags := resourceNamecheapEmailForwardUpdate(context.Background(), d, testMeta(newTestClient(server.URL)))
+
+	assert.True(t, diags.HasError())
+	assert.Equal(t, "me@example.com", d.Get("forward_to"), "a failed update must leave the previous destination in state")
+}
+
+func TestResourceEmailForwardDelete(t *testing.T) {
+	t.Run("removes_only_its_alias", func(t *testing.T) {
+		var sets []url.Values
+		server := emailForwardTestServer(t, map[string]string{"info": "me@example.com", "sales": "sales@example.com"}, &sets)
+
+		d := emailForwardTestData(t, "info", "me@example.com")
+		d.SetId("example.com/info")
+		diags := resourceNamecheapEmailForwardDelete(context.Background(), d, testMeta(newTestClient(server.URL)))
+
+		assert.Empty(t, diags)
+		if assert.Len(t, sets, 1) {
+			assert.Equal(t, map[string]string{"sales": "sales@example.com"}, sentForwards(sets[0]))
+		}
+	})
+
+	t.Run("already_gone_skips_write", func(t *testing.T) {
+		var sets []url.Values
+		server := emailForwardTestServer(t, map[string]string{"sales": "sales@example.com"}, &sets)
+
+		d := emailForwardTestData(t, "info", "me@example.com")
+		d.SetId("example.com/info")
+		diags := resourceNamecheapEmailForwardDelete(context.Background(), d, testMeta(newTestClient(server.URL)))
+
+		assert.Empty(t, diags)
+		assert.Empty(t, sets)
+	})
+}
+
+func TestResourceEmailForwardImport(t *testing.T) {
+	var sets []url.Values
+	server := emailForwardTestServer(t, map[string]string{"*": "catchall@example.com"}, &sets)
+	client := newTestClient(server.URL)
+
+	d := schema.TestResourceDataRaw(t, resourceNamecheapEmailForward().Schema, map[string]interface{}{})
+	d.SetId("Example.com/*")
+	res, err := resourceNamecheapEmailForwardImport(context.Background(), d, testMeta(client))
+	if assert.NoError(t, err) && assert.Len(t, res, 1) {
+		assert.Equal(t, "example.com/*", d.Id())
+		assert.Equal(t, "example.com", d.Get("domain"))
+		assert.Equal(t, "*", d.Get("mailbox"))
+		assert.Equal(t, "catchall@example.com", d.Get("forward_to"))
+	}
+
+	for _, id := range []string{"example.com", "example.com/", "/info"} {
+		d := schema.TestResourceDataRaw(t, resourceNamecheapEmailForward().Schema, map[string]interface{}{})
+		d.SetId(id)
+		_, err := resourceNamecheapEmailForwardImport(context.Background(), d, testMeta(client))
+		assert.ErrorContains(t, err, "invalid import ID", id)
+	}
+
+	d = schema.TestResourceDataRaw(t, resourceNamecheapEmailForward().Schema, map[string]interface{}{})
+	d.SetId("example.com/info")
+	_, err = resourceNamecheapEmailForwardImport(context.Background(), d, testMeta(client))
+	assert.ErrorContains(t, err, "no email forwarding alias")
+}
+
+func TestResourceEmailForwardCreate_ConflictWarning(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		switch r.FormValue("Command") {
+		case "namecheap.domains.dns.getEmailForwarding":
+			_, _ = fmt.Fprint(w, getEmailForwardingXML("example.com", nil))
+		case "namecheap.domains.dns.setEmailForwarding":
+			_, _ = fmt.Fprint(w, setEmailForwardingSuccessXML("example.com"))
+		case "namecheap.domains.dns.getHosts":
+			_, _ = fmt.Fprint(w, getHostsXML("MX", nil))
+		}
+	}))
+	defer server.Close()
+
+	d := emailForwardTestData(t, "info", "me@example.com")
+	diags := resourceNamecheapEmailForwardCreate(context.Background(), d, testMeta(newTestClient(server.URL)))
+
+	assert.False(t, diags.HasError())
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, diag.Warning, diags[0].Severity)
+		assert.Contains(t, diags[0].Summary, "email_type")
+	}
+}
@@ -3,6 +3,7 @@ package namecheap_provider
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
@@ -21,7 +22,9 @@ import (
 // effect when the domain uses Namecheap BasicDNS/FreeDNS and its email_type is
 // "FWD" (see namecheap_domain_records); a mismatch surfaces as an apply-time
 // warning; SDKv2 cannot make this a plan-time diagnostic without an extra API
-// call during plan (see #250's plan for the same limitation).
+// call during plan (see #250's plan for the same limitation). With
+// manage_email_type the resource switches the domain to FWD itself instead,
+// and switches it back on destroy.
 func resourceNamecheapEmailForwarding() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceEmailForwardingCreate,
@@ -29,11 +32,13 @@ func resourceNamecheapEmailForwarding() *schema.Resource {
 		UpdateContext: resourceEmailForwardingUpdate,
 		DeleteContext: resourceEmailForwardingDelete,
 
+		Timeouts: resourceTimeouts(),
+
 		Description: "Manages a domain's entire email forwarding table (mailbox alias -> destination address) via the Namecheap " +
 			"domains.dns.getEmailForwarding/setEmailForwarding API. This resource owns the full table: rules created outside " +
 			"Terraform surface as drift on refresh and are replaced on the next apply. Forwarding only takes effect when the " +
 			"domain uses Namecheap BasicDNS/FreeDNS and its email_type is \"FWD\" (see namecheap_domain_records); a mismatch " +
-			"surfaces as a warning at apply time.",
+			"surfaces as a warning at apply time, unless manage_email_type switches the domain to FWD itself.",
 
 		Importer: &schema.ResourceImporter{
 			StateContext: func(_ context.Context, data *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
@@ -55,18 +60,103 @@ func resourceNamecheapEmailForwarding() *schema.Resource {
 				ValidateFunc: validateDomainIsNotSubdomain,
 			},
 			"forwards": {
-				Type:     schema.TypeMap,
-				Required: true,
-				Elem:     &schema.Schema{Type: schema.TypeString},
+				Type:         schema.TypeMap,
+				Optional:     true,
+				ExactlyOneOf: []string{"forwards", "forward"},
+				Elem:         &schema.Schema{Type: schema.TypeString},
 				Description: "Map of mailbox alias to destination email address, e.g. { info = \"me@example.com\" }. Use \"*\" as the " +
 					"alias for a catch-all. This resource owns the domain's entire forwarding table: it must be non-empty (destroy " +
-					"the resource to clear all forwarding) and any rule not listed here is removed on the next apply.",
+					"the resource to clear all forwarding) and any rule not listed here is removed on the next apply. " +
+					"Exactly one of forwards or forward must be set; use forward for an alias with several destinations.",
 				ValidateDiagFunc: validateForwards,
 			},
+			"forward": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				ExactlyOneOf: []string{"forwards", "forward"},
+				Description: "A forwarding alias and every address it forwards to, for aliases that fan out to several " +
+					"destinations. Each mailbox may appear in one block only. Like forwards, the blocks describe the domain's " +
+					"entire forwarding table. Exactly one of forwards or forward must be set.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"mailbox": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "The mailbox alias, a lowercase local part with no \"@\" (e.g. \"ops\"), or \"*\" for a catch-all.",
+							ValidateFunc: validateForwardMailbox,
+						},
+						"destinations": {
+							Type:        schema.TypeSet,
+							Required:    true,
+							MinItems:    1,
+							Description: "The addresses mail to the alias is forwarded to. Namecheap stores one forwarding rule per address.",
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validateForwardDestination,
+							},
+						},
+					},
+				},
+			},
+			"manage_email_type": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Switch the domain's email_type to FWD on apply, and back to previous_email_type on destroy, instead of " +
+					"only warning about a mismatch. The switch rewrites the domain's host records unchanged; it is refused while the " +
+					"domain has MX or MXE records, which setHosts would delete. Do not combine with an email_type set on a " +
+					"namecheap_domain_records resource for the same domain. Setting it back to false leaves the email type as it is.",
+			},
+			"previous_email_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The domain's email_type before manage_email_type switched it to FWD, restored on destroy. Empty while manage_email_type is false.",
+			},
+		},
+
+		ValidateRawResourceConfigFuncs: []schema.ValidateRawResourceConfigFunc{
+			validateForwardBlocksRawConfig,
 		},
 	}
 }
 
+// validateForwardBlocksRawConfig rejects two forward blocks naming the same
+// mailbox. The API would store them as one alias, which reads back as a single
+// block and leaves a diff no apply can settle; list every destination in one
+// block instead.
+func validateForwardBlocksRawConfig(_ context.Context, req schema.ValidateResourceConfigFuncRequest, resp *schema.ValidateResourceConfigFuncResponse) {
+	if req.RawConfig.IsNull() || !req.RawConfig.IsKnown() {
+		return
+	}
+	blocks := req.RawConfig.GetAttr("forward")
+	if blocks.IsNull() || !blocks.IsKnown() {
+		return
+	}
+
+	seen := map[string]bool{}
+	for it := blocks.ElementIterator(); it.Next(); {
+		_, block := it.Element()
+		if block.IsNull() || !block.IsKnown() {
+			continue
+		}
+		mailbox := block.GetAttr("mailbox")
+		if mailbox.IsNull() || !mailbox.IsKnown() {
+			continue
+		}
+		name := strings.ToLower(mailbox.AsString())
+		if seen[name] {
+			resp.Diagnostics = append(resp.Diagnostics, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Duplicate forward mailbox",
+				Detail: fmt.Sprintf("mailbox %q appears in more than one forward block. List all of its destinations in a "+
+					"single block's destinations instead.", name),
+				AttributePath: cty.Path{cty.GetAttrStep{Name: "forward"}},
+			})
+		}
+		seen[name] = true
+	}
+}
+
 // validateForwards enforces the forwards map's full-ownership contract
 // (non-empty) and, per entry, that the mailbox alias is a bare lowercase local
 // part (or "*" for a catch-all) and the destination looks like an email
@@ -88,7 +178,7 @@ func validateForwards(v interface{}, path cty.Path) diag.Diagnostics {
 	for mailbox, destRaw := range forwards {
 		keyPath := append(path, cty.IndexStep{Key: cty.StringVal(mailbox)})
 
-		if mailbox == "" || mailbox != strings.ToLower(mailbox) || strings.ContainsAny(mailbox, "@ \t\r\n") {
+		if !isValidForwardMailbox(mailbox) {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
 				Summary:  "Invalid forwards mailbox alias",
@@ -117,6 +207,13 @@ func validateForwards(v interface{}, path cty.Path) diag.Diagnostics {
 	return diags
 }
 
+// isValidForwardMailbox reports whether mailbox is usable as a forwarding
+// alias: a non-empty, lowercase local part with no "@" or whitespace, or "*"
+// for a catch-all.
+func isValidForwardMailbox(mailbox string) bool {
+	return mailbox != "" && mailbox == strings.ToLower(mailbox) && !strings.ContainsAny(mailbox, "@ \t\r\n")
+}
+
 // isPlausibleEmailAddress reports whether s has the shape of an email
 // address: exactly one "@" with a non-empty local and domain part. This is
 // deliberately light-touch, not full RFC 5322 validation.
@@ -128,11 +225,12 @@ func isPlausibleEmailAddress(s string) bool {
 	return strings.Count(s, "@") == 1
 }
 
-// forwardsMapToSlice converts the schema's forwards map into the SDK's
-// []EmailForward, sorted by mailbox key so the resulting mailboxN/ForwardToN
+// forwardsMapToSlice converts a forwarding table (mailbox -> destinations)
+// into the SDK's []EmailForward, one rule per destination. Mailboxes and each
+// mailbox's destinations are sorted so the resulting mailboxN/ForwardToN
 // request parameters are in deterministic order (stable mock assertions and
 // API payloads).
-func forwardsMapToSlice(forwards map[string]interface{}) []namecheap.EmailForward {
+func forwardsMapToSlice(forwards map[string][]string) []namecheap.EmailForward {
 	mailboxes := make([]string, 0, len(forwards))
 	for mailbox := range forwards {
 		mailboxes = append(mailboxes, mailbox)
@@ -141,26 +239,99 @@ func forwardsMapToSlice(forwards map[string]interface{}) []namecheap.EmailForwar
 
 	result := make([]namecheap.EmailForward, 0, len(mailboxes))
 	for _, mailbox := range mailboxes {
-		result = append(result, namecheap.EmailForward{
-			Mailbox:   mailbox,
-			ForwardTo: forwards[mailbox].(string),
-		})
+		destinations := slices.Clone(forwards[mailbox])
+		sort.Strings(destinations)
+		for _, dest := range destinations {
+			result = append(result, namecheap.EmailForward{
+				Mailbox:   mailbox,
+				ForwardTo: dest,
+			})
+		}
 	}
 	return result
 }
 
-// forwardsSliceToMap converts the SDK's []EmailForward into the schema's
-// forwards map. Mailbox keys are lowercased (dashboard-created rules may
-// differ in case; aliases are case-insensitive) with the last entry winning
-// on a post-lowercasing collision; ForwardTo is preserved verbatim.
-func forwardsSliceToMap(forwards []namecheap.EmailForward) map[string]string {
-	result := make(map[string]string, len(forwards))
+// forwardsSliceToMap converts the SDK's []EmailForward into a forwarding table
+// (mailbox -> destinations), keeping every rule of a mailbox that has several.
+// Mailbox keys are lowercased (dashboard-created rules may differ in case;
+// aliases are case-insensitive), so rules that collide after lowercasing are
+// merged; ForwardTo is preserved verbatim, and a destination repeated for one
+// mailbox is kept once.
+func forwardsSliceToMap(forwards []namecheap.EmailForward) map[string][]string {
+	result := make(map[string][]string, len(forwards))
 	for _, fwd := range forwards {
-		result[strings.ToLower(fwd.Mailbox)] = fwd.ForwardTo
+		mailbox := strings.ToLower(fwd.Mailbox)
+		if !slices.Contains(result[mailbox], fwd.ForwardTo) {
+			result[mailbox] = append(result[mailbox], fwd.ForwardTo)
+		}
 	}
 	return result
 }
 
+// forwardsFromData returns the forwarding table the configuration describes,
+// from whichever of forward or forwards is set.
+func forwardsFromData(data *schema.ResourceData) map[string][]string {
+	result := map[string][]string{}
+	if blocks := data.Get("forward").(*schema.Set).List(); len(blocks) > 0 {
+		for _, raw := range blocks {
+			block := raw.(map[string]interface{})
+			mailbox := block["mailbox"].(string)
+			for _, dest := range block["destinations"].(*schema.Set).List() {
+				result[mailbox] = append(result[mailbox], dest.(string))
+			}
+		}
+		return result
+	}
+	for mailbox, dest := range data.Get("forwards").(map[string]interface{}) {
+		result[mailbox] = []string{dest.(string)}
+	}
+	return result
+}
+
+// flattenForwardBlocks converts a forwarding table into forward blocks. Both
+// the blocks and their destinations are sets, so neither the API's rule order
+// nor the configuration's matters to drift detection.
+func flattenForwardBlocks(forwards map[string][]string) []interface{} {
+	blocks := make([]interface{}, 0, len(forwards))
+	for mailbox, destinations := range forwards {
+		dests := make([]interface{}, 0, len(destinations))
+		for _, dest := range destinations {
+			dests = append(dests, dest)
+		}
+		blocks = append(blocks, map[string]interface{}{
+			"mailbox":      mailbox,
+			"destinations": dests,
+		})
+	}
+	return blocks
+}
+
+// flattenForwardsMap converts a forwarding table into the forwards map. The
+// map holds one destination per mailbox, so a mailbox with several - added in
+// the dashboard, say - is rendered as its sorted destinations joined by ", ":
+// that never equals a configured address, so the extra rules surface as drift
+// on the alias rather than being hidden behind whichever one came last.
+func flattenForwardsMap(forwards map[string][]string) map[string]string {
+	result := make(map[string]string, len(forwards))
+	for mailbox, destinations := range forwards {
+		sorted := slices.Clone(destinations)
+		sort.Strings(sorted)
+		result[mailbox] = strings.Join(sorted, ", ")
+	}
+	return result
+}
+
+// hasMultiDestinationAlias reports whether any mailbox in forwards has more
+// than one destination, which only the forward blocks can represent.
+func hasMultiDestinationAlias(forwards map[string][]string) bool {
+	for _, destinations := range forwards {
+		if len(destinations) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
 func resourceEmailForwardingCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	return setEmailForwarding(ctx, data, meta, true)
 }
@@ -171,14 +342,18 @@ func resourceEmailForwardingUpdate(ctx context.Context, data *schema.ResourceDat
 
 // setEmailForwarding backs both Create and Update: setEmailForwarding is the
 // API's only write primitive (a full-table replace), so both operations issue
-// the same call. After a successful set it runs the DNS-mode/email_type
-// conflict check and returns its warning rather than dropping it.
+// the same call. After a successful set it either switches the domain to FWD
+// (manage_email_type) or runs the DNS-mode/email_type conflict check and
+// returns its warning rather than dropping it.
+//
+// The forwards are written first: a failed switch then leaves a created
+// resource behind (tainted, so it is replaced on the next apply) rather than a
+// domain switched to FWD with nothing in state recording what it was before.
 func setEmailForwarding(ctx context.Context, data *schema.ResourceData, meta interface{}, isCreate bool) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 	domain := strings.ToLower(data.Get("domain").(string))
-	forwards := data.Get("forwards").(map[string]interface{})
 
-	_, err := client.DomainsDNS.SetEmailForwardingWithContext(ctx, domain, forwardsMapToSlice(forwards))
+	_, err := client.DomainsDNS.SetEmailForwardingWithContext(ctx, domain, forwardsMapToSlice(forwardsFromData(data)))
 	if err != nil {
 		return diagFromClientError(err)
 	}
@@ -187,7 +362,22 @@ func setEmailForwarding(ctx context.Context, data *schema.ResourceData, meta int
 		data.SetId(domain)
 	}
 
-	return checkEmailForwardingConflict(ctx, domain, client)
+	if !data.Get("manage_email_type").(bool) {
+		_ = data.Set("previous_email_type", "")
+		return checkEmailForwardingConflict(ctx, domain, client)
+	}
+
+	previous, diags := setDomainEmailType(ctx, client, domain, namecheap.EmailTypeForward, "")
+	if diags.HasError() {
+		return diags
+	}
+	// Only the type found when management began is worth restoring: once the
+	// domain is on FWD, a later apply finds FWD, and a type someone changed it to
+	// in the meantime was never the one to go back to.
+	if data.Get("previous_email_type").(string) == "" {
+		_ = data.Set("previous_email_type", previous)
+	}
+	return nil
 }
 
 // checkEmailForwardingConflict issues one GetHostsWithContext call to detect
@@ -231,7 +421,7 @@ func checkEmailForwardingConflict(ctx context.Context, domain string, client *na
 }
 
 func resourceEmailForwardingRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 	domain := strings.ToLower(data.Get("domain").(string))
 
 	resp, err := client.DomainsDNS.GetEmailForwardingWithContext(ctx, domain)
@@ -256,18 +446,44 @@ func resourceEmailForwardingRead(ctx context.Context, data *schema.ResourceData,
 		forwards = *resp.DomainDNSGetEmailForwardingResult.Forwards
 	}
 
-	if err := data.Set("forwards", forwardsSliceToMap(forwards)); err != nil {
+	// Only the representation in use is refreshed; setting the other would show
+	// as a diff against a configuration that does not mention it. A resource
+	// without either yet - just imported - takes forwards unless an alias has
+	// several destinations, which only forward blocks can hold.
+	table := forwardsSliceToMap(forwards)
+	useBlocks := data.Get("forward").(*schema.Set).Len() > 0
+	if !useBlocks && len(data.Get("forwards").(map[string]interface{})) == 0 {
+		useBlocks = hasMultiDestinationAlias(table)
+	}
+	if useBlocks {
+		if err := data.Set("forward", flattenForwardBlocks(table)); err != nil {
+			return diag.FromErr(err)
+		}
+	} else if err := data.Set("forwards", flattenForwardsMap(table)); err != nil {
 		return diag.FromErr(err)
 	}
 	if err := data.Set("domain", domain); err != nil {
 		return diag.FromErr(err)
 	}
 
+	// A domain moved off FWD behind a managing resource's back reads as
+	// manage_email_type = false, so the next plan proposes switching it back.
+	// Failing to tell is not worth failing the refresh over: the next apply
+	// reads the hosts again and reports whatever stands in its way.
+	if data.Get("manage_email_type").(bool) {
+		hosts, err := client.DomainsDNS.GetHostsWithContext(ctx, domain)
+		if err == nil && hosts != nil && hosts.DomainDNSGetHostsResult != nil {
+			if emailType := hosts.DomainDNSGetHostsResult.EmailType; emailType != nil && *emailType != namecheap.EmailTypeForward {
+				_ = data.Set("manage_email_type", false)
+			}
+		}
+	}
+
 	return nil
 }
 
 func resourceEmailForwardingDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 	domain := strings.ToLower(data.Get("domain").(string))
 
 	_, err := client.DomainsDNS.SetEmailForwardingWithContext(ctx, domain, []namecheap.EmailForward{})
@@ -280,5 +496,14 @@ func resourceEmailForwardingDelete(ctx context.Context, data *schema.ResourceDat
 		return diagFromClientError(err)
 	}
 
+	// Hand the domain back in the email type it had, but only while it is still
+	// on the FWD this resource put it on.
+	previous := data.Get("previous_email_type").(string)
+	if data.Get("manage_email_type").(bool) && previous != "" && previous != namecheap.EmailTypeForward {
+		if _, diags := setDomainEmailType(ctx, client, domain, previous, namecheap.EmailTypeForward); diags.HasError() {
+			return diags
+		}
+	}
+
 	return nil
 }
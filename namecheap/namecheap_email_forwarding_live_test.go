@@ -209,7 +209,7 @@ func testAccCheckEmailForwardingAPI(t *testing.T, want map[string]string) resour
 			if resp.DomainDNSGetEmailForwardingResult.Forwards != nil {
 				forwards = *resp.DomainDNSGetEmailForwardingResult.Forwards
 			}
-			got := forwardsSliceToMap(forwards)
+			got := flattenForwardsMap(forwardsSliceToMap(forwards))
 
 			if len(got) != len(want) {
 				return fmt.Errorf("email forwarding table for %q = %+v, want %+v", *testAccDomain, got, want)
@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
 	"github.com/stretchr/testify/assert"
 )
@@ -119,10 +120,10 @@ func TestValidateForwards_AttributePathScopedToOffendingKey(t *testing.T) {
 // --- forwardsMapToSlice / forwardsSliceToMap ---
 
 func TestForwardsMapToSlice_DeterministicOrder(t *testing.T) {
-	forwards := map[string]interface{}{
-		"zeta":  "z@example.com",
-		"alpha": "a@example.com",
-		"mid":   "m@example.com",
+	forwards := map[string][]string{
+		"zeta":  {"z@example.com"},
+		"alpha": {"a@example.com"},
+		"mid":   {"m@example.com"},
 	}
 
 	result := forwardsMapToSlice(forwards)
@@ -134,27 +135,122 @@ func TestForwardsMapToSlice_DeterministicOrder(t *testing.T) {
 	}, result)
 }
 
+func TestForwardsMapToSlice_OneRulePerDestination(t *testing.T) {
+	result := forwardsMapToSlice(map[string][]string{
+		"ops":  {"carol@example.com", "alice@example.com", "bob@example.com"},
+		"info": {"me@example.com"},
+	})
+
+	assert.Equal(t, []namecheap.EmailForward{
+		{Mailbox: "info", ForwardTo: "me@example.com"},
+		{Mailbox: "ops", ForwardTo: "alice@example.com"},
+		{Mailbox: "ops", ForwardTo: "bob@example.com"},
+		{Mailbox: "ops", ForwardTo: "carol@example.com"},
+	}, result)
+}
+
 func TestForwardsSliceToMap(t *testing.T) {
 	t.Run("nil_slice", func(t *testing.T) {
-		assert.Equal(t, map[string]string{}, forwardsSliceToMap(nil))
+		assert.Equal(t, map[string][]string{}, forwardsSliceToMap(nil))
 	})
 
 	t.Run("lowercases_keys", func(t *testing.T) {
 		result := forwardsSliceToMap([]namecheap.EmailForward{
 			{Mailbox: "Info", ForwardTo: "Me@Example.com"},
 		})
-		assert.Equal(t, map[string]string{"info": "Me@Example.com"}, result)
+		assert.Equal(t, map[string][]string{"info": {"Me@Example.com"}}, result)
 	})
 
-	t.Run("duplicate_after_lowercasing_last_wins", func(t *testing.T) {
+	t.Run("duplicate_mailboxes_round_trip", func(t *testing.T) {
 		result := forwardsSliceToMap([]namecheap.EmailForward{
 			{Mailbox: "Info", ForwardTo: "first@example.com"},
 			{Mailbox: "info", ForwardTo: "second@example.com"},
+			{Mailbox: "info", ForwardTo: "second@example.com"},
+		})
+		assert.Equal(t, map[string][]string{"info": {"first@example.com", "second@example.com"}}, result)
+		assert.Equal(t, []namecheap.EmailForward{
+			{Mailbox: "info", ForwardTo: "first@example.com"},
+			{Mailbox: "info", ForwardTo: "second@example.com"},
+		}, forwardsMapToSlice(result))
+	})
+}
+
+func TestFlattenForwardsMap_MultipleDestinationsSurfaceAsDrift(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"info": "me@example.com",
+		"ops":  "alice@example.com, bob@example.com",
+	}, flattenForwardsMap(map[string][]string{
+		"info": {"me@example.com"},
+		"ops":  {"bob@example.com", "alice@example.com"},
+	}))
+}
+
+func TestForwardsFromData(t *testing.T) {
+	t.Run("map", func(t *testing.T) {
+		d := emailForwardingTestData(t, "example.com", map[string]interface{}{"info": "me@example.com"})
+		assert.Equal(t, map[string][]string{"info": {"me@example.com"}}, forwardsFromData(d))
+	})
+
+	t.Run("blocks", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, resourceNamecheapEmailForwarding().Schema, map[string]interface{}{
+			"domain": "example.com",
+			"forward": []interface{}{
+				map[string]interface{}{"mailbox": "ops", "destinations": []interface{}{"alice@example.com", "bob@example.com"}},
+				map[string]interface{}{"mailbox": "info", "destinations": []interface{}{"me@example.com"}},
+			},
 		})
-		assert.Equal(t, map[string]string{"info": "second@example.com"}, result)
+		table := forwardsFromData(d)
+		assert.ElementsMatch(t, []string{"alice@example.com", "bob@example.com"}, table["ops"])
+		assert.Equal(t, []string{"me@example.com"}, table["info"])
 	})
 }
 
+func TestValidateForwardBlocksRawConfig(t *testing.T) {
+	block := func(mailbox string, dests ...string) cty.Value {
+		values := make([]cty.Value, 0, len(dests))
+		for _, dest := range dests {
+			values = append(values, cty.StringVal(dest))
+		}
+		return cty.ObjectVal(map[string]cty.Value{
+			"mailbox":      cty.StringVal(mailbox),
+			"destinations": cty.SetVal(values),
+		})
+	}
+	validate := func(blocks ...cty.Value) diag.Diagnostics {
+		var resp schema.ValidateResourceConfigFuncResponse
+		validateForwardBlocksRawConfig(context.Background(), schema.ValidateResourceConfigFuncRequest{
+			RawConfig: cty.ObjectVal(map[string]cty.Value{"forward": cty.SetVal(blocks)}),
+		}, &resp)
+		return resp.Diagnostics
+	}
+
+	assert.Empty(t, validate(block("ops", "a@example.com", "b@example.com"), block("info", "me@example.com")))
+
+	diags := validate(block("ops", "a@example.com"), block("ops", "b@example.com"))
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, diag.Error, diags[0].Severity)
+		assert.Contains(t, diags[0].Detail, `"ops"`)
+		assert.Equal(t, cty.Path{cty.GetAttrStep{Name: "forward"}}, diags[0].AttributePath)
+	}
+}
+
+func TestResourceEmailForwardingSchema_ExactlyOneOf(t *testing.T) {
+	r := resourceNamecheapEmailForwarding()
+	assert.NoError(t, r.InternalValidate(nil, true))
+
+	both := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"domain":   "example.com",
+		"forwards": map[string]interface{}{"info": "me@example.com"},
+		"forward": []interface{}{
+			map[string]interface{}{"mailbox": "ops", "destinations": []interface{}{"a@example.com"}},
+		},
+	})
+	assert.True(t, r.Validate(both).HasError(), "forwards and forward are mutually exclusive")
+
+	neither := terraform.NewResourceConfigRaw(map[string]interface{}{"domain": "example.com"})
+	assert.True(t, r.Validate(neither).HasError(), "one of forwards or forward is required")
+}
+
 // --- import ---
 
 func TestResourceEmailForwardingImport(t *testing.T) {
@@ -190,7 +286,7 @@ func TestResourceEmailForwardingCreate(t *testing.T) {
 	client := newTestClient(server.URL)
 	d := emailForwardingTestData(t, "example.com", map[string]interface{}{"info": "me@example.com"})
 
-	diags := resourceEmailForwardingCreate(context.Background(), d, client)
+	diags := resourceEmailForwardingCreate(context.Background(), d, testMeta(client))
 	assert.False(t, diags.HasError(), "unexpected diags: %v", diags)
 	assert.Empty(t, diags, "no warning expected when DNS mode and email_type are both correct")
 	assert.Equal(t, "example.com", d.Id())
@@ -205,7 +301,7 @@ func TestResourceEmailForwardingCreate_SetAPIError(t *testing.T) {
 	client := newTestClient(server.URL)
 	d := emailForwardingTestData(t, "example.com", map[string]interface{}{"info": "me@example.com"})
 
-	diags := resourceEmailForwardingCreate(context.Background(), d, client)
+	diags := resourceEmailForwardingCreate(context.Background(), d, testMeta(client))
 	assert.True(t, diags.HasError())
 	assert.Empty(t, d.Id())
 }
@@ -227,7 +323,7 @@ func TestResourceEmailForwardingCreate_WarnsOnCustomDNS(t *testing.T) {
 	client := newTestClient(server.URL)
 	d := emailForwardingTestData(t, "example.com", map[string]interface{}{"info": "me@example.com"})
 
-	diags := resourceEmailForwardingCreate(context.Background(), d, client)
+	diags := resourceEmailForwardingCreate(context.Background(), d, testMeta(client))
 	assert.False(t, diags.HasError())
 	if assert.Len(t, diags, 1) {
 		assert.Equal(t, diag.Warning, diags[0].Severity)
@@ -252,7 +348,7 @@ func TestResourceEmailForwardingCreate_WarnsOnWrongEmailType(t *testing.T) {
 	client := newTestClient(server.URL)
 	d := emailForwardingTestData(t, "example.com", map[string]interface{}{"info": "me@example.com"})
 
-	diags := resourceEmailForwardingCreate(context.Background(), d, client)
+	diags := resourceEmailForwardingCreate(context.Background(), d, testMeta(client))
 	assert.False(t, diags.HasError())
 	if assert.Len(t, diags, 1) {
 		assert.Equal(t, diag.Warning, diags[0].Severity)
@@ -278,7 +374,7 @@ func TestResourceEmailForwardingUpdate(t *testing.T) {
 	d := emailForwardingTestData(t, "example.com", map[string]interface{}{"sales": "sales@example.com"})
 	d.SetId("example.com")
 
-	diags := resourceEmailForwardingUpdate(context.Background(), d, client)
+	diags := resourceEmailForwardingUpdate(context.Background(), d, testMeta(client))
 	assert.False(t, diags.HasError(), "unexpected diags: %v", diags)
 }
 
@@ -294,11 +390,66 @@ func TestResourceEmailForwardingRead(t *testing.T) {
 	d := emailForwardingTestData(t, "example.com", map[string]interface{}{})
 	d.SetId("example.com")
 
-	diags := resourceEmailForwardingRead(context.Background(), d, client)
+	diags := resourceEmailForwardingRead(context.Background(), d, testMeta(client))
 	assert.False(t, diags.HasError(), "unexpected diags: %v", diags)
 	assert.Equal(t, map[string]interface{}{"info": "me@example.com"}, d.Get("forwards"))
 }
 
+func TestResourceEmailForwardingRead_Blocks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <CommandResponse>
+    <DomainEmailForwardingResult Domain="example.com">
+      <Forward mailbox="ops" ForwardTo="bob@example.com" />
+      <Forward mailbox="ops" ForwardTo="alice@example.com" />
+      <Forward mailbox="info" ForwardTo="me@example.com" />
+    </DomainEmailForwardingResult>
+  </CommandResponse>
+</ApiResponse>`)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+
+	t.Run("import_picks_blocks_for_multi_destination_aliases", func(t *testing.T) {
+		d := emailForwardingTestData(t, "example.com", map[string]interface{}{})
+		d.SetId("example.com")
+
+		diags := resourceEmailForwardingRead(context.Background(), d, testMeta(client))
+		assert.False(t, diags.HasError(), "unexpected diags: %v", diags)
+		assert.Empty(t, d.Get("forwards"))
+		assert.Equal(t, 2, d.Get("forward").(*schema.Set).Len())
+	})
+
+	t.Run("configured_order_does_not_matter", func(t *testing.T) {
+		blocks := []interface{}{
+			map[string]interface{}{"mailbox": "info", "destinations": []interface{}{"me@example.com"}},
+			map[string]interface{}{"mailbox": "ops", "destinations": []interface{}{"alice@example.com", "bob@example.com"}},
+		}
+		d := schema.TestResourceDataRaw(t, resourceNamecheapEmailForwarding().Schema, map[string]interface{}{
+			"domain":  "example.com",
+			"forward": blocks,
+		})
+		d.SetId("example.com")
+		want := d.Get("forward").(*schema.Set)
+
+		diags := resourceEmailForwardingRead(context.Background(), d, testMeta(client))
+		assert.False(t, diags.HasError(), "unexpected diags: %v", diags)
+		assert.True(t, want.Equal(d.Get("forward")), "the API's rule order must not read back as drift")
+	})
+
+	t.Run("map_shows_extra_destinations_as_drift", func(t *testing.T) {
+		d := emailForwardingTestData(t, "example.com", map[string]interface{}{"ops": "alice@example.com", "info": "me@example.com"})
+		d.SetId("example.com")
+
+		diags := resourceEmailForwardingRead(context.Background(), d, testMeta(client))
+		assert.False(t, diags.HasError(), "unexpected diags: %v", diags)
+		assert.Equal(t, "alice@example.com, bob@example.com", d.Get("forwards.ops"))
+	})
+}
+
 // TestResourceEmailForwardingRead_EmptyResultKeepsID covers a Status=OK
 // response carrying no DomainEmailForwardingResult element at all - the
 // observed real-API shape for a domain with zero forwarding rules. This must
@@ -318,7 +469,7 @@ func TestResourceEmailForwardingRead_EmptyResultKeepsID(t *testing.T) {
 	d := emailForwardingTestData(t, "example.com", map[string]interface{}{"info": "me@example.com"})
 	d.SetId("example.com")
 
-	diags := resourceEmailForwardingRead(context.Background(), d, client)
+	diags := resourceEmailForwardingRead(context.Background(), d, testMeta(client))
 	assert.False(t, diags.HasError(), "unexpected diags: %v", diags)
 	assert.Equal(t, "example.com", d.Id(), "a domain with zero forwards must not be treated as gone")
 	assert.Equal(t, map[string]interface{}{}, d.Get("forwards"))
@@ -334,7 +485,7 @@ func TestResourceEmailForwardingRead_DomainGone(t *testing.T) {
 	d := emailForwardingTestData(t, "example.com", map[string]interface{}{})
 	d.SetId("example.com")
 
-	diags := resourceEmailForwardingRead(context.Background(), d, client)
+	diags := resourceEmailForwardingRead(context.Background(), d, testMeta(client))
 	assert.False(t, diags.HasError(), "a gone domain must not error; got %v", diags)
 	assert.Empty(t, d.Id(), "a gone domain should be removed from state")
 }
@@ -349,7 +500,7 @@ func TestResourceEmailForwardingRead_APIError(t *testing.T) {
 	d := emailForwardingTestData(t, "example.com", map[string]interface{}{})
 	d.SetId("example.com")
 
-	diags := resourceEmailForwardingRead(context.Background(), d, client)
+	diags := resourceEmailForwardingRead(context.Background(), d, testMeta(client))
 	assert.True(t, diags.HasError(), "a non-gone API error must surface")
 	assert.Equal(t, "example.com", d.Id(), "state must be left intact on a hard error")
 }
@@ -367,7 +518,7 @@ func TestResourceEmailForwardingDelete(t *testing.T) {
 	d := emailForwardingTestData(t, "example.com", map[string]interface{}{"info": "me@example.com"})
 	d.SetId("example.com")
 
-	diags := resourceEmailForwardingDelete(context.Background(), d, client)
+	diags := resourceEmailForwardingDelete(context.Background(), d, testMeta(client))
 	assert.False(t, diags.HasError(), "unexpected diags: %v", diags)
 }
 
@@ -381,6 +532,6 @@ func TestResourceEmailForwardingDelete_DomainGoneIsNoOp(t *testing.T) {
 	d := emailForwardingTestData(t, "example.com", map[string]interface{}{"info": "me@example.com"})
 	d.SetId("example.com")
 
-	diags := resourceEmailForwardingDelete(context.Background(), d, client)
+	diags := resourceEmailForwardingDelete(context.Background(), d, testMeta(client))
 	assert.False(t, diags.HasError(), "destroying an already-gone domain must not error; got %v", diags)
 }
@@ -0,0 +1,834 @@
+package namecheap_provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+)
+
+const (
+	emailProviderGoogle       = "google"
+	emailProviderMicrosoft365 = "microsoft365"
+	emailProviderPrivateEmail = "private_email"
+	emailProviderCustom       = "custom"
+
+	// spfMaxLookups is RFC 7208's limit on the DNS-querying terms an SPF check
+	// may evaluate. A record over it fails with a permerror at every receiver,
+	// which is worse than having no SPF record at all.
+	spfMaxLookups = 10
+
+	// dkimHostSuffix is appended to a DKIM selector to name its record.
+	dkimHostSuffix = "._domainkey"
+
+	// dmarcHost names the DMARC policy record.
+	dmarcHost = "_dmarc"
+)
+
+// emailProviderPreset is what a hosted mail provider asks the domain to publish:
+// its MX hosts and the SPF include that authorizes its outbound servers.
+type emailProviderPreset struct {
+	mx         func(domain string) []emailSetupMX
+	spfInclude string
+}
+
+// emailSetupMX is one mail exchanger in a preset or an mx block.
+type emailSetupMX struct {
+	address string
+	pref    int
+}
+
+var emailProviderPresets = map[string]emailProviderPreset{
+	emailProviderGoogle: {
+		mx:         func(string) []emailSetupMX { return []emailSetupMX{{"smtp.google.com", 1}} },
+		spfInclude: "_spf.google.com",
+	},
+	emailProviderMicrosoft365: {
+		// Microsoft 365 names a tenant's MX after the domain, dots turned to dashes.
+		mx: func(domain string) []emailSetupMX {
+			return []emailSetupMX{{strings.ReplaceAll(domain, ".", "-") + ".mail.protection.outlook.com", 0}}
+		},
+		spfInclude: "spf.protection.outlook.com",
+	},
+	emailProviderPrivateEmail: {
+		mx: func(string) []emailSetupMX {
+			return []emailSetupMX{{"mx1.privateemail.com", 10}, {"mx2.privateemail.com", 10}}
+		},
+		spfInclude: "spf.privateemail.com",
+	},
+}
+
+// emailSetupSlot is one of the places in a zone namecheap_email_setup owns: a
+// host and record type, and for the apex TXT only the record that is an SPF
+// policy, since the apex carries unrelated TXT records too.
+type emailSetupSlot struct {
+	hostname   string
+	recordType string
+	spf        bool
+}
+
+// resourceNamecheapEmailSetup manages the DNS records a mail provider needs -
+// MX, SPF, DKIM and DMARC - together with the domain's email_type, leaving every
+// other record on the domain untouched.
+//
+// Like namecheap_domain_host_record it goes through the SDK's read-modify-write
+// helpers, one slot at a time, and writes only the slots whose records differ
+// from the ones wanted. Changes to one domain are serialized through ncMutexKV
+// within a run; across runs the SDK's verify-and-retry narrows, but does not
+// close, the window for losing a concurrent change.
+func resourceNamecheapEmailSetup() *schema.Resource {
+	return &schema.Resource{
+		Description: "Manages the MX, SPF, DKIM and DMARC records a mail provider needs, and the domain's email_type, leaving all other records untouched.",
+
+		CreateContext: resourceNamecheapEmailSetupCreate,
+		ReadContext:   resourceNamecheapEmailSetupRead,
+		UpdateContext: resourceNamecheapEmailSetupUpdate,
+		DeleteContext: resourceNamecheapEmailSetupDelete,
+
+		CustomizeDiff: resourceNamecheapEmailSetupCustomizeDiff,
+
+		Timeouts: resourceTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "The registered root domain to set up mail for (e.g. `example.com`). Must be a root domain, not a subdomain. Changing this forces a new resource.",
+				ValidateFunc: validateDomainIsNotSubdomain,
+			},
+			"email_provider": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{emailProviderGoogle, emailProviderMicrosoft365, emailProviderPrivateEmail, emailProviderCustom}, false),
+				Description: "The mail provider preset: `google`, `microsoft365` or `private_email` publish that provider's MX hosts and SPF include; `custom` publishes the `mx` blocks and " +
+					"only the SPF terms configured here.",
+			},
+			"mx": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "The mail exchangers for `email_provider = \"custom\"`, which requires at least one. Not allowed with a preset.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"address": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringIsNotEmpty,
+							Description:  "The mail server's hostname (e.g. `mx.example.net`).",
+						},
+						"mx_pref": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      defaultRecordMXPref,
+							ValidateFunc: validation.IntBetween(0, 255),
+							Description:  fmt.Sprintf("The MX preference, lower being preferred. Defaults to %d.", defaultRecordMXPref),
+						},
+					},
+				},
+			},
+			"spf_includes": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Additional domains to authorize with `include:` terms (e.g. `mailgun.org`), after the preset's own include.",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateSPFTerm,
+				},
+			},
+			"spf_mechanisms": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Further SPF mechanisms, written as they appear in the record (e.g. `ip4:203.0.113.0/24`, `a`). The closing `all` is set with `spf_all`.",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateSPFMechanism,
+				},
+			},
+			"spf_all": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "~all",
+				ValidateFunc: validation.StringInSlice([]string{"-all", "~all", "?all"}, false),
+				Description:  "How receivers treat mail from servers the SPF record does not list: `-all` (fail), `~all` (soft fail) or `?all` (neutral). Defaults to `~all`.",
+			},
+			"dkim": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "DKIM keys to publish, one block per selector.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"selector": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateDKIMSelector,
+							Description:  "The DKIM selector (e.g. `google`); the record is published at `<selector>._domainkey`.",
+						},
+						"public_key": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringIsNotEmpty,
+							Description:  "The public key, published as a TXT record. Either the base64 key alone, which is published as `v=DKIM1; k=rsa; p=<key>`, or the full record the provider gives, starting with `v=DKIM1`. Exactly one of `public_key` and `target` is required.",
+						},
+						"target": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringIsNotEmpty,
+							Description:  "A hostname the selector is published as a CNAME to, for providers that host the key themselves (e.g. Microsoft 365). Exactly one of `public_key` and `target` is required.",
+						},
+					},
+				},
+			},
+			"dmarc_policy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"none", "quarantine", "reject"}, false),
+				Description:  "The DMARC policy: `none`, `quarantine` or `reject`. Leave unset to publish no DMARC record.",
+			},
+			"dmarc_rua": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Addresses that receive aggregate DMARC reports. Requires `dmarc_policy`.",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateForwardDestination,
+				},
+			},
+			"dmarc_pct": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      100,
+				ValidateFunc: validation.IntBetween(1, 100),
+				Description:  "The percentage of failing mail the DMARC policy applies to. Defaults to 100.",
+			},
+			"ttl": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultRecordTTL,
+				ValidateFunc: validation.IntBetween(namecheap.MinTTL, namecheap.MaxTTL),
+				Description:  fmt.Sprintf("Time to live in seconds for every managed record, between %d and %d. Defaults to %d.", namecheap.MinTTL, namecheap.MaxTTL, defaultRecordTTL),
+			},
+			"records": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The records this resource manages, as the zone holds them.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"hostname": {Type: schema.TypeString, Computed: true, Description: "The record's host, `@` for the domain itself."},
+						"type":     {Type: schema.TypeString, Computed: true, Description: "The record type: MX, TXT or CNAME."},
+						"address":  {Type: schema.TypeString, Computed: true, Description: "The record's value."},
+						"mx_pref":  {Type: schema.TypeInt, Computed: true, Description: "The MX preference; Namecheap reports 10 for other types."},
+						"ttl":      {Type: schema.TypeInt, Computed: true, Description: "Time to live in seconds."},
+					},
+				},
+			},
+			"email_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The domain's email_type as Namecheap reports it. This resource sets it to `MX`.",
+			},
+		},
+	}
+}
+
+// validateSPFTerm accepts a single SPF token: no whitespace, no record prefix.
+func validateSPFTerm(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	if v == "" || strings.ContainsAny(v, " \t\r\n\"") || strings.HasPrefix(strings.ToLower(v), "v=spf1") {
+		errs = append(errs, fmt.Errorf("%q must be a single SPF term with no whitespace or quotes, got %q", key, v))
+	}
+	return
+}
+
+// validateSPFMechanism is validateSPFTerm, also refusing an `all` mechanism:
+// spf_all owns that one, and an `all` anywhere but last ends evaluation early.
+func validateSPFMechanism(val interface{}, key string) (warns []string, errs []error) {
+	if warns, errs = validateSPFTerm(val, key); len(errs) > 0 {
+		return
+	}
+	if spfMechanismName(val.(string)) == "all" {
+		errs = append(errs, fmt.Errorf("%q must not be an `all` mechanism; set spf_all instead", key))
+	}
+	return
+}
+
+// validateDKIMSelector accepts a DNS label, or dotted labels, for a selector.
+func validateDKIMSelector(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	for _, label := range strings.Split(v, ".") {
+		if label == "" || strings.Trim(label, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_") != "" {
+			errs = append(errs, fmt.Errorf("%q must be a DNS name of letters, digits, `-` and `_` (e.g. \"google\" or \"selector1\"), got %q", key, v))
+			return
+		}
+	}
+	return
+}
+
+// spfMechanismName returns term's mechanism or modifier name, lower-cased and
+// without its qualifier or argument: "~include:x" is "include", "ip4:..." is
+// "ip4", "redirect=x" is "redirect".
+func spfMechanismName(term string) string {
+	term = strings.TrimLeft(strings.ToLower(term), "+-~?")
+	if i := strings.IndexAny(term, ":/="); i >= 0 {
+		return term[:i]
+	}
+	return term
+}
+
+// spfTopLevelLookupCount counts the terms of an SPF record itself that cost a
+// DNS lookup, the ones RFC 7208 section 4.6.4 limits to spfMaxLookups. It is a
+// lower bound: an include counts once, and the lookups of the record it points
+// to are not counted, since they are only known when the policy is evaluated.
+func spfTopLevelLookupCount(record string) int {
+	count := 0
+	for _, term := range strings.Fields(record) {
+		switch spfMechanismName(term) {
+		case "include", "a", "mx", "ptr", "exists", "redirect":
+			count++
+		}
+	}
+	return count
+}
+
+// emailSetupGetter is the part of ResourceData and ResourceDiff the desired
+// records are built from, so plan and apply build them the same way.
+type emailSetupGetter interface {
+	Get(key string) interface{}
+}
+
+// emailSetupDesired builds the records the configuration asks for, in
+// emailSetupSortRecords order, refusing configurations that cannot be
+// published.
+func emailSetupDesired(d emailSetupGetter) ([]namecheap.DomainsDNSHostRecord, error) {
+	domain := strings.ToLower(d.Get("domain").(string))
+	emailProvider := d.Get("email_provider").(string)
+	ttl := d.Get("ttl").(int)
+
+	record := func(hostname, recordType, address string, mxPref int) namecheap.DomainsDNSHostRecord {
+		return namecheap.DomainsDNSHostRecord{
+			HostName:   namecheap.String(hostname),
+			RecordType: namecheap.String(recordType),
+			Address:    namecheap.String(address),
+			MXPref:     namecheap.UInt8(uint8(mxPref)),
+			TTL:        namecheap.Int(ttl),
+		}
+	}
+
+	var mx []emailSetupMX
+	var terms []string
+	blocks := d.Get("mx").([]interface{})
+	if emailProvider == emailProviderCustom {
+		if len(blocks) == 0 {
+			return nil, fmt.Errorf(`email_provider = "custom" requires at least one mx block`)
+		}
+		for _, raw := range blocks {
+			block := raw.(map[string]interface{})
+			mx = append(mx, emailSetupMX{block["address"].(string), block["mx_pref"].(int)})
+		}
+	} else {
+		if len(blocks) > 0 {
+			return nil, fmt.Errorf("mx blocks are only allowed with email_provider = \"custom\"; the %q preset publishes its own", emailProvider)
+		}
+		preset := emailProviderPresets[emailProvider]
+		mx = preset.mx(domain)
+		terms = append(terms, "include:"+preset.spfInclude)
+	}
+
+	for _, include := range d.Get("spf_includes").([]interface{}) {
+		terms = append(terms, "include:"+include.(string))
+	}
+	for _, mechanism := range d.Get("spf_mechanisms").([]interface{}) {
+		terms = append(terms, mechanism.(string))
+	}
+	if len(terms) == 0 {
+		// Nothing else authorizes the custom mail servers to send.
+		terms = append(terms, "mx")
+	}
+	spf := "v=spf1 " + strings.Join(terms, " ") + " " + d.Get("spf_all").(string)
+
+	var records []namecheap.DomainsDNSHostRecord
+	for _, exchanger := range mx {
+		records = append(records, record("@", namecheap.RecordTypeMX, exchanger.address, exchanger.pref))
+	}
+	records = append(records, record("@", namecheap.RecordTypeTXT, spf, hostRecordFixedMXPref))
+
+	selectors := map[string]bool{}
+	for _, raw := range d.Get("dkim").([]interface{}) {
+		block := raw.(map[string]interface{})
+		selector := strings.ToLower(block["selector"].(string))
+		if selectors[selector] {
+			return nil, fmt.Errorf("dkim selector %q is configured more than once", selector)
+		}
+		selectors[selector] = true
+
+		publicKey, target := block["public_key"].(string), block["target"].(string)
+		switch {
+		case (publicKey == "") == (target == ""):
+			return nil, fmt.Errorf("dkim selector %q needs exactly one of public_key and target", selector)
+		case target != "":
+			records = append(records, record(selector+dkimHostSuffix, namecheap.RecordTypeCNAME, target, hostRecordFixedMXPref))
+		default:
+			if !strings.HasPrefix(strings.ToLower(publicKey), "v=dkim1") {
+				publicKey = "v=DKIM1; k=rsa; p=" + publicKey
+			}
+			records = append(records, record(selector+dkimHostSuffix, namecheap.RecordTypeTXT, publicKey, hostRecordFixedMXPref))
+		}
+	}
+
+	rua := d.Get("dmarc_rua").([]interface{})
+	if policy := d.Get("dmarc_policy").(string); policy != "" {
+		dmarc := "v=DMARC1; p=" + policy
+		if len(rua) > 0 {
+			addresses := make([]string, 0, len(rua))
+			for _, address := range rua {
+				addresses = append(addresses, "mailto:"+address.(string))
+			}
+			dmarc += "; rua=" + strings.Join(addresses, ",")
+		}
+		if pct := d.Get("dmarc_pct").(int); pct != 100 {
+			dmarc += "; pct=" + strconv.Itoa(pct)
+		}
+		records = append(records, record(dmarcHost, namecheap.RecordTypeTXT, dmarc, hostRecordFixedMXPref))
+	} else if len(rua) > 0 {
+		return nil, fmt.Errorf("dmarc_rua requires dmarc_policy")
+	}
+
+	emailSetupSortRecords(records)
+	return records, nil
+}
+
+// emailSetupSPFWarning warns when the SPF record among records has more
+// top-level lookups than spfMaxLookups (see spfTopLevelLookupCount), and
+// returns nothing otherwise.
+func emailSetupSPFWarning(records []namecheap.DomainsDNSHostRecord) diag.Diagnostics {
+	for _, record := range records {
+		address := derefString(record.Address)
+		if derefString(record.RecordType) != namecheap.RecordTypeTXT || !strings.HasPrefix(address, "v=spf1 ") {
+			continue
+		}
+		lookups := spfTopLevelLookupCount(address)
+		if lookups <= spfMaxLookups {
+			return nil
+		}
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "SPF record is over the DNS lookup limit",
+			Detail: fmt.Sprintf("The SPF record %q has %d DNS-querying terms of its own, over the limit of %d receivers enforce, "+
+				"before counting the lookups of the records its includes point to. Receivers will fail its check; "+
+				"replace includes with ip4/ip6 mechanisms or drop some of them.", address, lookups, spfMaxLookups),
+		}}
+	}
+	return nil
+}
+
+// emailSetupSortRecords orders records by host, type, address and preference,
+// the order the records attribute is kept in so it only diffs on content.
+func emailSetupSortRecords(records []namecheap.DomainsDNSHostRecord) {
+	key := func(r namecheap.DomainsDNSHostRecord) string {
+		n := namecheap.NormalizeRecord(r)
+		return fmt.Sprintf("%s\x00%s\x00%03d\x00%s", *n.HostName, *n.RecordType, derefUInt8(n.MXPref), *n.Address)
+	}
+	sort.SliceStable(records, func(i, j int) bool { return key(records[i]) < key(records[j]) })
+}
+
+// derefUInt8 returns the value p points to, or 0 for nil.
+func derefUInt8(p *uint8) uint8 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+// emailSetupSlotOf returns the slot record belongs in.
+func emailSetupSlotOf(record namecheap.DomainsDNSHostRecord) emailSetupSlot {
+	n := namecheap.NormalizeRecord(record)
+	return emailSetupSlot{
+		hostname:   *n.HostName,
+		recordType: *n.RecordType,
+		spf:        *n.HostName == "@" && *n.RecordType == namecheap.RecordTypeTXT,
+	}
+}
+
+// holds reports whether record sits in slot. The apex TXT slot only holds the
+// SPF policy, so a site verification TXT next to it is never touched.
+func (slot emailSetupSlot) holds(record namecheap.DomainsDNSHostRecord) bool {
+	n := namecheap.NormalizeRecord(record)
+	if *n.HostName != slot.hostname || *n.RecordType != slot.recordType {
+		return false
+	}
+	return !slot.spf || strings.HasPrefix(strings.ToLower(strings.Trim(*n.Address, `" `)), "v=spf1")
+}
+
+// selector picks the records in slot out of the zone. The SPF slot has to name
+// the address too, as read, so the apex's other TXT records are left alone.
+func (slot emailSetupSlot) selector(address string) namecheap.RecordSelector {
+	selector := namecheap.RecordSelector{
+		HostName:   namecheap.String(slot.hostname),
+		RecordType: namecheap.String(slot.recordType),
+	}
+	if slot.spf {
+		selector.Address = namecheap.String(address)
+	}
+	return selector
+}
+
+// emailSetupSlots returns the slots records occupy, each once, in the order
+// they are first seen.
+func emailSetupSlots(records ...[]namecheap.DomainsDNSHostRecord) []emailSetupSlot {
+	seen := map[emailSetupSlot]bool{}
+	var slots []emailSetupSlot
+	for _, set := range records {
+		for _, record := range set {
+			if slot := emailSetupSlotOf(record); !seen[slot] {
+				seen[slot] = true
+				slots = append(slots, slot)
+			}
+		}
+	}
+	return slots
+}
+
+// emailSetupInSlot returns the records in records that sit in slot.
+func emailSetupInSlot(slot emailSetupSlot, records []namecheap.DomainsDNSHostRecord) []namecheap.DomainsDNSHostRecord {
+	var held []namecheap.DomainsDNSHostRecord
+	for _, record := range records {
+		if slot.holds(record) {
+			held = append(held, record)
+		}
+	}
+	return held
+}
+
+// emailSetupSameRecords reports whether a and b hold the same records, in any
+// order, compared the way the SDK verifies its writes.
+func emailSetupSameRecords(a, b []namecheap.DomainsDNSHostRecord) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	matched := make([]bool, len(b))
+	for _, x := range a {
+		found := false
+		for i, y := range b {
+			if !matched[i] && namecheap.RecordsEqual(x, y) {
+				matched[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// emailSetupRecordsFromState reads the records attribute back into SDK records.
+func emailSetupRecordsFromState(raw interface{}) []namecheap.DomainsDNSHostRecord {
+	var records []namecheap.DomainsDNSHostRecord
+	for _, item := range raw.([]interface{}) {
+		r := item.(map[string]interface{})
+		records = append(records, namecheap.DomainsDNSHostRecord{
+			HostName:   namecheap.String(r["hostname"].(string)),
+			RecordType: namecheap.String(r["type"].(string)),
+			Address:    namecheap.String(r["address"].(string)),
+			MXPref:     namecheap.UInt8(uint8(r["mx_pref"].(int))),
+			TTL:        namecheap.Int(r["ttl"].(int)),
+		})
+	}
+	return records
+}
+
+// flattenEmailSetupRecords renders records for the records attribute.
+func flattenEmailSetupRecords(records []namecheap.DomainsDNSHostRecord) []interface{} {
+	result := make([]interface{}, 0, len(records))
+	for _, record := range records {
+		result = append(result, map[string]interface{}{
+			"hostname": derefString(record.HostName),
+			"type":     derefString(record.RecordType),
+			"address":  derefString(record.Address),
+			"mx_pref":  int(derefUInt8(record.MXPref)),
+			"ttl":      derefInt(record.TTL),
+		})
+	}
+	return result
+}
+
+// emailSetupZone reads domain's live records and email type.
+func emailSetupZone(ctx context.Context, client *namecheap.Client, domain string) ([]namecheap.DomainsDNSHostRecord, string, error) {
+	resp, err := client.DomainsDNS.GetHostsWithContext(ctx, domain)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := validateGetHostsResponse(resp); err != nil {
+		return nil, "", err
+	}
+	var records []namecheap.DomainsDNSHostRecord
+	if resp.DomainDNSGetHostsResult.Hosts != nil {
+		for _, host := range *resp.DomainDNSGetHostsResult.Hosts {
+			records = append(records, namecheap.RecordFromDetailed(host))
+		}
+	}
+	return records, derefString(resp.DomainDNSGetHostsResult.EmailType), nil
+}
+
+func resourceNamecheapEmailSetupCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, _ interface{}) error {
+	// An input known only at apply - a DKIM key from another resource, say -
+	// leaves the records to be worked out then.
+	if !diff.GetRawConfig().IsWhollyKnown() {
+		if err := diff.SetNewComputed("records"); err != nil {
+			return err
+		}
+		return diff.SetNew("email_type", namecheap.EmailTypeMX)
+	}
+
+	desired, err := emailSetupDesired(diff)
+	if err != nil {
+		return err
+	}
+
+	// The SDK cannot attach a warning to a plan, so an SPF record over the
+	// lookup limit is only logged here; apply writes it anyway and repeats the
+	// warning then.
+	for _, warning := range emailSetupSPFWarning(desired) {
+		log.Printf("[WARN] namecheap: %s: %s", warning.Summary, warning.Detail)
+	}
+
+	// Only a change goes into the plan: the records compare by content, not by
+	// the order or spelling state happens to hold them in.
+	if !emailSetupSameRecords(desired, emailSetupRecordsFromState(diff.Get("records"))) {
+		if err := diff.SetNew("records", flattenEmailSetupRecords(desired)); err != nil {
+			return err
+		}
+	}
+	if diff.Get("email_type").(string) != namecheap.EmailTypeMX {
+		return diff.SetNew("email_type", namecheap.EmailTypeMX)
+	}
+	return nil
+}
+
+func resourceNamecheapEmailSetupCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	domain := strings.ToLower(data.Get("domain").(string))
+
+	// The ID is set before the first write: should a later slot fail, the
+	// resource is saved as tainted and the records already written are
+	// destroyed with it rather than orphaned.
+	data.SetId(domain)
+	diags := emailSetupApply(ctx, client, data, domain, nil)
+	if diags.HasError() {
+		return diags
+	}
+	return append(diags, resourceNamecheapEmailSetupRead(ctx, data, meta)...)
+}
+
+func resourceNamecheapEmailSetupRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	domain := strings.ToLower(data.Get("domain").(string))
+
+	zone, emailType, err := emailSetupZone(ctx, client, domain)
+	if err != nil {
+		if isDomainGoneError(err) {
+			data.SetId("")
+			return nil
+		}
+		return diagFromClientError(err)
+	}
+
+	// The slots read are the ones state already claims plus the ones the
+	// configuration asks for, so a record removed from either is still seen.
+	desired, _ := emailSetupDesired(data)
+	var live []namecheap.DomainsDNSHostRecord
+	for _, slot := range emailSetupSlots(emailSetupRecordsFromState(data.Get("records")), desired) {
+		for _, record := range emailSetupInSlot(slot, zone) {
+			// A record that is what the configuration asks for is reported in the
+			// configuration's spelling, without the trailing dot the API adds to
+			// an MX or CNAME target, so the plan made before apply still holds.
+			for _, want := range desired {
+				if namecheap.RecordsEqual(record, want) {
+					record = want
+					break
+				}
+			}
+			live = append(live, record)
+		}
+	}
+	emailSetupSortRecords(live)
+
+	if err := data.Set("records", flattenEmailSetupRecords(live)); err != nil {
+		return diag.FromErr(err)
+	}
+	_ = data.Set("email_type", emailType)
+	return nil
+}
+
+func resourceNamecheapEmailSetupUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	domain := strings.ToLower(data.Get("domain").(string))
+
+	before, _ := data.GetChange("records")
+	diags := emailSetupApply(ctx, client, data, domain, emailSetupRecordsFromState(before))
+	if diags.HasError() {
+		// SDKv2 persists the planned records when an update fails; read the
+		// zone back instead so state shows what was actually written.
+		_ = resourceNamecheapEmailSetupRead(ctx, data, meta)
+		return diags
+	}
+	return append(diags, resourceNamecheapEmailSetupRead(ctx, data, meta)...)
+}
+
+// emailSetupApply writes every slot the desired records or prior occupy whose
+// live records differ from the desired ones. A slot that is no longer wanted -
+// a DKIM selector removed from the configuration - is emptied. The
+// diagnostics carry emailSetupSPFWarning's warning alongside any error.
+func emailSetupApply(ctx context.Context, client *namecheap.Client, data *schema.ResourceData, domain string, prior []namecheap.DomainsDNSHostRecord) diag.Diagnostics {
+	desired, err := emailSetupDesired(data)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	diags := emailSetupSPFWarning(desired)
+
+	if lockDiags := lockDomain(ctx, domain); lockDiags != nil {
+		return append(diags, lockDiags...)
+	}
+	defer ncMutexKV.Unlock(domain)
+
+	zone, emailType, err := emailSetupZone(ctx, client, domain)
+	if err != nil {
+		return append(diags, diagFromClientError(err)...)
+	}
+
+	// The MX slot goes first, then the slots being emptied: a DKIM selector
+	// moving from a TXT key to a CNAME cannot have its CNAME added while the
+	// TXT still exists.
+	slots := emailSetupSlots(desired, prior)
+	rank := func(slot emailSetupSlot) int {
+		switch {
+		case slot.recordType == namecheap.RecordTypeMX:
+			return 0
+		case len(emailSetupInSlot(slot, desired)) == 0:
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.SliceStable(slots, func(i, j int) bool { return rank(slots[i]) < rank(slots[j]) })
+
+	for _, slot := range slots {
+		live, want := emailSetupInSlot(slot, zone), emailSetupInSlot(slot, desired)
+		// The MX slot also carries the email type, so it is rewritten whenever
+		// the domain is not on MX even when its records already match.
+		switchType := slot.recordType == namecheap.RecordTypeMX && emailType != namecheap.EmailTypeMX
+		if emailSetupSameRecords(live, want) && !switchType {
+			continue
+		}
+		if err := emailSetupWriteSlot(ctx, client, domain, slot, live, want); err != nil {
+			return append(diags, hostRecordWriteError(domain, "update", err)...)
+		}
+	}
+	return diags
+}
+
+// emailSetupWriteSlot replaces the live records in slot with want.
+func emailSetupWriteSlot(ctx context.Context, client *namecheap.Client, domain string, slot emailSetupSlot, live, want []namecheap.DomainsDNSHostRecord) error {
+	opts := []namecheap.RecordOption{namecheap.WithRetryOnConflict(hostRecordRetryAttempts)}
+	if slot.recordType == namecheap.RecordTypeMX {
+		opts = append(opts, namecheap.WithEmailType(namecheap.EmailTypeMX))
+	}
+
+	if !slot.spf {
+		_, err := client.DomainsDNS.UpsertRecordsWithContext(ctx, domain, slot.selector(""), want, opts...)
+		return err
+	}
+
+	// A zone can hold several SPF records, which receivers treat as a
+	// permerror; all but the first are removed and the first replaced.
+	if len(live) == 0 {
+		_, err := client.DomainsDNS.AddRecordsWithContext(ctx, domain, want, opts...)
+		return err
+	}
+	for _, extra := range live[1:] {
+		if _, err := client.DomainsDNS.DeleteRecordsWithContext(ctx, domain, slot.selector(derefString(extra.Address)), opts...); err != nil {
+			return err
+		}
+	}
+	_, err := client.DomainsDNS.UpsertRecordsWithContext(ctx, domain, slot.selector(derefString(live[0].Address)), want, opts...)
+	return err
+}
+
+func resourceNamecheapEmailSetupDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+	domain := strings.ToLower(data.Get("domain").(string))
+
+	if diags := lockDomain(ctx, domain); diags != nil {
+		return diags
+	}
+	defer ncMutexKV.Unlock(domain)
+
+	zone, _, err := emailSetupZone(ctx, client, domain)
+	if err != nil {
+		if isDomainGoneError(err) {
+			return nil
+		}
+		return diagFromClientError(err)
+	}
+
+	// Only records this resource manages are deleted, each only if the zone
+	// still holds it: an SPF policy someone rewrote by hand is theirs now.
+	for _, record := range emailSetupRecordsFromState(data.Get("records")) {
+		if !emailSetupZoneHolds(zone, record) {
+			continue
+		}
+		selector := hostRecordSelector(record)
+		opts := []namecheap.RecordOption{namecheap.WithRetryOnConflict(hostRecordRetryAttempts)}
+		if emailSetupSlotOf(record).recordType == namecheap.RecordTypeMX && emailSetupCountMX(zone) == 1 {
+			// The last MX record goes, and with it the MX email type.
+			opts = append(opts, namecheap.WithEmailType(namecheap.EmailTypeNone))
+		}
+		if _, err := client.DomainsDNS.DeleteRecordsWithContext(ctx, domain, selector, opts...); err != nil {
+			return hostRecordWriteError(domain, "delete", err)
+		}
+		zone = emailSetupWithout(zone, record)
+	}
+
+	data.SetId("")
+	return nil
+}
+
+// emailSetupZoneHolds reports whether zone holds record, by identity: host,
+// type, address and the MX preference, but not the TTL.
+func emailSetupZoneHolds(zone []namecheap.DomainsDNSHostRecord, record namecheap.DomainsDNSHostRecord) bool {
+	for _, live := range zone {
+		if hostRecordIdentityMatches(live, record) {
+			return true
+		}
+	}
+	return false
+}
+
+// emailSetupCountMX counts the MX records in zone.
+func emailSetupCountMX(zone []namecheap.DomainsDNSHostRecord) int {
+	count := 0
+	for _, record := range zone {
+		if strings.EqualFold(derefString(record.RecordType), namecheap.RecordTypeMX) {
+			count++
+		}
+	}
+	return count
+}
+
+// emailSetupWithout returns zone without the records sharing record's identity,
+// mirroring a delete just made so the next one is decided on the current zone.
+func emailSetupWithout(zone []namecheap.DomainsDNSHostRecord, record namecheap.DomainsDNSHostRecord) []namecheap.DomainsDNSHostRecord {
+	var kept []namecheap.DomainsDNSHostRecord
+	for _, live := range zone {
+		if !hostRecordIdentityMatches(live, record) {
+			kept = append(kept, live)
+		}
+	}
+	return kept
+}
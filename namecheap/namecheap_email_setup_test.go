@@ -0,0 +1,296 @@
+package namecheap_provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// These are the pure-function tests for namecheap_email_setup: what each
+// configuration publishes and which records belong to it. The CRUD paths are
+// covered by the mock acceptance suite (mock_email_setup_test.go).
+
+func emailSetupData(t *testing.T, raw map[string]interface{}) *schema.ResourceData {
+	t.Helper()
+	if _, ok := raw["domain"]; !ok {
+		raw["domain"] = "example.com"
+	}
+	return schema.TestResourceDataRaw(t, resourceNamecheapEmailSetup().Schema, raw)
+}
+
+// emailSetupSummary renders records as "host TYPE address pref" lines.
+func emailSetupSummary(records []namecheap.DomainsDNSHostRecord) []string {
+	var lines []string
+	for _, r := range records {
+		line := derefString(r.HostName) + " " + derefString(r.RecordType) + " " + derefString(r.Address)
+		if derefString(r.RecordType) == namecheap.RecordTypeMX {
+			line += fmt.Sprintf(" %02d", derefUInt8(r.MXPref))
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestEmailSetupDesired_Presets(t *testing.T) {
+	tests := []struct {
+		provider string
+		want     []string
+	}{
+		{emailProviderGoogle, []string{
+			"@ MX smtp.google.com 01",
+			"@ TXT v=spf1 include:_spf.google.com ~all",
+		}},
+		{emailProviderMicrosoft365, []string{
+			"@ MX example-com.mail.protection.outlook.com 00",
+			"@ TXT v=spf1 include:spf.protection.outlook.com ~all",
+		}},
+		{emailProviderPrivateEmail, []string{
+			"@ MX mx1.privateemail.com 10",
+			"@ MX mx2.privateemail.com 10",
+			"@ TXT v=spf1 include:spf.privateemail.com ~all",
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.provider, func(t *testing.T) {
+			records, err := emailSetupDesired(emailSetupData(t, map[string]interface{}{"email_provider": tc.provider}))
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, emailSetupSummary(records))
+		})
+	}
+}
+
+func TestEmailSetupDesired_Custom(t *testing.T) {
+	records, err := emailSetupDesired(emailSetupData(t, map[string]interface{}{
+		"email_provider": emailProviderCustom,
+		"mx": []interface{}{
+			map[string]interface{}{"address": "mx1.example.net", "mx_pref": 10},
+			map[string]interface{}{"address": "mx2.example.net", "mx_pref": 20},
+		},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"@ MX mx1.example.net 10",
+		"@ MX mx2.example.net 20",
+		"@ TXT v=spf1 mx ~all",
+	}, emailSetupSummary(records), "with no SPF terms configured, the MX hosts are what is authorized to send")
+}
+
+func TestEmailSetupDesired_MXBlocks(t *testing.T) {
+	_, err := emailSetupDesired(emailSetupData(t, map[string]interface{}{"email_provider": emailProviderCustom}))
+	assert.ErrorContains(t, err, "requires at least one mx block")
+
+	_, err = emailSetupDesired(emailSetupData(t, map[string]interface{}{
+		"email_provider": emailProviderGoogle,
+		"mx":             []interface{}{map[string]interface{}{"address": "mx.example.net"}},
+	}))
+	assert.ErrorContains(t, err, `only allowed with email_provider = "custom"`)
+}
+
+func TestEmailSetupDesired_FullSetup(t *testing.T) {
+	records, err := emailSetupDesired(emailSetupData(t, map[string]interface{}{
+		"email_provider": emailProviderGoogle,
+		"spf_includes":   []interface{}{"mailgun.org"},
+		"spf_mechanisms": []interface{}{"ip4:203.0.113.0/24"},
+		"spf_all":        "-all",
+		"dkim": []interface{}{
+			map[string]interface{}{"selector": "google", "public_key": "MIIBIjAN"},
+			map[string]interface{}{"selector": "selector1", "target": "selector1-example-com._domainkey.example.onmicrosoft.com"},
+			map[string]interface{}{"selector": "mg", "public_key": "v=DKIM1; k=ed25519; p=abc"},
+		},
+		"dmarc_policy": "quarantine",
+		"dmarc_rua":    []interface{}{"dmarc@example.com", "reports@example.net"},
+		"dmarc_pct":    50,
+		"ttl":          600,
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"@ MX smtp.google.com 01",
+		"@ TXT v=spf1 include:_spf.google.com include:mailgun.org ip4:203.0.113.0/24 -all",
+		"_dmarc TXT v=DMARC1; p=quarantine; rua=mailto:dmarc@example.com,mailto:reports@example.net; pct=50",
+		"google._domainkey TXT v=DKIM1; k=rsa; p=MIIBIjAN",
+		"mg._domainkey TXT v=DKIM1; k=ed25519; p=abc",
+		"selector1._domainkey CNAME selector1-example-com._domainkey.example.onmicrosoft.com",
+	}, emailSetupSummary(records))
+	for _, r := range records {
+		assert.Equal(t, 600, derefInt(r.TTL))
+	}
+}
+
+func TestEmailSetupDesired_Refusals(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  map[string]interface{}
+		want string
+	}{
+		{"dkim without key or target", map[string]interface{}{
+			"dkim": []interface{}{map[string]interface{}{"selector": "s1"}},
+		}, `dkim selector "s1" needs exactly one of public_key and target`},
+		{"dkim with key and target", map[string]interface{}{
+			"dkim": []interface{}{map[string]interface{}{"selector": "s1", "public_key": "k", "target": "t.example.net"}},
+		}, `dkim selector "s1" needs exactly one of public_key and target`},
+		{"duplicate selector", map[string]interface{}{
+			"dkim": []interface{}{
+				map[string]interface{}{"selector": "s1", "public_key": "k"},
+				map[string]interface{}{"selector": "S1", "public_key": "k"},
+			},
+		}, `dkim selector "s1" is configured more than once`},
+		{"rua without policy", map[string]interface{}{
+			"dmarc_rua": []interface{}{"dmarc@example.com"},
+		}, "dmarc_rua requires dmarc_policy"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.raw["email_provider"] = emailProviderGoogle
+			_, err := emailSetupDesired(emailSetupData(t, tc.raw))
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+func TestEmailSetupSPFWarning(t *testing.T) {
+	desired := func(includes ...interface{}) []namecheap.DomainsDNSHostRecord {
+		records, err := emailSetupDesired(emailSetupData(t, map[string]interface{}{
+			"email_provider": emailProviderGoogle,
+			"spf_includes":   includes,
+			"spf_mechanisms": []interface{}{"a", "mx", "exists:%{i}.example"},
+		}))
+		require.NoError(t, err)
+		return records
+	}
+
+	assert.Empty(t, emailSetupSPFWarning(desired("a.example", "b.example", "c.example", "d.example", "e.example", "f.example")), "exactly the limit")
+
+	diags := emailSetupSPFWarning(desired("a.example", "b.example", "c.example", "d.example", "e.example", "f.example", "g.example"))
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Contains(t, diags[0].Detail, "has 11 DNS-querying terms of its own, over the limit of 10")
+}
+
+// An SPF record over the limit still plans, and the plan logs the warning the
+// SDK has no way to show.
+func TestEmailSetupCustomizeDiff_SPFOverLimitPlans(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	config := map[string]interface{}{
+		"domain":         "example.com",
+		"email_provider": emailProviderGoogle,
+		"spf_includes":   []interface{}{"a.example", "b.example", "c.example", "d.example", "e.example", "f.example", "g.example"},
+		"spf_mechanisms": []interface{}{"a", "mx", "exists:%{i}.example"},
+	}
+	resource := resourceNamecheapEmailSetup()
+	encoded, err := json.Marshal(config)
+	require.NoError(t, err)
+	instance := &terraform.InstanceState{}
+	instance.RawConfig, err = ctyjson.Unmarshal(encoded, resource.CoreConfigSchema().ImpliedType())
+	require.NoError(t, err)
+
+	diff, err := schema.InternalMap(resource.Schema).Diff(context.Background(), instance,
+		terraform.NewResourceConfigRaw(config), resource.CustomizeDiff, nil, true)
+	require.NoError(t, err)
+	require.NotNil(t, diff)
+	assert.Contains(t, diff.Attributes, "records.#", "the over-limit record is still planned")
+	assert.Contains(t, logged.String(), "[WARN] namecheap: SPF record is over the DNS lookup limit")
+}
+
+func TestSPFTopLevelLookupCount(t *testing.T) {
+	tests := []struct {
+		record string
+		want   int
+	}{
+		{"v=spf1 -all", 0},
+		{"v=spf1 ip4:203.0.113.1 ip6:2001:db8::/32 ~all", 0},
+		{"v=spf1 include:_spf.google.com ~all", 1},
+		{"v=spf1 a mx ptr ~all", 3},
+		{"v=spf1 +a:mail.example.com -mx/24 ?include:x.example ~exists:%{i}.example redirect=_spf.example", 5},
+		{"v=spf1 A MX Include:x.example -all", 3},
+	}
+	for _, tc := range tests {
+		t.Run(tc.record, func(t *testing.T) {
+			assert.Equal(t, tc.want, spfTopLevelLookupCount(tc.record))
+		})
+	}
+}
+
+func TestValidateSPFMechanism(t *testing.T) {
+	for _, ok := range []string{"a", "mx:mail.example.com", "ip4:203.0.113.0/24", "-include:x.example"} {
+		_, errs := validateSPFMechanism(ok, "spf_mechanisms.0")
+		assert.Empty(t, errs, ok)
+	}
+	for _, bad := range []string{"", "all", "-all", "ip4:1.2.3.4 ~all", `"a"`, "v=spf1"} {
+		_, errs := validateSPFMechanism(bad, "spf_mechanisms.0")
+		assert.NotEmpty(t, errs, bad)
+	}
+}
+
+func TestValidateDKIMSelector(t *testing.T) {
+	for _, ok := range []string{"google", "selector1", "s_2024", "k1.mail"} {
+		_, errs := validateDKIMSelector(ok, "dkim.0.selector")
+		assert.Empty(t, errs, ok)
+	}
+	for _, bad := range []string{"", "a b", "x._domainkey.", "sel@ctor"} {
+		_, errs := validateDKIMSelector(bad, "dkim.0.selector")
+		assert.NotEmpty(t, errs, bad)
+	}
+}
+
+// TestEmailSetupSlotHoldsOnlySPF is the property that keeps the apex's other
+// TXT records safe: the SPF slot never claims a site verification record.
+func TestEmailSetupSlotHoldsOnlySPF(t *testing.T) {
+	spf := hostRecordFixture("@", "TXT", "v=spf1 include:_spf.google.com ~all", 10)
+	slot := emailSetupSlotOf(spf)
+	assert.True(t, slot.spf)
+
+	assert.True(t, slot.holds(spf))
+	assert.True(t, slot.holds(hostRecordFixture("@", "TXT", `"V=SPF1 -all"`, 10)), "quoting and case do not hide an SPF policy")
+	assert.False(t, slot.holds(hostRecordFixture("@", "TXT", "google-site-verification=abc", 10)))
+	assert.False(t, slot.holds(hostRecordFixture("_dmarc", "TXT", "v=spf1 -all", 10)))
+
+	dmarc := emailSetupSlotOf(hostRecordFixture("_dmarc", "TXT", "v=DMARC1; p=none", 10))
+	assert.False(t, dmarc.spf)
+	assert.True(t, dmarc.holds(hostRecordFixture("_DMARC", "TXT", "anything", 10)), "every record in a non-SPF slot is managed")
+}
+
+func TestEmailSetupSlotsDeduplicate(t *testing.T) {
+	slots := emailSetupSlots(
+		[]namecheap.DomainsDNSHostRecord{
+			hostRecordFixture("@", "MX", "mx1.example.net", 10),
+			hostRecordFixture("@", "MX", "mx2.example.net", 20),
+		},
+		[]namecheap.DomainsDNSHostRecord{
+			hostRecordFixture("@", "MX", "old.example.net", 10),
+			hostRecordFixture("s1._domainkey", "TXT", "v=DKIM1; p=k", 10),
+		},
+	)
+	assert.Equal(t, []emailSetupSlot{
+		{hostname: "@", recordType: "MX"},
+		{hostname: "s1._domainkey", recordType: "TXT"},
+	}, slots)
+}
+
+func TestEmailSetupSameRecords(t *testing.T) {
+	a := []namecheap.DomainsDNSHostRecord{
+		hostRecordFixture("@", "MX", "mx1.example.net", 10),
+		hostRecordFixture("@", "MX", "mx2.example.net", 20),
+	}
+	b := []namecheap.DomainsDNSHostRecord{
+		hostRecordFixture("@", "MX", "mx2.example.net.", 20),
+		hostRecordFixture("@", "MX", "mx1.example.net.", 10),
+	}
+	assert.True(t, emailSetupSameRecords(a, b), "order and the API's trailing dot are not differences")
+	assert.False(t, emailSetupSameRecords(a, b[:1]))
+	assert.False(t, emailSetupSameRecords(a, []namecheap.DomainsDNSHostRecord{b[0], hostRecordFixture("@", "MX", "mx1.example.net", 30)}))
+}
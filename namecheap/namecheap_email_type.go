@@ -0,0 +1,91 @@
+package namecheap_provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+)
+
+// setDomainEmailType switches domain's email_type to emailType and returns the
+// type it replaced. Namecheap only changes the email type through setHosts,
+// which also replaces the domain's records, so the live records are read and
+// written back unchanged alongside the new type - the same path
+// namecheap_domain_records takes - under the domain's ncMutexKV lock.
+//
+// When onlyFrom is set the switch only happens while the domain's current type
+// is onlyFrom, so restoring a type never undoes someone else's later change.
+// Nothing is written when the type already matches; the current type is
+// returned either way.
+//
+// An MX or MXE record cannot outlive a switch to another type: setHosts would
+// either reject it or delete it. Both are refused here before any write, so
+// the caller's records are never lost to a change of email type. A target of
+// MX or MXE without the records it needs is resolved the way namecheap_domain_records
+// resolves it, see resolveEmailType.
+func setDomainEmailType(ctx context.Context, client *namecheap.Client, domain, emailType, onlyFrom string) (string, diag.Diagnostics) {
+	if diags := lockDomain(ctx, domain); diags != nil {
+		return "", diags
+	}
+	defer ncMutexKV.Unlock(domain)
+
+	resp, err := client.DomainsDNS.GetHostsWithContext(ctx, domain)
+	if err != nil {
+		return "", diagFromClientError(err)
+	}
+	if err := validateGetHostsResponse(resp); err != nil {
+		return "", diagFromClientError(err)
+	}
+
+	current := derefString(resp.DomainDNSGetHostsResult.EmailType)
+	if onlyFrom != "" && current != onlyFrom {
+		return current, nil
+	}
+
+	var records []namecheap.DomainsDNSHostRecord
+	if resp.DomainDNSGetHostsResult.Hosts != nil {
+		for _, host := range *resp.DomainDNSGetHostsResult.Hosts {
+			records = append(records, namecheap.RecordFromDetailed(host))
+		}
+	}
+
+	target := derefString(resolveEmailType(&records, &emailType))
+	if current == target {
+		return current, nil
+	}
+
+	if blocking := mailRecordsBlockingEmailType(records, target); len(blocking) > 0 {
+		return current, diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Cannot switch %s's email_type to %s", domain, target),
+			Detail: fmt.Sprintf("%s holds %s, which Namecheap only keeps while email_type matches the record type; switching to %s "+
+				"would delete them. Remove them first - they route the domain's mail elsewhere - or leave the email type as it is.",
+				domain, strings.Join(blocking, ", "), target),
+		}}
+	}
+
+	_, err = client.DomainsDNS.SetHostsWithContext(ctx, &namecheap.DomainsDNSSetHostsArgs{
+		Domain:    namecheap.String(domain),
+		Records:   &records,
+		EmailType: namecheap.String(target),
+	})
+	if err != nil {
+		return current, diagFromClientError(err)
+	}
+	return current, nil
+}
+
+// mailRecordsBlockingEmailType describes the MX and MXE records in records that
+// setHosts would not accept together with emailType.
+func mailRecordsBlockingEmailType(records []namecheap.DomainsDNSHostRecord, emailType string) []string {
+	var blocking []string
+	for _, record := range records {
+		recordType := strings.ToUpper(derefString(record.RecordType))
+		if (recordType == namecheap.RecordTypeMX || recordType == namecheap.RecordTypeMXE) && recordType != emailType {
+			blocking = append(blocking, fmt.Sprintf("%s record %s -> %s", recordType, derefString(record.HostName), derefString(record.Address)))
+		}
+	}
+	return blocking
+}
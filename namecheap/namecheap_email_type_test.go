@@ -0,0 +1,176 @@
+package namecheap_provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// emailTypeTestServer serves a zone of hosts with the given email type and
+// records each setHosts request into *sets. Forwarding commands succeed, so
+// the same server backs namecheap_email_forwarding's CRUD.
+func emailTypeTestServer(t *testing.T, emailType string, hosts []hostEntry, sets *[]url.Values) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		switch r.FormValue("Command") {
+		case "namecheap.domains.dns.getHosts":
+			_, _ = fmt.Fprint(w, getHostsXML(emailType, hosts))
+		case "namecheap.domains.dns.setHosts":
+			*sets = append(*sets, r.Form)
+			_, _ = fmt.Fprint(w, setHostsSuccessXML())
+		case "namecheap.domains.dns.setEmailForwarding":
+			_, _ = fmt.Fprint(w, setEmailForwardingSuccessXML("example.com"))
+		case "namecheap.domains.dns.getEmailForwarding":
+			_, _ = fmt.Fprint(w, getEmailForwardingXML("example.com", map[string]string{"info": "me@example.com"}))
+		default:
+			t.Fatalf("unexpected command: %s", r.FormValue("Command"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+var emailTypeTestZone = []hostEntry{
+	{Name: "@", Type: "A", Address: "203.0.113.10", TTL: 1800, MXPref: 10},
+	{Name: "www", Type: "CNAME", Address: "example.com.", TTL: 600, MXPref: 10},
+}
+
+func TestSetDomainEmailType_PreservesRecords(t *testing.T) {
+	var sets []url.Values
+	server := emailTypeTestServer(t, "NONE", emailTypeTestZone, &sets)
+
+	previous, diags := setDomainEmailType(context.Background(), newTestClient(server.URL), "example.com", namecheap.EmailTypeForward, "")
+
+	assert.Empty(t, diags)
+	assert.Equal(t, "NONE", previous)
+	if assert.Len(t, sets, 1) {
+		assert.Equal(t, "FWD", sets[0].Get("EmailType"))
+		assert.Equal(t, "@", sets[0].Get("HostName1"))
+		assert.Equal(t, "203.0.113.10", sets[0].Get("Address1"))
+		assert.Equal(t, "www", sets[0].Get("HostName2"))
+		assert.Equal(t, "600", sets[0].Get("TTL2"))
+	}
+}
+
+func TestSetDomainEmailType_AlreadySetIsNoOp(t *testing.T) {
+	var sets []url.Values
+	server := emailTypeTestServer(t, "FWD", emailTypeTestZone, &sets)
+
+	previous, diags := setDomainEmailType(context.Background(), newTestClient(server.URL), "example.com", namecheap.EmailTypeForward, "")
+
+	assert.Empty(t, diags)
+	assert.Equal(t, "FWD", previous)
+	assert.Empty(t, sets)
+}
+
+func TestSetDomainEmailType_MXRecordsBlockSwitch(t *testing.T) {
+	var sets []url.Values
+	zone := append([]hostEntry{{Name: "@", Type: "MX", Address: "mail.example.com.", TTL: 1800, MXPref: 10}}, emailTypeTestZone...)
+	server := emailTypeTestServer(t, "MX", zone, &sets)
+
+	_, diags := setDomainEmailType(context.Background(), newTestClient(server.URL), "example.com", namecheap.EmailTypeForward, "")
+
+	if assert.True(t, diags.HasError()) {
+		assert.Contains(t, diags[0].Summary, "Cannot switch example.com's email_type to FWD")
+		assert.Contains(t, diags[0].Detail, "MX record @ -> mail.example.com.")
+	}
+	assert.Empty(t, sets, "nothing may be written when the switch would delete records")
+}
+
+func TestSetDomainEmailType_OnlyFrom(t *testing.T) {
+	var sets []url.Values
+	server := emailTypeTestServer(t, "MXE", emailTypeTestZone, &sets)
+
+	current, diags := setDomainEmailType(context.Background(), newTestClient(server.URL), "example.com", "NONE", namecheap.EmailTypeForward)
+
+	assert.Empty(t, diags)
+	assert.Equal(t, "MXE", current)
+	assert.Empty(t, sets, "a type changed by someone else must not be overwritten")
+}
+
+func TestSetDomainEmailType_RestoreResolvesMissingMX(t *testing.T) {
+	var sets []url.Values
+	server := emailTypeTestServer(t, "FWD", emailTypeTestZone, &sets)
+
+	_, diags := setDomainEmailType(context.Background(), newTestClient(server.URL), "example.com", namecheap.EmailTypeMX, namecheap.EmailTypeForward)
+
+	assert.Empty(t, diags)
+	if assert.Len(t, sets, 1) {
+		assert.Equal(t, "NONE", sets[0].Get("EmailType"), "MX without MX records resolves to NONE, as for namecheap_domain_records")
+	}
+}
+
+func emailForwardingManagedData(t *testing.T, previous string) *schema.ResourceData {
+	t.Helper()
+	d := schema.TestResourceDataRaw(t, resourceNamecheapEmailForwarding().Schema, map[string]interface{}{
+		"domain":            "example.com",
+		"forwards":          map[string]interface{}{"info": "me@example.com"},
+		"manage_email_type": true,
+	})
+	require.NoError(t, d.Set("previous_email_type", previous))
+	return d
+}
+
+func TestResourceEmailForwardingCreate_ManageEmailType(t *testing.T) {
+	var sets []url.Values
+	server := emailTypeTestServer(t, "NONE", emailTypeTestZone, &sets)
+
+	d := emailForwardingManagedData(t, "")
+	diags := resourceEmailForwardingCreate(context.Background(), d, testMeta(newTestClient(server.URL)))
+
+	assert.Empty(t, diags, "a managed email type leaves nothing to warn about")
+	assert.Equal(t, "example.com", d.Id())
+	assert.Equal(t, "NONE", d.Get("previous_email_type"))
+	if assert.Len(t, sets, 1) {
+		assert.Equal(t, "FWD", sets[0].Get("EmailType"))
+	}
+}
+
+func TestResourceEmailForwardingUpdate_KeepsFirstPreviousEmailType(t *testing.T) {
+	var sets []url.Values
+	server := emailTypeTestServer(t, "FWD", emailTypeTestZone, &sets)
+
+	d := emailForwardingManagedData(t, "MXE")
+	d.SetId("example.com")
+	diags := resourceEmailForwardingUpdate(context.Background(), d, testMeta(newTestClient(server.URL)))
+
+	assert.Empty(t, diags)
+	assert.Equal(t, "MXE", d.Get("previous_email_type"))
+	assert.Empty(t, sets)
+}
+
+func TestResourceEmailForwardingRead_ManagedEmailTypeDrift(t *testing.T) {
+	var sets []url.Values
+	server := emailTypeTestServer(t, "NONE", emailTypeTestZone, &sets)
+
+	d := emailForwardingManagedData(t, "NONE")
+	d.SetId("example.com")
+	diags := resourceEmailForwardingRead(context.Background(), d, testMeta(newTestClient(server.URL)))
+
+	assert.Empty(t, diags)
+	assert.False(t, d.Get("manage_email_type").(bool), "a domain moved off FWD must read as drift")
+}
+
+func TestResourceEmailForwardingDelete_RestoresPreviousEmailType(t *testing.T) {
+	var sets []url.Values
+	server := emailTypeTestServer(t, "FWD", emailTypeTestZone, &sets)
+
+	d := emailForwardingManagedData(t, "NONE")
+	d.SetId("example.com")
+	diags := resourceEmailForwardingDelete(context.Background(), d, testMeta(newTestClient(server.URL)))
+
+	assert.Empty(t, diags)
+	if assert.Len(t, sets, 1) {
+		assert.Equal(t, "NONE", sets[0].Get("EmailType"))
+		assert.Equal(t, "203.0.113.10", sets[0].Get("Address1"))
+	}
+}
@@ -0,0 +1,336 @@
+package namecheap_provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+)
+
+// extendedAttribute describes one TLD-specific registry attribute that
+// Namecheap accepts alongside a domain's contacts (for example CIRALegalType
+// for .ca). The tables below only cover what the registry requires or
+// constrains; they are not a copy of every attribute Namecheap documents.
+type extendedAttribute struct {
+	name        string
+	description string
+	// values, when set, are the only accepted values.
+	values []string
+	// pattern, when set, must match the value.
+	pattern  *regexp.Regexp
+	required bool
+	// requiredWhenAttr and requiredWhenValues make the attribute required only
+	// when another attribute holds one of the listed values.
+	requiredWhenAttr   string
+	requiredWhenValues []string
+}
+
+// extendedAttributeLanguageRegexp matches a two-letter ISO 639-1 language code.
+var extendedAttributeLanguageRegexp = regexp.MustCompile(`^[a-z]{2}$`)
+
+// ukExtendedAttributes builds the table shared by the .uk family, whose
+// attribute names differ only by a per-TLD prefix (UK, COUK, ORGUK, MEUK).
+func ukExtendedAttributes(prefix string) []extendedAttribute {
+	return []extendedAttribute{
+		{
+			name:        prefix + "LegalType",
+			description: "the registrant type",
+			values:      []string{"IND", "FIND", "LTD", "PLC", "PTNR", "LLP", "IP", "STRA", "SCH", "RCHAR", "GOV", "CRO", "STAT", "OTHER", "FCORP", "FOTHER"},
+			required:    true,
+		},
+		{
+			name:               prefix + "CompanyID",
+			description:        "the registrant's Companies House or charity number",
+			requiredWhenAttr:   prefix + "LegalType",
+			requiredWhenValues: []string{"LTD", "PLC", "LLP", "IP", "SCH", "RCHAR"},
+		},
+		{
+			name:        prefix + "Registeredfor",
+			description: "the name the domain is registered for, when it differs from the registrant",
+		},
+	}
+}
+
+// extendedAttributeTables maps a TLD, without the leading dot, to the extended
+// attributes Namecheap takes for it. The longest matching suffix wins, so
+// co.uk domains use the co.uk table rather than the uk one.
+var extendedAttributeTables = map[string][]extendedAttribute{
+	"us": {
+		{
+			name:        "RegistrantNexus",
+			description: "the registrant's US nexus category",
+			values:      []string{"C11", "C12", "C21", "C31", "C32"},
+			required:    true,
+		},
+		{
+			name:               "RegistrantNexusCountry",
+			description:        "the two-letter code of the country a foreign registrant's US presence is based in",
+			pattern:            contactCountryRegexp,
+			requiredWhenAttr:   "RegistrantNexus",
+			requiredWhenValues: []string{"C31", "C32"},
+		},
+		{
+			name:        "RegistrantPurpose",
+			description: "the intended use of the domain",
+			values:      []string{"P1", "P2", "P3", "P4", "P5"},
+			required:    true,
+		},
+	},
+	"ca": {
+		{
+			name:        "CIRALegalType",
+			description: "the registrant's legal type",
+			values: []string{"CCO", "CCT", "RES", "GOV", "EDU", "ASS", "HOP", "PRT", "TDM", "TRD", "PLT", "LAM",
+				"TRS", "ABO", "INB", "LGR", "OMK", "MAJ"},
+			required: true,
+		},
+		{
+			name:        "CIRAAgreementVersion",
+			description: "the version of the CIRA registrant agreement accepted",
+			values:      []string{"2.0"},
+			required:    true,
+		},
+		{
+			name:        "CIRAAgreementValue",
+			description: "acceptance of the CIRA registrant agreement",
+			values:      []string{"Y"},
+			required:    true,
+		},
+		{
+			name:        "CIRAWhoisDisplay",
+			description: "whether the registrant's details are shown in WHOIS",
+			values:      []string{"Full", "Private"},
+		},
+		{
+			name:        "CIRALanguage",
+			description: "the registrant's preferred language",
+			values:      []string{"en", "fr"},
+		},
+	},
+	"eu": {
+		{
+			name:        "EUAgreeWhoisPolicy",
+			description: "acceptance of the EURid WHOIS policy",
+			values:      []string{"YES"},
+			required:    true,
+		},
+		{
+			name:        "EUAgreeDeletePolicy",
+			description: "acceptance of the EURid deletion policy",
+			values:      []string{"YES"},
+			required:    true,
+		},
+		{
+			name:        "EUCountryOfCitizenship",
+			description: "the two-letter code of the EU country a registrant resident outside the EU/EEA is a citizen of",
+			pattern:     contactCountryRegexp,
+		},
+		{
+			name:        "EUADRLang",
+			description: "the two-letter code of the language for alternative dispute resolution",
+			pattern:     extendedAttributeLanguageRegexp,
+		},
+	},
+	"uk":     ukExtendedAttributes("UK"),
+	"co.uk":  ukExtendedAttributes("COUK"),
+	"org.uk": ukExtendedAttributes("ORGUK"),
+	"me.uk":  ukExtendedAttributes("MEUK"),
+}
+
+// extendedAttributeTLD returns the extendedAttributeTables key for domain, or
+// "" when its TLD has no table.
+func extendedAttributeTLD(domain string) string {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	match := ""
+	for tld := range extendedAttributeTables {
+		if strings.HasSuffix(domain, "."+tld) && len(tld) > len(match) {
+			match = tld
+		}
+	}
+	return match
+}
+
+// setContactsReservedParams are the setContacts parameters the provider sets
+// itself, which an extended attribute must not override.
+var setContactsReservedParams = map[string]bool{
+	"Command": true, "DomainName": true, "ApiUser": true, "ApiKey": true, "UserName": true, "ClientIp": true,
+}
+
+// isReservedSetContactsParam reports whether name is a parameter the provider
+// already sends with setContacts: the call's own parameters, or a contact
+// field under one of the four block prefixes.
+func isReservedSetContactsParam(name string) bool {
+	if setContactsReservedParams[name] {
+		return true
+	}
+	for _, prefix := range []string{"Registrant", "Tech", "Admin", "AuxBilling"} {
+		for _, f := range contactSchemaFields {
+			if name == prefix+f.structField {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateExtendedAttributes checks attrs against the table for domain's TLD,
+// reporting every problem at once. A TLD without a table accepts any
+// attribute that does not clash with the contact parameters, so attributes
+// the tables do not cover can still be sent.
+func validateExtendedAttributes(domain string, attrs map[string]string) error {
+	var errs []error
+	for _, name := range sortedKeys(attrs) {
+		if isReservedSetContactsParam(name) {
+			errs = append(errs, fmt.Errorf("extended_attributes: %s is sent by the provider itself and cannot be set as an extended attribute", name))
+		}
+	}
+
+	tld := extendedAttributeTLD(domain)
+	table := extendedAttributeTables[tld]
+	if table == nil {
+		return errors.Join(errs...)
+	}
+
+	known := make(map[string]bool, len(table))
+	for _, attr := range table {
+		known[attr.name] = true
+		value, ok := attrs[attr.name]
+
+		if !ok {
+			switch {
+			case attr.required:
+				errs = append(errs, fmt.Errorf("extended_attributes: .%s domains require %s (%s%s)", tld, attr.name, attr.description, attr.valuesHint()))
+			case attr.requiredWhenAttr != "" && slices.Contains(attr.requiredWhenValues, attrs[attr.requiredWhenAttr]):
+				errs = append(errs, fmt.Errorf("extended_attributes: .%s domains with %s %s require %s (%s%s)",
+					tld, attr.requiredWhenAttr, attrs[attr.requiredWhenAttr], attr.name, attr.description, attr.valuesHint()))
+			}
+			continue
+		}
+
+		switch {
+		case len(attr.values) > 0 && !slices.Contains(attr.values, value):
+			errs = append(errs, fmt.Errorf("extended_attributes: %s = %q is not valid for .%s domains; expected one of %s",
+				attr.name, value, tld, strings.Join(attr.values, ", ")))
+		case attr.pattern != nil && !attr.pattern.MatchString(value):
+			errs = append(errs, fmt.Errorf("extended_attributes: %s = %q is not valid for .%s domains; expected %s",
+				attr.name, value, tld, attr.description))
+		}
+	}
+
+	for _, name := range sortedKeys(attrs) {
+		if !known[name] && !isReservedSetContactsParam(name) {
+			names := make([]string, 0, len(table))
+			for _, attr := range table {
+				names = append(names, attr.name)
+			}
+			errs = append(errs, fmt.Errorf("extended_attributes: %s is not an extended attribute of .%s domains; expected one of %s",
+				name, tld, strings.Join(names, ", ")))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// valuesHint lists the accepted values for a missing-attribute message.
+func (a extendedAttribute) valuesHint() string {
+	if len(a.values) == 0 {
+		return ""
+	}
+	return ": one of " + strings.Join(a.values, ", ")
+}
+
+// validateExtendedAttributesConfig runs validateExtendedAttributes against the
+// raw configuration. It is skipped while the domain or any attribute is
+// unknown, since the table to check against, or the values, are not known
+// yet; apply re-runs the plan with them resolved.
+func validateExtendedAttributesConfig(rawConfig cty.Value) error {
+	domain := rawConfig.GetAttr("domain")
+	raw := rawConfig.GetAttr("extended_attributes")
+	if !domain.IsKnown() || domain.IsNull() || !raw.IsWhollyKnown() {
+		return nil
+	}
+
+	attrs := map[string]string{}
+	if !raw.IsNull() {
+		for name, value := range raw.AsValueMap() {
+			if !value.IsNull() {
+				attrs[name] = value.AsString()
+			}
+		}
+	}
+	return validateExtendedAttributes(domain.AsString(), attrs)
+}
+
+// expandExtendedAttributes converts the extended_attributes map from state into
+// the parameter map sent to setContacts.
+func expandExtendedAttributes(raw interface{}) map[string]string {
+	m, _ := raw.(map[string]interface{})
+	attrs := make(map[string]string, len(m))
+	for name, value := range m {
+		attrs[name] = value.(string)
+	}
+	return attrs
+}
+
+// applyContactParams flattens contact into params under prefix, the same way
+// the SDK does for setContacts: required fields always, optional ones only when
+// set.
+func applyContactParams(params map[string]string, prefix string, contact namecheap.ContactInfo) {
+	params[prefix+"FirstName"] = contact.FirstName
+	params[prefix+"LastName"] = contact.LastName
+	params[prefix+"Address1"] = contact.Address1
+	params[prefix+"City"] = contact.City
+	params[prefix+"StateProvince"] = contact.StateProvince
+	params[prefix+"PostalCode"] = contact.PostalCode
+	params[prefix+"Country"] = contact.Country
+	params[prefix+"Phone"] = contact.Phone
+	params[prefix+"EmailAddress"] = contact.EmailAddress
+	for key, value := range map[string]string{
+		"OrganizationName": contact.OrganizationName,
+		"JobTitle":         contact.JobTitle,
+		"Address2":         contact.Address2,
+	} {
+		if value != "" {
+			params[prefix+key] = value
+		}
+	}
+}
+
+// setContactsWithExtendedAttributes issues namecheap.domains.setContacts with
+// the extended attributes added to the request. The SDK's SetContactsWithContext
+// has no way to carry them, so the request is built here and sent through the
+// client, which still applies its retries, rate limiting and error parsing.
+func setContactsWithExtendedAttributes(ctx context.Context, client *namecheap.Client, args *namecheap.DomainsSetContactsArgs, extended map[string]string) (*namecheap.DomainsSetContactsCommandResponse, error) {
+	params := make(map[string]string, len(extended)+2+4*len(contactSchemaFields))
+	for name, value := range extended {
+		params[name] = value
+	}
+	params["Command"] = "namecheap.domains.setContacts"
+	params["DomainName"] = args.DomainName
+	applyContactParams(params, "Registrant", args.Registrant)
+	applyContactParams(params, "Tech", args.Tech)
+	applyContactParams(params, "Admin", args.Admin)
+	applyContactParams(params, "AuxBilling", args.AuxBilling)
+
+	var response namecheap.DomainsSetContactsResponse
+	if _, err := client.DoXMLWithContext(ctx, params, &response); err != nil {
+		return nil, err
+	}
+	return response.CommandResponse, nil
+}
+
+// sortedKeys returns the keys of m in lexical order, so diagnostics are stable.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,225 @@
+package namecheap_provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtendedAttributeTLD(t *testing.T) {
+	for domain, want := range map[string]string{
+		"example.ca":      "ca",
+		"EXAMPLE.US":      "us",
+		"example.co.uk":   "co.uk",
+		"example.uk":      "uk",
+		"example.org.uk.": "org.uk",
+		"example.com":     "",
+		"example.ca.com":  "",
+	} {
+		assert.Equal(t, want, extendedAttributeTLD(domain), domain)
+	}
+}
+
+func TestValidateExtendedAttributes(t *testing.T) {
+	ca := map[string]string{"CIRALegalType": "CCO", "CIRAAgreementVersion": "2.0", "CIRAAgreementValue": "Y"}
+
+	cases := []struct {
+		name   string
+		domain string
+		attrs  map[string]string
+		errs   []string
+	}{
+		{name: "complete .ca", domain: "example.ca", attrs: ca},
+		{name: "no table accepts anything", domain: "example.com", attrs: map[string]string{"Anything": "x"}},
+		{name: "no table no attributes", domain: "example.com", attrs: map[string]string{}},
+		{
+			name:   ".ca without a legal type",
+			domain: "example.ca",
+			attrs:  map[string]string{"CIRAAgreementVersion": "2.0", "CIRAAgreementValue": "Y"},
+			errs:   []string{"extended_attributes: .ca domains require CIRALegalType (the registrant's legal type: one of CCO, CCT"},
+		},
+		{
+			name:   ".ca with nothing set reports every missing attribute",
+			domain: "example.ca",
+			attrs:  map[string]string{},
+			errs:   []string{"require CIRALegalType", "require CIRAAgreementVersion", "require CIRAAgreementValue"},
+		},
+		{
+			name:   "value outside the table",
+			domain: "example.ca",
+			attrs:  map[string]string{"CIRALegalType": "XYZ", "CIRAAgreementVersion": "2.0", "CIRAAgreementValue": "Y"},
+			errs:   []string{`CIRALegalType = "XYZ" is not valid for .ca domains; expected one of CCO`},
+		},
+		{
+			name:   "unknown attribute for the TLD",
+			domain: "example.ca",
+			attrs:  map[string]string{"CIRALegalType": "CCO", "CIRAAgreementVersion": "2.0", "CIRAAgreementValue": "Y", "RegistrantNexus": "C11"},
+			errs:   []string{"RegistrantNexus is not an extended attribute of .ca domains; expected one of CIRALegalType"},
+		},
+		{
+			name:   ".us foreign nexus needs a country",
+			domain: "example.us",
+			attrs:  map[string]string{"RegistrantNexus": "C31", "RegistrantPurpose": "P1"},
+			errs:   []string{".us domains with RegistrantNexus C31 require RegistrantNexusCountry"},
+		},
+		{
+			name:   ".us nexus country format",
+			domain: "example.us",
+			attrs:  map[string]string{"RegistrantNexus": "C31", "RegistrantPurpose": "P1", "RegistrantNexusCountry": "Portugal"},
+			errs:   []string{`RegistrantNexusCountry = "Portugal" is not valid for .us domains`},
+		},
+		{name: ".us citizen", domain: "example.us", attrs: map[string]string{"RegistrantNexus": "C11", "RegistrantPurpose": "P3"}},
+		{
+			name:   ".co.uk company needs a company number",
+			domain: "example.co.uk",
+			attrs:  map[string]string{"COUKLegalType": "LTD"},
+			errs:   []string{".co.uk domains with COUKLegalType LTD require COUKCompanyID"},
+		},
+		{name: ".co.uk individual", domain: "example.co.uk", attrs: map[string]string{"COUKLegalType": "IND"}},
+		{
+			name:   "contact parameters cannot be overridden",
+			domain: "example.com",
+			attrs:  map[string]string{"RegistrantEmailAddress": "x@example.com", "Command": "namecheap.domains.create"},
+			errs:   []string{"Command is sent by the provider itself", "RegistrantEmailAddress is sent by the provider itself"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateExtendedAttributes(tc.domain, tc.attrs)
+			if len(tc.errs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			for _, want := range tc.errs {
+				assert.ErrorContains(t, err, want)
+			}
+		})
+	}
+}
+
+func TestValidateExtendedAttributesConfig(t *testing.T) {
+	config := func(domain, attrs cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{"domain": domain, "extended_attributes": attrs})
+	}
+
+	// A .ca domain without extended_attributes fails at plan time.
+	err := validateExtendedAttributesConfig(config(cty.StringVal("example.ca"), cty.NullVal(cty.Map(cty.String))))
+	assert.ErrorContains(t, err, ".ca domains require CIRALegalType")
+
+	// Unknown values are checked once they resolve.
+	assert.NoError(t, validateExtendedAttributesConfig(config(cty.UnknownVal(cty.String), cty.NullVal(cty.Map(cty.String)))))
+	assert.NoError(t, validateExtendedAttributesConfig(config(cty.StringVal("example.ca"), cty.MapVal(map[string]cty.Value{
+		"CIRALegalType": cty.UnknownVal(cty.String),
+	}))))
+
+	assert.NoError(t, validateExtendedAttributesConfig(config(cty.StringVal("example.com"), cty.NullVal(cty.Map(cty.String)))))
+}
+
+// contactsPlanRaw plans config against state like contactsPlan, but also hands
+// the plan the raw configuration, which the extended-attribute check reads.
+func contactsPlanRaw(t *testing.T, state, config map[string]interface{}) error {
+	t.Helper()
+	resource := resourceNamecheapDomainContacts()
+	prior := schema.TestResourceDataRaw(t, resource.Schema, state)
+	prior.SetId(state["domain"].(string))
+	instance := prior.State()
+
+	encoded, err := json.Marshal(config)
+	require.NoError(t, err)
+	instance.RawConfig, err = ctyjson.Unmarshal(encoded, resource.CoreConfigSchema().ImpliedType())
+	require.NoError(t, err)
+
+	_, err = schema.InternalMap(resource.Schema).Diff(context.Background(), instance,
+		terraform.NewResourceConfigRaw(config), resource.CustomizeDiff, nil, true)
+	return err
+}
+
+func TestCustomizeContactsDiff_ExtendedAttributes(t *testing.T) {
+	imported := registrantRaw()
+	imported["domain"] = "example.ca"
+
+	assert.NoError(t, contactsPlanRaw(t, imported, imported), "an imported .ca domain without attributes plans while unchanged")
+
+	changed := withRegistrant(imported, "city", "Porto")
+	assert.ErrorContains(t, contactsPlanRaw(t, imported, changed), ".ca domains require CIRALegalType")
+
+	changed["extended_attributes"] = map[string]interface{}{
+		"CIRALegalType": "CCO", "CIRAAgreementVersion": "2.0", "CIRAAgreementValue": "Y",
+	}
+	assert.NoError(t, contactsPlanRaw(t, imported, changed))
+}
+
+func TestResourceContactsRead_MissingExtendedAttributesWarns(t *testing.T) {
+	url := contactsTestServer(t, func(command string) string {
+		if command == "namecheap.domains.getContacts" {
+			return xmlGetContacts("example.ca")
+		}
+		return apiErrorXML("1010101", "unexpected "+command)
+	})
+
+	raw := registrantRaw()
+	raw["domain"] = "example.ca"
+	d := schema.TestResourceDataRaw(t, resourceNamecheapDomainContacts().Schema, raw)
+	d.SetId("example.ca")
+
+	diags := resourceContactsRead(context.Background(), d, testMeta(newTestClient(url)))
+	require.False(t, diags.HasError(), "unexpected diags: %+v", diags)
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Equal(t, "Extended attributes missing for example.ca", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "CIRALegalType")
+}
+
+func TestResourceContactsCreate_ExtendedAttributes(t *testing.T) {
+	var sent url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "text/xml")
+		switch r.FormValue("Command") {
+		case "namecheap.domains.setContacts":
+			sent = r.Form
+			_, _ = io.WriteString(w, xmlSetContactsOK("example.ca"))
+		case "namecheap.domains.getContacts":
+			_, _ = io.WriteString(w, xmlGetContacts("example.ca"))
+		default:
+			_, _ = io.WriteString(w, apiErrorXML("1010101", "unexpected "+r.FormValue("Command")))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	raw := registrantRaw()
+	raw["domain"] = "example.ca"
+	raw["extended_attributes"] = map[string]interface{}{
+		"CIRALegalType": "CCO", "CIRAAgreementVersion": "2.0", "CIRAAgreementValue": "Y",
+	}
+	d := schema.TestResourceDataRaw(t, resourceNamecheapDomainContacts().Schema, raw)
+	diags := resourceContactsCreate(context.Background(), d, testMeta(newTestClient(srv.URL)))
+	require.False(t, diags.HasError(), "unexpected diags: %+v", diags)
+
+	require.NotNil(t, sent)
+	assert.Equal(t, "example.ca", sent.Get("DomainName"))
+	assert.Equal(t, "CCO", sent.Get("CIRALegalType"))
+	assert.Equal(t, "2.0", sent.Get("CIRAAgreementVersion"))
+	assert.Equal(t, "Y", sent.Get("CIRAAgreementValue"))
+	for _, prefix := range []string{"Registrant", "Tech", "Admin", "AuxBilling"} {
+		assert.Equal(t, "Jane", sent.Get(prefix+"FirstName"), prefix)
+		assert.Equal(t, "PT", sent.Get(prefix+"Country"), prefix)
+	}
+	assert.NotContains(t, sent, "RegistrantJobTitle", "unset optional fields are not sent")
+
+	// getContacts does not return extended attributes; the configured ones stay.
+	assert.Equal(t, "CCO", d.Get("extended_attributes.CIRALegalType"))
+}
@@ -37,6 +37,9 @@ func isNameserverNotFoundError(err error) bool {
 // custom nameservers to a domain (namecheap_domain_records' nameservers
 // argument, which calls domains.dns.setCustom): this resource registers the
 // nameserver host and its glue IP so it can itself be used as a nameserver.
+//
+// The API holds one glue address per nameserver host, IPv4 or IPv6, so ip is a
+// single address rather than a list.
 func resourceNamecheapPersonalNameserver() *schema.Resource {
 	return &schema.Resource{
 		Description:   "Registers a personal (glue/vanity) nameserver such as ns1.example.com under a domain on your account, and manages the IP address it resolves to.",
@@ -45,6 +48,8 @@ func resourceNamecheapPersonalNameserver() *schema.Resource {
 		UpdateContext: resourceNameserverUpdate,
 		DeleteContext: resourceNameserverDelete,
 
+		Timeouts: resourceTimeouts(),
+
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceNameserverImport,
 		},
@@ -90,7 +95,7 @@ func nameserverSplitDomain(domain string) (sld string, tld string, err error) {
 }
 
 func resourceNameserverCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 
 	domain := strings.ToLower(data.Get("domain").(string))
 	nameserver := strings.ToLower(data.Get("nameserver").(string))
@@ -111,7 +116,7 @@ func resourceNameserverCreate(ctx context.Context, data *schema.ResourceData, me
 }
 
 func resourceNameserverRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 
 	domain := strings.ToLower(data.Get("domain").(string))
 	nameserver := strings.ToLower(data.Get("nameserver").(string))
@@ -158,7 +163,7 @@ func resourceNameserverRead(ctx context.Context, data *schema.ResourceData, meta
 }
 
 func resourceNameserverUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 
 	domain := strings.ToLower(data.Get("domain").(string))
 	nameserver := strings.ToLower(data.Get("nameserver").(string))
@@ -182,7 +187,7 @@ func resourceNameserverUpdate(ctx context.Context, data *schema.ResourceData, me
 }
 
 func resourceNameserverDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*namecheap.Client)
+	client := meta.(*providerMeta).client
 
 	domain := strings.ToLower(data.Get("domain").(string))
 	nameserver := strings.ToLower(data.Get("nameserver").(string))
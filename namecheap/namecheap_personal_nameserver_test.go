@@ -135,7 +135,7 @@ func TestResourceNameserverCreate(t *testing.T) {
 	client := newTestClient(m.server.URL)
 
 	d := nsTestData(t, "example.com", "ns1.example.com", "1.2.3.4")
-	diags := resourceNameserverCreate(context.Background(), d, client)
+	diags := resourceNameserverCreate(context.Background(), d, testMeta(client))
 
 	assert.False(t, diags.HasError(), "unexpected diags: %v", diags)
 	form := m.last()
@@ -154,7 +154,7 @@ func TestResourceNameserverCreateAPIError(t *testing.T) {
 	client := newTestClient(m.server.URL)
 
 	d := nsTestData(t, "example.com", "ns1.example.com", "1.2.3.4")
-	diags := resourceNameserverCreate(context.Background(), d, client)
+	diags := resourceNameserverCreate(context.Background(), d, testMeta(client))
 
 	assert.True(t, diags.HasError(), "expected an error diagnostic for an API error")
 }
@@ -168,7 +168,7 @@ func TestResourceNameserverRead(t *testing.T) {
 
 	d := nsTestData(t, "example.com", "ns1.example.com", "1.2.3.4")
 	d.SetId("example.com/ns1.example.com")
-	diags := resourceNameserverRead(context.Background(), d, client)
+	diags := resourceNameserverRead(context.Background(), d, testMeta(client))
 
 	assert.False(t, diags.HasError(), "unexpected diags: %v", diags)
 	form := m.last()
@@ -190,7 +190,7 @@ func TestResourceNameserverReadNotFound(t *testing.T) {
 
 	d := nsTestData(t, "example.com", "ns1.example.com", "1.2.3.4")
 	d.SetId("example.com/ns1.example.com")
-	diags := resourceNameserverRead(context.Background(), d, client)
+	diags := resourceNameserverRead(context.Background(), d, testMeta(client))
 
 	assert.False(t, diags.HasError(), "a not-found nameserver must not error; got %v", diags)
 	assert.Empty(t, d.Id(), "a missing nameserver should be removed from state")
@@ -206,7 +206,7 @@ func TestResourceNameserverReadEmptyResult(t *testing.T) {
 
 	d := nsTestData(t, "example.com", "ns1.example.com", "1.2.3.4")
 	d.SetId("example.com/ns1.example.com")
-	diags := resourceNameserverRead(context.Background(), d, client)
+	diags := resourceNameserverRead(context.Background(), d, testMeta(client))
 
 	assert.False(t, diags.HasError(), "unexpected diags: %v", diags)
 	assert.Empty(t, d.Id(), "an empty result should be removed from state")
@@ -222,7 +222,7 @@ func TestResourceNameserverReadAPIError(t *testing.T) {
 
 	d := nsTestData(t, "example.com", "ns1.example.com", "1.2.3.4")
 	d.SetId("example.com/ns1.example.com")
-	diags := resourceNameserverRead(context.Background(), d, client)
+	diags := resourceNameserverRead(context.Background(), d, testMeta(client))
 
 	assert.True(t, diags.HasError(), "a non-not-found API error must surface")
 	assert.Equal(t, "example.com/ns1.example.com", d.Id(), "state must be left intact on a hard error")
@@ -236,7 +236,7 @@ func TestResourceNameserverUpdate(t *testing.T) {
 
 	d := nsTestData(t, "example.com", "ns1.example.com", "5.6.7.8")
 	d.SetId("example.com/ns1.example.com")
-	diags := resourceNameserverUpdate(context.Background(), d, client)
+	diags := resourceNameserverUpdate(context.Background(), d, testMeta(client))
 
 	assert.False(t, diags.HasError(), "unexpected diags: %v", diags)
 	form := m.last()
@@ -255,7 +255,7 @@ func TestResourceNameserverDelete(t *testing.T) {
 
 	d := nsTestData(t, "example.com", "ns1.example.com", "1.2.3.4")
 	d.SetId("example.com/ns1.example.com")
-	diags := resourceNameserverDelete(context.Background(), d, client)
+	diags := resourceNameserverDelete(context.Background(), d, testMeta(client))
 
 	assert.False(t, diags.HasError(), "unexpected diags: %v", diags)
 	form := m.last()
@@ -276,7 +276,7 @@ func TestResourceNameserverDeleteNotFound(t *testing.T) {
 
 	d := nsTestData(t, "example.com", "ns1.example.com", "1.2.3.4")
 	d.SetId("example.com/ns1.example.com")
-	diags := resourceNameserverDelete(context.Background(), d, client)
+	diags := resourceNameserverDelete(context.Background(), d, testMeta(client))
 
 	assert.False(t, diags.HasError(), "deleting an already-absent nameserver must not error; got %v", diags)
 }
@@ -0,0 +1,624 @@
+package namecheap_provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/id"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+)
+
+// Per-domain statuses recorded in a namecheap_portfolio_contacts results entry.
+const (
+	portfolioStatusApplied = "applied"
+	portfolioStatusFailed  = "failed"
+	portfolioStatusPending = "pending"
+)
+
+// portfolioContactBlocks are the contact blocks of namecheap_portfolio_contacts.
+var portfolioContactBlocks = []string{"registrant", "tech", "admin", "aux_billing"}
+
+// portfolioReapplyAttributes are the attributes whose changes mean every
+// matched domain must be updated again.
+var portfolioReapplyAttributes = []string{"registrant", "tech", "admin", "aux_billing", "extended_attributes"}
+
+// portfolioSelectors choose the domains. One must be set, so that a selector
+// left out by mistake does not rewrite the contacts of the whole account.
+var portfolioSelectors = []string{"domains", "search_term", "list_type"}
+
+// resourceNamecheapPortfolioContacts applies one contact set to every domain a
+// selector matches: an explicit list, or the search_term/list_type filters of
+// the namecheap_domains data source.
+//
+// Semantics worth calling out:
+//   - Domains are updated one setContacts call at a time through the provider's
+//     client, so the provider's requests_per_minute paces the whole batch.
+//   - Every matched domain gets an entry in results recording whether its
+//     contacts were applied. A failed domain does not stop the batch, and a
+//     batch cut short by the operation's deadline leaves the rest pending.
+//   - Any entry that is not applied plans an update, which retries only those
+//     domains. A change to the contact blocks reapplies every domain.
+//   - Read re-resolves the selector, so a domain that newly matches the filters
+//     is added as pending and planned, and one that no longer matches is
+//     dropped. Read does not compare each domain's live contacts: that would
+//     cost a getContacts call per domain on every refresh. Use
+//     namecheap_domain_contacts for per-domain drift detection.
+//   - Delete is a state-only removal, as for namecheap_domain_contacts.
+//   - extended_attributes holds one block per TLD, and each domain is sent the
+//     block of its TLD. The blocks, and the domains listed in domains, are
+//     checked against extendedAttributeTables at plan time. A domain the
+//     filters select whose TLD requires attributes without a block fails at
+//     apply time without an API call.
+//   - The registrant applied to a gTLD domain is subject to the same ICANN
+//     change-of-registrant rule as in namecheap_domain_contacts, so the plan
+//     fails without acknowledge_transfer_lock (see
+//     checkPortfolioRegistrantChange).
+func resourceNamecheapPortfolioContacts() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Applies one contact set to every domain matched by an explicit list or portfolio filters, recording the outcome per domain and resuming failed domains on the next apply.",
+		CreateContext: resourcePortfolioContactsCreate,
+		ReadContext:   resourcePortfolioContactsRead,
+		UpdateContext: resourcePortfolioContactsUpdate,
+		DeleteContext: resourcePortfolioContactsDelete,
+
+		Timeouts: resourceTimeouts(),
+
+		ValidateRawResourceConfigFuncs: []schema.ValidateRawResourceConfigFunc{validateContactBlocksRawConfig(portfolioContactBlocks...)},
+
+		CustomizeDiff: customizePortfolioContactsDiff,
+
+		Schema: map[string]*schema.Schema{
+			"domains": {
+				Type:          schema.TypeSet,
+				Optional:      true,
+				MinItems:      1,
+				ConflictsWith: []string{"search_term", "list_type"},
+				AtLeastOneOf:  portfolioSelectors,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateDomainIsNotSubdomain,
+				},
+				Description: "The domains to update. Conflicts with search_term and list_type. One of the three must be set; updating every domain in the account takes list_type = \"ALL\".",
+			},
+			"search_term": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"domains"},
+				AtLeastOneOf:  portfolioSelectors,
+				Description:   "Select the domains matching this keyword (the getList SearchTerm parameter, as in the namecheap_domains data source).",
+			},
+			"list_type": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"domains"},
+				AtLeastOneOf:  portfolioSelectors,
+				ValidateFunc:  validation.StringInSlice([]string{domainsListTypeAll, domainsListTypeExpiring, domainsListTypeExpired}, false),
+				Description:   "Select this subset of the account's domains: ALL, EXPIRING or EXPIRED (the getList ListType parameter). Defaults to ALL when only search_term is set.",
+			},
+			"registrant": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MaxItems:    1,
+				Description: "Registrant contact applied to every selected domain. Required.",
+				Elem:        &schema.Resource{Schema: domainContactBlockSchema()},
+			},
+			"tech": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Tech contact. Optional; the registrant contact is applied when omitted.",
+				Elem:        &schema.Resource{Schema: domainContactBlockSchema()},
+			},
+			"admin": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Admin contact. Optional; the registrant contact is applied when omitted.",
+				Elem:        &schema.Resource{Schema: domainContactBlockSchema()},
+			},
+			"aux_billing": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "AuxBilling contact. Optional; the registrant contact is applied when omitted.",
+				Elem:        &schema.Resource{Schema: domainContactBlockSchema()},
+			},
+			"extended_attributes": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Registry-specific attributes sent with the contacts of the selected domains under one TLD. Repeat the block for each TLD that takes them.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"tld": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "The TLD the attributes are sent for, without the leading dot (e.g. `us` or `co.uk`). A domain uses the block with the longest TLD it ends in.",
+							ValidateFunc: validation.StringMatch(portfolioTLDRegexp, "must be a TLD without the leading dot, such as us or co.uk"),
+						},
+						"attributes": {
+							Type:     schema.TypeMap,
+							Required: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Description: "The attributes, keyed by their Namecheap name (e.g. CIRALegalType for .ca, RegistrantNexus for .us), " +
+								"as in the extended_attributes of namecheap_domain_contacts.",
+						},
+					},
+				},
+			},
+			"acknowledge_transfer_lock": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Allow a registrant that makes a material change of registrant on the selected gTLD domains. Such a change must be confirmed by e-mail and locks each domain against transfer to another registrar for 60 days; without this set, the plan fails instead. Defaults to false.",
+			},
+			"results": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The outcome for each selected domain, sorted by domain.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"domain": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The domain.",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "applied once the contacts were set; failed when the last attempt was rejected; pending when the domain has not been attempted yet.",
+						},
+						"error": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Why the last attempt failed. Empty unless status is failed.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// portfolioTLDRegexp matches a TLD without the leading dot, such as us or co.uk.
+var portfolioTLDRegexp = regexp.MustCompile(`^[a-z0-9-]+(\.[a-z0-9-]+)*$`)
+
+// portfolioExtendedAttributes decodes the extended_attributes blocks into a map
+// keyed by TLD.
+func portfolioExtendedAttributes(raw interface{}) (map[string]map[string]string, error) {
+	list, _ := raw.([]interface{})
+	byTLD := make(map[string]map[string]string, len(list))
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		tld := m["tld"].(string)
+		if _, ok := byTLD[tld]; ok {
+			return nil, fmt.Errorf("extended_attributes: tld %q is set by more than one block", tld)
+		}
+		byTLD[tld] = expandExtendedAttributes(m["attributes"])
+	}
+	return byTLD, nil
+}
+
+// portfolioExtendedAttributesBlock returns the TLD of the block with the
+// longest TLD domain ends in, and its attributes, or "" and nil when no block
+// matches.
+func portfolioExtendedAttributesBlock(byTLD map[string]map[string]string, domain string) (string, map[string]string) {
+	domain = strings.ToLower(domain)
+	match := ""
+	for tld := range byTLD {
+		if strings.HasSuffix(domain, "."+tld) && len(tld) > len(match) {
+			match = tld
+		}
+	}
+	if match == "" {
+		return "", nil
+	}
+	return match, byTLD[match]
+}
+
+// validatePortfolioExtendedAttributes checks each extended_attributes block
+// against the table of its TLD, and each domain in domains against the block it
+// will be sent, reporting a TLD that requires attributes once. It is skipped
+// while either is unknown.
+func validatePortfolioExtendedAttributes(diff *schema.ResourceDiff) error {
+	if !diff.NewValueKnown("extended_attributes") || !diff.NewValueKnown("domains") {
+		return nil
+	}
+	// NewValueKnown does not see an unknown value nested in a block.
+	if rawConfig := diff.GetRawConfig(); !rawConfig.IsNull() && !rawConfig.GetAttr("extended_attributes").IsWhollyKnown() {
+		return nil
+	}
+	byTLD, err := portfolioExtendedAttributes(diff.Get("extended_attributes"))
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	tlds := make([]string, 0, len(byTLD))
+	for tld := range byTLD {
+		tlds = append(tlds, tld)
+	}
+	sort.Strings(tlds)
+	for _, tld := range tlds {
+		if err := validateExtendedAttributes("example."+tld, byTLD[tld]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	var domains []string
+	if set, ok := diff.Get("domains").(*schema.Set); ok {
+		for _, domain := range set.List() {
+			domains = append(domains, strings.ToLower(domain.(string)))
+		}
+	}
+	sort.Strings(domains)
+	seen := map[string]bool{}
+	for _, domain := range domains {
+		blockTLD, attrs := portfolioExtendedAttributesBlock(byTLD, domain)
+		tableTLD := extendedAttributeTLD(domain)
+		// A block for the domain's own TLD was checked above.
+		if (blockTLD != "" && blockTLD == tableTLD) || seen[tableTLD+"/"+blockTLD] {
+			continue
+		}
+		seen[tableTLD+"/"+blockTLD] = true
+		if err := validateExtendedAttributes(domain, attrs); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", domain, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// portfolioResult is one entry of results.
+type portfolioResult struct {
+	domain string
+	status string
+	err    string
+}
+
+// portfolioResultsFromData decodes results into a map keyed by domain.
+func portfolioResultsFromData(raw interface{}) map[string]portfolioResult {
+	list, _ := raw.([]interface{})
+	results := make(map[string]portfolioResult, len(list))
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		r := portfolioResult{domain: m["domain"].(string), status: m["status"].(string), err: m["error"].(string)}
+		results[r.domain] = r
+	}
+	return results
+}
+
+// flattenPortfolioResults encodes results sorted by domain.
+func flattenPortfolioResults(results map[string]portfolioResult) []interface{} {
+	domains := make([]string, 0, len(results))
+	for domain := range results {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	list := make([]interface{}, 0, len(domains))
+	for _, domain := range domains {
+		r := results[domain]
+		list = append(list, map[string]interface{}{"domain": r.domain, "status": r.status, "error": r.err})
+	}
+	return list
+}
+
+// selectPortfolioDomains resolves the selector to the lowercased, sorted list of
+// domains it matches.
+func selectPortfolioDomains(ctx context.Context, client *namecheap.Client, data *schema.ResourceData) ([]string, error) {
+	var selected []string
+	if set, ok := data.Get("domains").(*schema.Set); ok && set.Len() > 0 {
+		for _, domain := range set.List() {
+			selected = append(selected, strings.ToLower(domain.(string)))
+		}
+	} else {
+		listType := data.Get("list_type").(string)
+		if listType == "" {
+			listType = domainsListTypeAll
+		}
+		domains, err := fetchAllDomains(ctx, client, listType, data.Get("search_term").(string))
+		if err != nil {
+			return nil, err
+		}
+		for i := range domains {
+			if domains[i].Name != nil {
+				selected = append(selected, strings.ToLower(*domains[i].Name))
+			}
+		}
+	}
+	sort.Strings(selected)
+	return selected, nil
+}
+
+// applyPortfolioContacts sets the contacts, and the extended attributes of the
+// domain's TLD, on each selected domain whose prior result is not applied, or
+// on every selected domain when reapplyAll is set. It
+// returns the new results and one warning or error per failed domain, at
+// severity. Once ctx ends the remaining domains are left pending.
+func applyPortfolioContacts(ctx context.Context, client *namecheap.Client, data *schema.ResourceData, selected []string, prior map[string]portfolioResult, reapplyAll bool, severity diag.Severity) (map[string]portfolioResult, diag.Diagnostics) {
+	addresses := newContactAddressBook(ctx, client)
+	results := make(map[string]portfolioResult, len(selected))
+	byTLD, err := portfolioExtendedAttributes(data.Get("extended_attributes"))
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	var diags diag.Diagnostics
+	for _, domain := range selected {
+		if r, ok := prior[domain]; ok && r.status == portfolioStatusApplied && !reapplyAll {
+			results[domain] = r
+			continue
+		}
+		if ctx.Err() != nil {
+			results[domain] = portfolioResult{domain: domain, status: portfolioStatusPending}
+			continue
+		}
+
+		args, argDiags := contactsArgsFromData(data, domain, addresses)
+		if argDiags != nil {
+			// An unresolvable address_id fails every domain alike.
+			return nil, argDiags
+		}
+		// A domain the filters selected is only now known to need attributes
+		// no block supplies; it fails here rather than at the registry.
+		_, extended := portfolioExtendedAttributesBlock(byTLD, domain)
+		err := validateExtendedAttributes(domain, extended)
+		if err == nil {
+			err = sendDomainContacts(ctx, client, args, extended)
+		}
+		if err != nil {
+			results[domain] = portfolioResult{domain: domain, status: portfolioStatusFailed, err: err.Error()}
+			diags = append(diags, diag.Diagnostic{
+				Severity: severity,
+				Summary:  "Unable to set the contacts of " + domain,
+				Detail:   err.Error(),
+			})
+			continue
+		}
+		results[domain] = portfolioResult{domain: domain, status: portfolioStatusApplied}
+	}
+
+	if pending := countPortfolioStatus(results, portfolioStatusPending); pending > 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: severity,
+			Summary:  fmt.Sprintf("%d of %d domains were not attempted before the deadline", pending, len(selected)),
+			Detail: "The operation's deadline passed part-way through the batch. The remaining domains are recorded as pending " +
+				"and the next apply resumes with them. Raise the timeouts block, or the provider's requests_per_minute, for a large portfolio.",
+		})
+	}
+	return results, diags
+}
+
+// countPortfolioStatus counts the results with status.
+func countPortfolioStatus(results map[string]portfolioResult, status string) int {
+	n := 0
+	for _, r := range results {
+		if r.status == status {
+			n++
+		}
+	}
+	return n
+}
+
+func resourcePortfolioContactsCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+
+	selected, err := selectPortfolioDomains(ctx, client, data)
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	// Terraform taints a resource whose create fails, and replacing it would
+	// restart the batch from scratch. Per-domain failures are therefore warnings
+	// here, and the resume is planned from results, unless no domain at all was
+	// updated, when there is nothing worth keeping.
+	results, diags := applyPortfolioContacts(ctx, client, data, selected, nil, true, diag.Warning)
+	if diags.HasError() {
+		return diags
+	}
+	if len(selected) > 0 && countPortfolioStatus(results, portfolioStatusApplied) == 0 {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to set the contacts of any selected domain",
+			Detail:   fmt.Sprintf("None of the %d selected domains was updated; see the warnings above for each domain's error.", len(selected)),
+		})
+	}
+
+	data.SetId(id.PrefixedUniqueId("portfolio-contacts-"))
+	if err := data.Set("results", flattenPortfolioResults(results)); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	return diags
+}
+
+func resourcePortfolioContactsRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+
+	selected, err := selectPortfolioDomains(ctx, client, data)
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	prior := portfolioResultsFromData(data.Get("results"))
+	results := make(map[string]portfolioResult, len(selected))
+	for _, domain := range selected {
+		if r, ok := prior[domain]; ok {
+			results[domain] = r
+			continue
+		}
+		results[domain] = portfolioResult{domain: domain, status: portfolioStatusPending}
+	}
+
+	if err := data.Set("results", flattenPortfolioResults(results)); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+func resourcePortfolioContactsUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*providerMeta).client
+
+	// The planned results are unknown; keep the prior ones in state until the
+	// batch has run, so an early failure does not lose them.
+	priorRaw, _ := data.GetChange("results")
+	if err := data.Set("results", priorRaw); err != nil {
+		return diag.FromErr(err)
+	}
+
+	selected, err := selectPortfolioDomains(ctx, client, data)
+	if err != nil {
+		return diagFromClientError(err)
+	}
+
+	reapplyAll := data.HasChanges(portfolioReapplyAttributes...)
+
+	results, diags := applyPortfolioContacts(ctx, client, data, selected, portfolioResultsFromData(priorRaw), reapplyAll, diag.Error)
+	if results == nil {
+		return diags
+	}
+	if err := data.Set("results", flattenPortfolioResults(results)); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	return diags
+}
+
+// resourcePortfolioContactsDelete removes the resource from state without
+// calling the API, as for namecheap_domain_contacts: the domains keep the
+// contacts last applied.
+func resourcePortfolioContactsDelete(_ context.Context, data *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	data.SetId("")
+
+	return diag.Diagnostics{
+		{
+			Severity: diag.Warning,
+			Summary:  "Domain contacts cannot be deleted",
+			Detail: "The Namecheap API has no operation to delete a domain's WHOIS contacts. Removing this resource stops " +
+				"Terraform from managing the contacts of the selected domains, but the last-applied contact values remain on them.",
+		},
+	}
+}
+
+// customizePortfolioContactsDiff validates the contact blocks and extended
+// attributes, rejects an
+// unacknowledged material change of registrant, and plans an update while any
+// domain in results is not applied, which is how a batch left incomplete by a
+// failure or a deadline resumes on the next apply.
+func customizePortfolioContactsDiff(_ context.Context, diff *schema.ResourceDiff, _ interface{}) error {
+	if rawConfig := diff.GetRawConfig(); !rawConfig.IsNull() {
+		if err := validateContactBlocks(rawConfig); err != nil {
+			return err
+		}
+	}
+	if err := validatePortfolioExtendedAttributes(diff); err != nil {
+		return err
+	}
+	if err := checkPortfolioRegistrantChange(diff); err != nil {
+		return err
+	}
+	if diff.Id() == "" {
+		return nil
+	}
+
+	incomplete := false
+	for _, r := range portfolioResultsFromData(diff.Get("results")) {
+		if r.status != portfolioStatusApplied {
+			incomplete = true
+			break
+		}
+	}
+	if incomplete || diff.HasChanges(append([]string{"domains", "search_term", "list_type"}, portfolioReapplyAttributes...)...) {
+		return diff.SetNewComputed("results")
+	}
+	return nil
+}
+
+// checkPortfolioRegistrantChange is checkRegistrantChange for a batch, judged
+// per gTLD domain the apply writes the contacts to. A domain the contacts were
+// already applied to is written again when they change, and its registrant
+// changes materially when the registrant block does. Any other domain - every
+// domain of a create, one the selection newly matches, or one still pending or
+// failed that is retried - gets the registrant without its current one having
+// been read, which would take a getContacts call per domain, so the change
+// counts as material for it.
+func checkPortfolioRegistrantChange(diff *schema.ResourceDiff) error {
+	if diff.Get("acknowledge_transfer_lock").(bool) || !diff.NewValueKnown("registrant") {
+		return nil
+	}
+	unread, applied, anyDomain := portfolioRegistrantWrites(diff)
+
+	if anyDomain || len(unread) > 0 {
+		domains := "the selected gTLD domains"
+		if len(unread) > 0 {
+			domains = "gTLD domains not yet updated (such as " + unread[0] + ")"
+		}
+		return fmt.Errorf("registrant: setting the registrant of %s is a material change of registrant wherever the name, organization "+
+			"or e-mail differs. ICANN requires the change to be confirmed by e-mail, and the domain cannot be transferred to another "+
+			"registrar for 60 days afterwards. The domains' current registrants are not read while planning, so set "+
+			"acknowledge_transfer_lock = true to apply it", domains)
+	}
+	if len(applied) == 0 || !diff.HasChange("registrant") {
+		return nil
+	}
+	changed := materialRegistrantChanges(diff.GetChange("registrant"))
+	if len(changed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("registrant: changing %s is a material change of registrant for the selected gTLD domains (such as %s). "+
+		"ICANN requires the change to be confirmed by e-mail, and each domain cannot be transferred to another registrar for "+
+		"60 days afterwards. Set acknowledge_transfer_lock = true to apply it", strings.Join(changed, ", "), applied[0])
+}
+
+// portfolioRegistrantWrites sorts the gTLD domains the plan may write the
+// contacts to into those with no applied result and those with one. anyDomain
+// is set when the selection is not known at plan time - domains not yet
+// known, filters on a create, or filters that change - and may match any
+// domain of the account. Otherwise the selection is domains, or the domains
+// the filters matched at the last refresh, which records new matches as
+// pending.
+func portfolioRegistrantWrites(diff *schema.ResourceDiff) (unread, applied []string, anyDomain bool) {
+	if !diff.NewValueKnown("domains") {
+		return nil, nil, true
+	}
+	prior := map[string]portfolioResult{}
+	if diff.Id() != "" {
+		prior = portfolioResultsFromData(diff.Get("results"))
+	}
+
+	var candidates []string
+	if set, ok := diff.Get("domains").(*schema.Set); ok && set.Len() > 0 {
+		for _, domain := range set.List() {
+			candidates = append(candidates, strings.ToLower(domain.(string)))
+		}
+	} else if diff.Id() == "" || diff.HasChanges("search_term", "list_type") {
+		return nil, nil, true
+	} else {
+		for domain := range prior {
+			candidates = append(candidates, domain)
+		}
+	}
+	sort.Strings(candidates)
+
+	for _, domain := range candidates {
+		if !isGenericTLD(domain) {
+			continue
+		}
+		if r, ok := prior[domain]; ok && r.status == portfolioStatusApplied {
+			applied = append(applied, domain)
+		} else {
+			unread = append(unread, domain)
+		}
+	}
+	return unread, applied, false
+}
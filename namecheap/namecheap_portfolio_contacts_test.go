@@ -0,0 +1,404 @@
+package namecheap_provider
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// portfolioTestServer lists portfolio as the account's domains and accepts
+// setContacts for every domain except those in failing, recording the domains
+// each setContacts call was for.
+func portfolioTestServer(t *testing.T, portfolio []string, failing ...string) (string, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var set []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "text/xml")
+		switch r.FormValue("Command") {
+		case "namecheap.domains.getList":
+			rows := make([]dsDomainRow, 0, len(portfolio))
+			for _, name := range portfolio {
+				rows = append(rows, dsDomainRow{ID: "1", Name: name, Created: "01/01/2020", Expires: "01/01/2030"})
+			}
+			_, _ = io.WriteString(w, xmlGetListPage(rows, len(rows), 1, domainsPageSize))
+		case "namecheap.domains.setContacts":
+			domain := r.FormValue("DomainName")
+			mu.Lock()
+			set = append(set, domain)
+			mu.Unlock()
+			for _, f := range failing {
+				if f == domain {
+					_, _ = io.WriteString(w, apiErrorXML("2019166", "Domain not found"))
+					return
+				}
+			}
+			_, _ = io.WriteString(w, xmlSetContactsOK(domain))
+		default:
+			_, _ = io.WriteString(w, apiErrorXML("1010101", "unexpected "+r.FormValue("Command")))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), set...)
+	}
+}
+
+// portfolioRaw is a namecheap_portfolio_contacts configuration selecting domains.
+func portfolioRaw(domains ...string) map[string]interface{} {
+	raw := registrantRaw()
+	delete(raw, "domain")
+	list := make([]interface{}, 0, len(domains))
+	for _, d := range domains {
+		list = append(list, d)
+	}
+	if len(list) > 0 {
+		raw["domains"] = list
+	}
+	return raw
+}
+
+func portfolioStatuses(d *schema.ResourceData) map[string]string {
+	statuses := map[string]string{}
+	for domain, r := range portfolioResultsFromData(d.Get("results")) {
+		statuses[domain] = r.status
+	}
+	return statuses
+}
+
+func TestResourcePortfolioContactsSchema_RequiresSelector(t *testing.T) {
+	r := resourceNamecheapPortfolioContacts()
+
+	assert.True(t, r.Validate(terraform.NewResourceConfigRaw(portfolioRaw())).HasError(), "a selector is required")
+
+	all := portfolioRaw()
+	all["list_type"] = domainsListTypeAll
+	assert.False(t, r.Validate(terraform.NewResourceConfigRaw(all)).HasError(), "every domain is selected explicitly")
+	assert.False(t, r.Validate(terraform.NewResourceConfigRaw(portfolioRaw("a.example"))).HasError())
+}
+
+func TestResourcePortfolioContactsCreate_PartialFailure(t *testing.T) {
+	url, sent := portfolioTestServer(t, nil, "b.example")
+
+	d := schema.TestResourceDataRaw(t, resourceNamecheapPortfolioContacts().Schema, portfolioRaw("a.example", "B.example", "c.example"))
+	diags := resourcePortfolioContactsCreate(context.Background(), d, testMeta(newTestClient(url)))
+
+	require.False(t, diags.HasError(), "a partial failure must not fail create: %+v", diags)
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Equal(t, "Unable to set the contacts of b.example", diags[0].Summary)
+
+	assert.NotEmpty(t, d.Id())
+	assert.ElementsMatch(t, []string{"a.example", "b.example", "c.example"}, sent())
+	assert.Equal(t, map[string]string{
+		"a.example": portfolioStatusApplied,
+		"b.example": portfolioStatusFailed,
+		"c.example": portfolioStatusApplied,
+	}, portfolioStatuses(d))
+	assert.Equal(t, "b.example", d.Get("results.1.domain"))
+	assert.Contains(t, d.Get("results.1.error"), "Domain not found")
+}
+
+func TestResourcePortfolioContactsCreate_AllFailed(t *testing.T) {
+	url, _ := portfolioTestServer(t, nil, "a.example", "b.example")
+
+	d := schema.TestResourceDataRaw(t, resourceNamecheapPortfolioContacts().Schema, portfolioRaw("a.example", "b.example"))
+	diags := resourcePortfolioContactsCreate(context.Background(), d, testMeta(newTestClient(url)))
+
+	assert.True(t, diags.HasError())
+	assert.Empty(t, d.Id())
+}
+
+func TestResourcePortfolioContactsCreate_SearchTerm(t *testing.T) {
+	url, sent := portfolioTestServer(t, []string{"one.example", "two.example"})
+
+	raw := portfolioRaw()
+	raw["search_term"] = "example"
+	d := schema.TestResourceDataRaw(t, resourceNamecheapPortfolioContacts().Schema, raw)
+	diags := resourcePortfolioContactsCreate(context.Background(), d, testMeta(newTestClient(url)))
+
+	require.False(t, diags.HasError(), "unexpected diags: %+v", diags)
+	assert.ElementsMatch(t, []string{"one.example", "two.example"}, sent())
+}
+
+func TestResourcePortfolioContactsCreate_Deadline(t *testing.T) {
+	url, sent := portfolioTestServer(t, nil)
+
+	d := schema.TestResourceDataRaw(t, resourceNamecheapPortfolioContacts().Schema, portfolioRaw("a.example", "b.example"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	diags := resourcePortfolioContactsCreate(ctx, d, testMeta(newTestClient(url)))
+
+	assert.True(t, diags.HasError(), "nothing was applied")
+	assert.Empty(t, sent())
+}
+
+// portfolioUpdateData builds the ResourceData an update sees: prior state with
+// results, and a plan from config.
+func portfolioUpdateData(t *testing.T, state, config map[string]interface{}, results []interface{}) *schema.ResourceData {
+	t.Helper()
+	resource := resourceNamecheapPortfolioContacts()
+	prior := schema.TestResourceDataRaw(t, resource.Schema, state)
+	prior.SetId("portfolio-contacts-1")
+	require.NoError(t, prior.Set("results", results))
+
+	diff, err := schema.InternalMap(resource.Schema).Diff(context.Background(), prior.State(),
+		terraform.NewResourceConfigRaw(config), resource.CustomizeDiff, nil, true)
+	require.NoError(t, err)
+	require.NotNil(t, diff, "an update must be planned")
+	data, err := schema.InternalMap(resource.Schema).Data(prior.State(), diff)
+	require.NoError(t, err)
+	return data
+}
+
+func TestResourcePortfolioContactsUpdate_ResumesFailedDomains(t *testing.T) {
+	url, sent := portfolioTestServer(t, nil)
+
+	raw := portfolioRaw("a.example", "b.example", "c.example")
+	raw["acknowledge_transfer_lock"] = true
+	d := portfolioUpdateData(t, raw, raw, []interface{}{
+		map[string]interface{}{"domain": "a.example", "status": portfolioStatusApplied, "error": ""},
+		map[string]interface{}{"domain": "b.example", "status": portfolioStatusFailed, "error": "Domain not found"},
+		map[string]interface{}{"domain": "c.example", "status": portfolioStatusPending, "error": ""},
+	})
+
+	diags := resourcePortfolioContactsUpdate(context.Background(), d, testMeta(newTestClient(url)))
+	require.False(t, diags.HasError(), "unexpected diags: %+v", diags)
+
+	assert.Equal(t, []string{"b.example", "c.example"}, sent(), "applied domains are not sent again")
+	assert.Equal(t, map[string]string{
+		"a.example": portfolioStatusApplied,
+		"b.example": portfolioStatusApplied,
+		"c.example": portfolioStatusApplied,
+	}, portfolioStatuses(d))
+	assert.Equal(t, "", d.Get("results.1.error"))
+}
+
+func TestResourcePortfolioContactsUpdate_ContactChangeReappliesAll(t *testing.T) {
+	url, sent := portfolioTestServer(t, nil, "b.example")
+
+	config := portfolioRaw("a.example", "b.example")
+	config["registrant"].([]interface{})[0].(map[string]interface{})["city"] = "Porto"
+	d := portfolioUpdateData(t, portfolioRaw("a.example", "b.example"), config, []interface{}{
+		map[string]interface{}{"domain": "a.example", "status": portfolioStatusApplied, "error": ""},
+		map[string]interface{}{"domain": "b.example", "status": portfolioStatusApplied, "error": ""},
+	})
+
+	diags := resourcePortfolioContactsUpdate(context.Background(), d, testMeta(newTestClient(url)))
+	require.True(t, diags.HasError())
+	assert.Equal(t, "Unable to set the contacts of b.example", diags[0].Summary)
+
+	assert.Equal(t, []string{"a.example", "b.example"}, sent())
+	assert.Equal(t, map[string]string{
+		"a.example": portfolioStatusApplied,
+		"b.example": portfolioStatusFailed,
+	}, portfolioStatuses(d))
+}
+
+func TestCustomizePortfolioContactsDiff(t *testing.T) {
+	resource := resourceNamecheapPortfolioContacts()
+	raw := portfolioRaw("a.example")
+	raw["acknowledge_transfer_lock"] = true
+
+	diffFor := func(status string) *terraform.InstanceDiff {
+		prior := schema.TestResourceDataRaw(t, resource.Schema, raw)
+		prior.SetId("portfolio-contacts-1")
+		require.NoError(t, prior.Set("results", []interface{}{
+			map[string]interface{}{"domain": "a.example", "status": status, "error": ""},
+		}))
+		diff, err := schema.InternalMap(resource.Schema).Diff(context.Background(), prior.State(),
+			terraform.NewResourceConfigRaw(raw), resource.CustomizeDiff, nil, true)
+		require.NoError(t, err)
+		return diff
+	}
+
+	assert.Nil(t, diffFor(portfolioStatusApplied), "a complete batch plans nothing")
+	for _, status := range []string{portfolioStatusFailed, portfolioStatusPending} {
+		diff := diffFor(status)
+		if assert.NotNil(t, diff, status) {
+			assert.True(t, diff.Attributes["results.#"].NewComputed, status)
+		}
+	}
+}
+
+func TestResourcePortfolioContactsRead_TracksSelection(t *testing.T) {
+	url, sent := portfolioTestServer(t, []string{"kept.example", "new.example"})
+
+	raw := portfolioRaw()
+	raw["search_term"] = "example"
+	d := schema.TestResourceDataRaw(t, resourceNamecheapPortfolioContacts().Schema, raw)
+	d.SetId("portfolio-contacts-1")
+	require.NoError(t, d.Set("results", []interface{}{
+		map[string]interface{}{"domain": "kept.example", "status": portfolioStatusApplied, "error": ""},
+		map[string]interface{}{"domain": "gone.example", "status": portfolioStatusApplied, "error": ""},
+	}))
+
+	diags := resourcePortfolioContactsRead(context.Background(), d, testMeta(newTestClient(url)))
+	require.False(t, diags.HasError(), "unexpected diags: %+v", diags)
+
+	assert.Equal(t, map[string]string{
+		"kept.example": portfolioStatusApplied,
+		"new.example":  portfolioStatusPending,
+	}, portfolioStatuses(d))
+	assert.Empty(t, sent(), "read never sets contacts")
+}
+
+func TestPortfolioContactsResourceSchemaValid(t *testing.T) {
+	assert.NoError(t, resourceNamecheapPortfolioContacts().InternalValidate(nil, true))
+}
+
+func TestCheckPortfolioRegistrantChange(t *testing.T) {
+	resource := resourceNamecheapPortfolioContacts()
+	planWith := func(state, config map[string]interface{}, results ...interface{}) error {
+		var prior *terraform.InstanceState
+		if state != nil {
+			data := schema.TestResourceDataRaw(t, resource.Schema, state)
+			data.SetId("portfolio-contacts-1")
+			require.NoError(t, data.Set("results", results))
+			prior = data.State()
+		}
+		_, err := schema.InternalMap(resource.Schema).Diff(context.Background(), prior,
+			terraform.NewResourceConfigRaw(config), resource.CustomizeDiff, nil, true)
+		return err
+	}
+	result := func(domain, status string) interface{} {
+		return map[string]interface{}{"domain": domain, "status": status, "error": ""}
+	}
+	plan := func(state, config map[string]interface{}) error {
+		return planWith(state, config, result("a.com", portfolioStatusApplied))
+	}
+	acknowledged := func(raw map[string]interface{}) map[string]interface{} {
+		raw["acknowledge_transfer_lock"] = true
+		return raw
+	}
+
+	err := plan(nil, portfolioRaw("a.pt", "a.com"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "gTLD domains not yet updated (such as a.com)")
+	assert.Contains(t, err.Error(), "acknowledge_transfer_lock = true")
+	assert.NoError(t, plan(nil, acknowledged(portfolioRaw("a.com"))))
+	assert.NoError(t, plan(nil, portfolioRaw("a.pt", "b.de")), "ccTLDs are not subject to the ICANN policy")
+
+	filtered := portfolioRaw()
+	filtered["search_term"] = "a"
+	assert.Error(t, plan(nil, filtered), "unresolved filters may select any domain")
+
+	renamed := withRegistrant(portfolioRaw("a.com"), "last_name", "Roe")
+	delete(renamed, "domain")
+	err = plan(acknowledged(portfolioRaw("a.com")), renamed)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "changing last_name is a material change of registrant")
+	assert.NoError(t, plan(acknowledged(portfolioRaw("a.com")), acknowledged(renamed)))
+
+	moved := withRegistrant(portfolioRaw("a.com"), "city", "Porto")
+	delete(moved, "domain")
+	assert.NoError(t, plan(acknowledged(portfolioRaw("a.com")), moved), "not a material change")
+
+	// A domain written without its registrant having been compared needs the
+	// acknowledgement even when the registrant block does not change.
+	assert.NoError(t, plan(portfolioRaw("a.com"), portfolioRaw("a.com")), "nothing is written")
+	err = plan(portfolioRaw("a.com"), portfolioRaw("a.com", "b.com"))
+	require.Error(t, err, "a newly selected domain")
+	assert.Contains(t, err.Error(), "(such as b.com)")
+	assert.NoError(t, plan(portfolioRaw("a.com"), portfolioRaw("a.com", "b.pt")), "a newly selected ccTLD domain")
+
+	err = planWith(portfolioRaw("a.com", "b.com"), portfolioRaw("a.com", "b.com"),
+		result("a.com", portfolioStatusApplied), result("b.com", portfolioStatusFailed))
+	require.Error(t, err, "a failed domain is retried")
+	assert.Contains(t, err.Error(), "(such as b.com)")
+
+	filteredState := portfolioRaw()
+	filteredState["search_term"] = "a"
+	err = planWith(filteredState, filteredState, result("a.com", portfolioStatusApplied), result("ab.com", portfolioStatusPending))
+	require.Error(t, err, "a domain the filter newly matched is pending")
+	assert.Contains(t, err.Error(), "(such as ab.com)")
+	assert.NoError(t, planWith(filteredState, filteredState, result("a.com", portfolioStatusApplied)))
+
+	refiltered := portfolioRaw()
+	refiltered["search_term"] = "b"
+	assert.Error(t, planWith(filteredState, refiltered, result("a.com", portfolioStatusApplied)), "a changed filter may match any domain")
+}
+
+// usExtendedAttributes is an extended_attributes block for .us domains.
+func usExtendedAttributes() []interface{} {
+	return []interface{}{map[string]interface{}{
+		"tld":        "us",
+		"attributes": map[string]interface{}{"RegistrantNexus": "C11", "RegistrantPurpose": "P1"},
+	}}
+}
+
+func TestResourcePortfolioContactsCreate_ExtendedAttributes(t *testing.T) {
+	nexus := map[string]string{}
+	client := startDataSourceServer(t, func(command string, r *http.Request) string {
+		switch command {
+		case "namecheap.domains.getList":
+			rows := []dsDomainRow{
+				{ID: "1", Name: "a.us", Created: "01/01/2020", Expires: "01/01/2030"},
+				{ID: "2", Name: "b.ca", Created: "01/01/2020", Expires: "01/01/2030"},
+				{ID: "3", Name: "c.example", Created: "01/01/2020", Expires: "01/01/2030"},
+			}
+			return xmlGetListPage(rows, len(rows), 1, domainsPageSize)
+		case "namecheap.domains.setContacts":
+			nexus[r.FormValue("DomainName")] = r.FormValue("RegistrantNexus")
+			return xmlSetContactsOK(r.FormValue("DomainName"))
+		}
+		return apiErrorXML("1010101", "unexpected "+command)
+	})
+
+	raw := portfolioRaw()
+	raw["search_term"] = "example"
+	raw["extended_attributes"] = usExtendedAttributes()
+	d := schema.TestResourceDataRaw(t, resourceNamecheapPortfolioContacts().Schema, raw)
+	diags := resourcePortfolioContactsCreate(context.Background(), d, testMeta(client))
+
+	require.False(t, diags.HasError(), "unexpected diags: %+v", diags)
+	assert.Equal(t, map[string]string{"a.us": "C11", "c.example": ""}, nexus, "b.ca is not sent without its required attributes")
+	assert.Equal(t, map[string]string{
+		"a.us":      portfolioStatusApplied,
+		"b.ca":      portfolioStatusFailed,
+		"c.example": portfolioStatusApplied,
+	}, portfolioStatuses(d))
+	assert.Contains(t, d.Get("results.1.error"), ".ca domains require CIRALegalType")
+}
+
+func TestValidatePortfolioExtendedAttributes(t *testing.T) {
+	resource := resourceNamecheapPortfolioContacts()
+	plan := func(config map[string]interface{}) error {
+		_, err := schema.InternalMap(resource.Schema).Diff(context.Background(), nil,
+			terraform.NewResourceConfigRaw(config), resource.CustomizeDiff, nil, true)
+		return err
+	}
+	withAttributes := func(raw map[string]interface{}, blocks []interface{}) map[string]interface{} {
+		raw["extended_attributes"] = blocks
+		return raw
+	}
+
+	assert.NoError(t, plan(withAttributes(portfolioRaw("a.us", "b.us", "c.pt"), usExtendedAttributes())))
+
+	err := plan(portfolioRaw("a.us", "b.us", "c.pt"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "a.us: extended_attributes: .us domains require RegistrantNexus")
+	assert.NotContains(t, err.Error(), "b.us", "a TLD is reported once")
+
+	invalid := usExtendedAttributes()
+	invalid[0].(map[string]interface{})["attributes"].(map[string]interface{})["RegistrantNexus"] = "X99"
+	err = plan(withAttributes(portfolioRaw("c.pt"), invalid))
+	require.Error(t, err, "a block is checked even when no listed domain uses it")
+	assert.Contains(t, err.Error(), `RegistrantNexus = "X99" is not valid for .us domains`)
+
+	err = plan(withAttributes(portfolioRaw("a.us"), append(usExtendedAttributes(), usExtendedAttributes()...)))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `tld "us" is set by more than one block`)
+}
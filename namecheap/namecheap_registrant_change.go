@@ -0,0 +1,173 @@
+package namecheap_provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// registrantChangeFields are the registrant fields whose change ICANN's
+// Transfer Policy treats as a material change of registrant: the name, the
+// organization and the e-mail address. On a gTLD such a change must be
+// confirmed by e-mail and puts the domain under a 60-day transfer lock.
+var registrantChangeFields = []string{"first_name", "last_name", "organization", "email_address"}
+
+// idnCountryCodeTLDs are the internationalized ccTLDs delegated in the root
+// zone, in their punycode form. Like the two-letter ccTLDs they set their own
+// rules, but their length says nothing about that.
+var idnCountryCodeTLDs = map[string]bool{
+	"xn--2scrj9c": true, "xn--3e0b707e": true, "xn--3hcrj9c": true, "xn--45br5cyl": true, "xn--45brj9c": true,
+	"xn--54b7fta0cc": true, "xn--80ao21a": true, "xn--90a3ac": true, "xn--90ae": true, "xn--90ais": true,
+	"xn--clchc0ea0b2g2a9gcd": true, "xn--d1alf": true, "xn--e1a4c": true, "xn--fiqs8s": true, "xn--fiqz9s": true,
+	"xn--fpcrj9c3d": true, "xn--fzc2c9e2c": true, "xn--gecrj9c": true, "xn--h2breg3eve": true, "xn--h2brj9c": true,
+	"xn--h2brj9c8c": true, "xn--j1amh": true, "xn--j6w193g": true, "xn--kprw13d": true, "xn--kpry57d": true,
+	"xn--l1acc": true, "xn--lgbbat1ad8j": true, "xn--mgb9awbf": true, "xn--mgba3a4f16a": true, "xn--mgbaam7a8h": true,
+	"xn--mgbah1a3hjkrd": true, "xn--mgbai9azgqp6j": true, "xn--mgbayh7gpa": true, "xn--mgbbh1a": true,
+	"xn--mgbbh1a71e": true, "xn--mgbc0a9azcg": true, "xn--mgbcpq6gpa1a": true, "xn--mgberp4a5d4ar": true,
+	"xn--mgbgu82a": true, "xn--mgbpl2fh": true, "xn--mgbtx2b": true, "xn--mgbx4cd0ab": true, "xn--mix891f": true,
+	"xn--node": true, "xn--o3cw4h": true, "xn--ogbpf8fl": true, "xn--p1ai": true, "xn--pgbs0dh": true,
+	"xn--q7ce6a": true, "xn--qxa6a": true, "xn--qxam": true, "xn--rvc1e0am3e": true, "xn--s9brj9c": true,
+	"xn--wgbh1c": true, "xn--wgbl6a": true, "xn--xkc2al3hye2a": true, "xn--xkc2dl3a5ee0h": true, "xn--y9a3aq": true,
+	"xn--yfro4i67o": true, "xn--ygbi2ammx": true,
+}
+
+// isGenericTLD reports whether domain is under a gTLD, the only domains ICANN's
+// change-of-registrant process applies to. A ccTLD, which sets its own rules,
+// is two ASCII letters or one of idnCountryCodeTLDs; a second-level domain
+// such as co.uk is judged by the ccTLD it sits under.
+func isGenericTLD(domain string) bool {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	tld := domain[strings.LastIndex(domain, ".")+1:]
+	if idnCountryCodeTLDs[tld] {
+		return false
+	}
+	return !(len(tld) == 2 && tld[0] >= 'a' && tld[0] <= 'z' && tld[1] >= 'a' && tld[1] <= 'z')
+}
+
+// materialRegistrantChanges lists the registrant attributes that differ
+// between two values of the registrant block and make the change material. A
+// changed address_id is listed as well: what the entry holds is not known at
+// plan time, so it may change any of the fields. For the same reason the fields
+// are only compared when the new block sets them inline.
+func materialRegistrantChanges(before, after interface{}) []string {
+	oldBlock, newBlock := contactBlockMap(before), contactBlockMap(after)
+	if oldBlock == nil || newBlock == nil {
+		return nil
+	}
+
+	var changed []string
+	if oldBlock[contactAddressIDAttr] != newBlock[contactAddressIDAttr] {
+		changed = append(changed, contactAddressIDAttr)
+	}
+	if id, _ := newBlock[contactAddressIDAttr].(int); id > 0 {
+		return changed
+	}
+	for _, attr := range registrantChangeFields {
+		if !strings.EqualFold(fmt.Sprint(oldBlock[attr]), fmt.Sprint(newBlock[attr])) {
+			changed = append(changed, attr)
+		}
+	}
+	return changed
+}
+
+// contactBlockMap returns the attributes of a contact block value, or nil when
+// the block is empty.
+func contactBlockMap(raw interface{}) map[string]interface{} {
+	list, ok := raw.([]interface{})
+	if !ok || len(list) == 0 || list[0] == nil {
+		return nil
+	}
+	m, _ := list[0].(map[string]interface{})
+	return m
+}
+
+// checkRegistrantChange fails the plan of a material change to the registrant
+// of a gTLD domain unless acknowledge_transfer_lock is set. An update compares
+// the planned registrant with the prior state. A create compares it with the
+// registrant the domain holds now, read through meta, since the first apply
+// replaces that registrant just the same. When that read fails, offline or
+// rate limited say, a material change cannot be ruled out and the plan needs
+// the acknowledgement as well.
+func checkRegistrantChange(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.NewValueKnown("domain") || !diff.NewValueKnown("registrant") {
+		return nil
+	}
+	domain := diff.Get("domain").(string)
+	if !isGenericTLD(domain) || diff.Get("acknowledge_transfer_lock").(bool) {
+		return nil
+	}
+
+	var changed []string
+	if diff.Id() == "" {
+		var err error
+		if changed, err = currentRegistrantChanges(ctx, meta, domain, diff.Get("registrant")); err != nil {
+			return fmt.Errorf("registrant: the current registrant of %s could not be read to check for a material change of registrant: %s. "+
+				"A material change must be confirmed by e-mail, and the domain cannot be transferred to another registrar for 60 days "+
+				"afterwards. Set acknowledge_transfer_lock = true to apply the registrant anyway", domain, err)
+		}
+	} else if diff.HasChange("registrant") {
+		changed = materialRegistrantChanges(diff.GetChange("registrant"))
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("registrant: changing %s of %s is a material change of registrant. ICANN requires the change to be confirmed "+
+		"by e-mail, and the domain cannot be transferred to another registrar for 60 days afterwards. "+
+		"Set acknowledge_transfer_lock = true to apply it", strings.Join(changed, ", "), domain)
+}
+
+// currentRegistrantChanges lists the material changes the planned registrant
+// makes to the one domain holds, read with getContacts. A domain that is not
+// on the account yet, such as one registered in the same apply, has no
+// registrant to compare with. An error means the comparison could not be made.
+func currentRegistrantChanges(ctx context.Context, meta interface{}, domain string, planned interface{}) ([]string, error) {
+	m, ok := meta.(*providerMeta)
+	if !ok {
+		return nil, nil
+	}
+	resp, err := m.client.Domains.GetContactsWithContext(ctx, strings.ToLower(domain))
+	if err != nil {
+		if isDomainGoneError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if resp == nil || resp.DomainContactsResult == nil || resp.DomainContactsResult.Registrant == nil {
+		return nil, nil
+	}
+	current := resp.DomainContactsResult.Registrant
+	if current.FirstName == "" && current.LastName == "" && current.OrganizationName == "" && current.EmailAddress == "" {
+		// No registrant on record, so setting one changes none.
+		return nil, nil
+	}
+
+	// Resolving an address_id here lets the comparison see the entry's fields.
+	registrant, err := expandContactBlock(planned, newContactAddressBook(ctx, m.client))
+	if err != nil {
+		return nil, err
+	}
+	return materialRegistrantChanges(flattenContactInfo(current), flattenContactInfo(&registrant)), nil
+}
+
+// registrantChangeWarning reminds the user, after a material registrant change
+// was applied, of the confirmation and lock it starts.
+func registrantChangeWarning(data *schema.ResourceData) diag.Diagnostics {
+	domain := data.Get("domain").(string)
+	if !isGenericTLD(domain) || !data.HasChange("registrant") {
+		return nil
+	}
+	changed := materialRegistrantChanges(data.GetChange("registrant"))
+	if len(changed) == 0 {
+		return nil
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  "Registrant change of " + domain + " awaits confirmation",
+		Detail: fmt.Sprintf("The change to %s is a material change of registrant. Namecheap e-mails the previous and new registrant "+
+			"to confirm it, and the domain cannot be transferred to another registrar for 60 days. "+
+			"contacts_read_only stays true while Namecheap does not accept further contact changes.", strings.Join(changed, ", ")),
+	}}
+}
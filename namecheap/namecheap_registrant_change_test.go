@@ -0,0 +1,176 @@
+package namecheap_provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsGenericTLD(t *testing.T) {
+	for domain, want := range map[string]bool{
+		"example.com":          true,
+		"example.shop":         true,
+		"EXAMPLE.ORG.":         true,
+		"example.pt":           false,
+		"example.co.uk":        false,
+		"example.io":           false,
+		"example.xn--p1ai":     false,
+		"example.xn--fiqs8s":   false,
+		"example.xn--80asehdb": true,
+	} {
+		assert.Equal(t, want, isGenericTLD(domain), domain)
+	}
+}
+
+func TestMaterialRegistrantChanges(t *testing.T) {
+	block := func(changes map[string]interface{}) []interface{} {
+		m := fullContactMap()
+		m[contactAddressIDAttr] = 0
+		for k, v := range changes {
+			m[k] = v
+		}
+		return []interface{}{m}
+	}
+
+	assert.Empty(t, materialRegistrantChanges(block(nil), block(nil)))
+	assert.Empty(t, materialRegistrantChanges(block(nil), block(map[string]interface{}{"city": "Porto", "phone": "+351.1"})),
+		"address and phone changes are not material")
+	assert.Empty(t, materialRegistrantChanges(block(nil), block(map[string]interface{}{"email_address": strings.ToUpper(fullContactMap()["email_address"].(string))})),
+		"case-only changes are not material")
+	assert.Equal(t, []string{"last_name", "email_address"},
+		materialRegistrantChanges(block(nil), block(map[string]interface{}{"last_name": "Roe", "email_address": "new@example.com"})))
+	assert.Equal(t, []string{"organization"},
+		materialRegistrantChanges(block(nil), block(map[string]interface{}{"organization": ""})))
+
+	// The entry an address_id names is not known at plan time.
+	assert.Equal(t, []string{"address_id"},
+		materialRegistrantChanges(block(nil), []interface{}{map[string]interface{}{contactAddressIDAttr: 42}}))
+	assert.Empty(t, materialRegistrantChanges(
+		[]interface{}{map[string]interface{}{contactAddressIDAttr: 42}},
+		[]interface{}{map[string]interface{}{contactAddressIDAttr: 42}}))
+
+	assert.Empty(t, materialRegistrantChanges(nil, block(nil)))
+}
+
+// contactsPlan plans the change from state to config for a domain contacts
+// resource, returning the CustomizeDiff error.
+func contactsPlan(t *testing.T, state, config map[string]interface{}) error {
+	t.Helper()
+	resource := resourceNamecheapDomainContacts()
+	prior := schema.TestResourceDataRaw(t, resource.Schema, state)
+	prior.SetId(state["domain"].(string))
+	_, err := schema.InternalMap(resource.Schema).Diff(context.Background(), prior.State(),
+		terraform.NewResourceConfigRaw(config), resource.CustomizeDiff, nil, true)
+	return err
+}
+
+func withRegistrant(raw map[string]interface{}, attr, value string) map[string]interface{} {
+	block := map[string]interface{}{}
+	for k, v := range raw["registrant"].([]interface{})[0].(map[string]interface{}) {
+		block[k] = v
+	}
+	block[attr] = value
+	out := map[string]interface{}{}
+	for k, v := range raw {
+		out[k] = v
+	}
+	out["registrant"] = []interface{}{block}
+	return out
+}
+
+func TestCheckRegistrantChange(t *testing.T) {
+	state := registrantRaw()
+
+	err := contactsPlan(t, state, withRegistrant(state, "email_address", "new@example.com"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "changing email_address of example.com is a material change of registrant")
+	assert.Contains(t, err.Error(), "acknowledge_transfer_lock = true")
+
+	acknowledged := withRegistrant(state, "email_address", "new@example.com")
+	acknowledged["acknowledge_transfer_lock"] = true
+	assert.NoError(t, contactsPlan(t, state, acknowledged))
+
+	assert.NoError(t, contactsPlan(t, state, withRegistrant(state, "city", "Porto")), "not a material change")
+
+	ccTLD := withRegistrant(state, "domain", "example.pt")
+	ccTLD["domain"] = "example.pt"
+	state["domain"] = "example.pt"
+	assert.NoError(t, contactsPlan(t, state, withRegistrant(ccTLD, "last_name", "Roe")), "ccTLDs are not subject to the ICANN policy")
+}
+
+func TestResourceContactsUpdate_RegistrantChangeWarns(t *testing.T) {
+	url := contactsTestServer(t, func(command string) string {
+		switch command {
+		case "namecheap.domains.setContacts":
+			return xmlSetContactsOK("example.com")
+		case "namecheap.domains.getContacts":
+			return strings.Replace(xmlGetContacts("example.com"), `Readonly="false"`, `Readonly="true"`, 1)
+		}
+		return apiErrorXML("1010101", "unexpected "+command)
+	})
+
+	resource := resourceNamecheapDomainContacts()
+	state := registrantRaw()
+	config := withRegistrant(state, "last_name", "Roe")
+	config["acknowledge_transfer_lock"] = true
+
+	prior := schema.TestResourceDataRaw(t, resource.Schema, state)
+	prior.SetId("example.com")
+	diff, err := schema.InternalMap(resource.Schema).Diff(context.Background(), prior.State(),
+		terraform.NewResourceConfigRaw(config), resource.CustomizeDiff, nil, true)
+	require.NoError(t, err)
+	d, err := schema.InternalMap(resource.Schema).Data(prior.State(), diff)
+	require.NoError(t, err)
+
+	diags := resourceContactsUpdate(context.Background(), d, testMeta(newTestClient(url)))
+	require.False(t, diags.HasError(), "unexpected diags: %+v", diags)
+	require.NotEmpty(t, diags)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Equal(t, "Registrant change of example.com awaits confirmation", diags[0].Summary)
+	assert.True(t, d.Get("contacts_read_only").(bool))
+}
+
+func TestCheckRegistrantChange_Create(t *testing.T) {
+	contactsResponse := xmlGetContacts("example.com")
+	url := contactsTestServer(t, func(command string) string {
+		if command == "namecheap.domains.getContacts" {
+			return contactsResponse
+		}
+		return apiErrorXML("1010101", "unexpected "+command)
+	})
+	meta := testMeta(newTestClient(url))
+
+	plan := func(config map[string]interface{}) error {
+		_, err := resourceNamecheapDomainContacts().Diff(context.Background(), nil, terraform.NewResourceConfigRaw(config), meta)
+		return err
+	}
+
+	assert.NoError(t, plan(registrantRaw()), "the domain already holds this registrant")
+	assert.NoError(t, plan(withRegistrant(registrantRaw(), "city", "Porto")), "not a material change")
+
+	err := plan(withRegistrant(registrantRaw(), "email_address", "new@example.com"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "changing email_address of example.com is a material change of registrant")
+
+	acknowledged := withRegistrant(registrantRaw(), "email_address", "new@example.com")
+	acknowledged["acknowledge_transfer_lock"] = true
+	assert.NoError(t, plan(acknowledged))
+
+	contactsResponse = apiErrorXML("2019166", "Domain not found")
+	assert.NoError(t, plan(withRegistrant(registrantRaw(), "email_address", "new@example.com")), "a domain not on the account has no registrant yet")
+
+	contactsResponse = apiErrorXML("4011103", "Access denied")
+	err = plan(registrantRaw())
+	require.Error(t, err, "an unread registrant may change materially")
+	assert.Contains(t, err.Error(), "the current registrant of example.com could not be read")
+	assert.Contains(t, err.Error(), "acknowledge_transfer_lock = true")
+	acknowledged = registrantRaw()
+	acknowledged["acknowledge_transfer_lock"] = true
+	assert.NoError(t, plan(acknowledged), "the acknowledgement covers an unread registrant")
+}
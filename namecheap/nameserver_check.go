@@ -0,0 +1,272 @@
+package namecheap_provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Before a domain is delegated, verify_nameservers asks every target
+// nameserver for the domain's SOA record, directly and without recursion. A
+// nameserver that does not resolve, does not answer, answers without authority
+// (a lame delegation) or has no SOA for the domain would leave resolvers that
+// pick it unable to resolve the domain, so the delegation is not changed.
+
+// nameserverCheckTimeout bounds each resolution and each SOA query.
+const nameserverCheckTimeout = 5 * time.Second
+
+// defaultNameserverQueryPort is the port SOA queries are sent to unless the
+// nameserver_query_port setting says otherwise.
+const defaultNameserverQueryPort = 53
+
+// nameserverTarget is a nameserver a domain is about to be delegated to, with
+// its glue addresses when the configuration carries them: a nameserver under
+// the domain itself does not resolve before the domain is delegated to it.
+type nameserverTarget struct {
+	host      string
+	addresses []string
+}
+
+// nameserverTargetsFromHosts returns targets that are resolved by hostname.
+func nameserverTargetsFromHosts(hosts []string) []nameserverTarget {
+	targets := make([]nameserverTarget, 0, len(hosts))
+	for _, host := range hosts {
+		targets = append(targets, nameserverTarget{host: host})
+	}
+	return targets
+}
+
+// nameserverCheckResult is what one nameserver answered, one line per address.
+type nameserverCheckResult struct {
+	host     string
+	serving  []string
+	failures []string
+}
+
+func (r nameserverCheckResult) ok() bool {
+	return len(r.serving) > 0
+}
+
+// dnsResolverAddress normalizes a dns_resolver value to host:port, adding the
+// DNS port when it is left out.
+func dnsResolverAddress(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	if _, _, err := net.SplitHostPort(value); err == nil {
+		return value
+	}
+	return net.JoinHostPort(strings.Trim(value, "[]"), "53")
+}
+
+func validateDNSResolver(val interface{}, key string) (warns []string, errs []error) {
+	value := strings.TrimSpace(val.(string))
+	if value == "" {
+		return
+	}
+	host, port, err := net.SplitHostPort(dnsResolverAddress(value))
+	if portNumber, portErr := strconv.Atoi(port); err != nil || net.ParseIP(host) == nil || portErr != nil || portNumber < 1 || portNumber > 65535 {
+		errs = append(errs, fmt.Errorf("%q must be an IP address, optionally with a port (e.g. \"9.9.9.9\" or \"127.0.0.1:5353\"), got %q", key, value))
+	}
+	return
+}
+
+// nameserverResolver returns the resolver nameserver hostnames are looked up
+// with: the system's, or the one at address.
+func nameserverResolver(address string) *net.Resolver {
+	if address == "" {
+		return net.DefaultResolver
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+}
+
+// verifyNameservers checks that every target serves domain, and returns one
+// error per nameserver that does not. The ones that do are named in each
+// error's detail, so the diagnostics describe the whole delegation. A
+// nameserver that serves the zone from some of its addresses but not others
+// passes with a warning naming the failed ones. Hostnames are looked up with
+// the config's dns_resolver, and each address is asked on its
+// nameserver_query_port.
+func verifyNameservers(ctx context.Context, config *providerConfig, domain string, targets []nameserverTarget) diag.Diagnostics {
+	resolver := nameserverResolver(config.dnsResolver)
+
+	results := make([]nameserverCheckResult, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target nameserverTarget) {
+			defer wg.Done()
+			results[i] = checkNameserver(ctx, resolver, config.nameserverQueryPort, domain, target)
+		}(i, target)
+	}
+	wg.Wait()
+
+	var serving []string
+	var diags diag.Diagnostics
+	for _, result := range results {
+		if !result.ok() {
+			continue
+		}
+		serving = append(serving, fmt.Sprintf("%s (%s)", result.host, strings.Join(result.serving, ", ")))
+		if len(result.failures) > 0 {
+			diags = append(diags, nameserverPartialWarning(domain, result))
+		}
+	}
+	if len(serving) == len(results) {
+		log.Printf("[INFO] namecheap: every nameserver serves %s: %s", domain, strings.Join(serving, "; "))
+		return diags
+	}
+
+	for _, result := range results {
+		if result.ok() {
+			continue
+		}
+		detail := fmt.Sprintf("Asked for the SOA record of %s, %s did not answer for the zone:\n  - %s\n\n",
+			domain, result.host, strings.Join(result.failures, "\n  - "))
+		if len(serving) > 0 {
+			detail += fmt.Sprintf("Serving %s: %s.\n\n", domain, strings.Join(serving, "; "))
+		}
+		detail += fmt.Sprintf("The delegation of %s was not changed. Check the nameserver's spelling and that it has the zone loaded, "+
+			"or set verify_nameservers = false to delegate anyway.", domain)
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Nameserver %s does not serve %s", result.host, domain),
+			Detail:   detail,
+		})
+	}
+	return diags
+}
+
+// nameserverPartialWarning describes a nameserver that serves domain from
+// some of its addresses only. It is not an error: an address the machine
+// running Terraform cannot reach may well answer resolvers elsewhere.
+func nameserverPartialWarning(domain string, result nameserverCheckResult) diag.Diagnostic {
+	return diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("Nameserver %s did not answer for %s on every address", result.host, domain),
+		Detail: fmt.Sprintf("Asked for the SOA record of %s, %s answered with authority from %s, but not from:\n  - %s\n\n"+
+			"The nameserver was accepted. If these addresses are reachable from the internet, resolvers that pick them "+
+			"will fail to resolve %s; if this machine only lacks a route to them (IPv6, say), the warning can be ignored.",
+			domain, result.host, strings.Join(result.serving, "; "), strings.Join(result.failures, "\n  - "), domain),
+	}
+}
+
+// checkNameserver asks each address of target, on port, for the SOA record of
+// domain. One authoritative answer is enough for the nameserver to pass, since
+// the machine running Terraform may not reach every address family it listens
+// on; the addresses that failed are still reported.
+func checkNameserver(ctx context.Context, resolver *net.Resolver, port int, domain string, target nameserverTarget) nameserverCheckResult {
+	result := nameserverCheckResult{host: target.host}
+
+	addresses := target.addresses
+	if len(addresses) == 0 {
+		lookupCtx, cancel := context.WithTimeout(ctx, nameserverCheckTimeout)
+		resolved, err := resolver.LookupIPAddr(lookupCtx, strings.TrimSuffix(target.host, ".")+".")
+		cancel()
+		if err != nil {
+			result.failures = append(result.failures, fmt.Sprintf("does not resolve: %s", err))
+			return result
+		}
+		for _, addr := range resolved {
+			addresses = append(addresses, addr.IP.String())
+		}
+		sort.Strings(addresses)
+	}
+
+	for _, address := range addresses {
+		serial, err := querySOA(ctx, net.JoinHostPort(address, strconv.Itoa(port)), domain)
+		if err != nil {
+			result.failures = append(result.failures, fmt.Sprintf("%s: %s", address, err))
+			continue
+		}
+		result.serving = append(result.serving, fmt.Sprintf("%s, serial %d", address, serial))
+	}
+	return result
+}
+
+// querySOA sends a non-recursive SOA query for domain to server over UDP and
+// returns the serial of an authoritative answer.
+func querySOA(ctx context.Context, server, domain string) (uint32, error) {
+	name, err := dnsmessage.NewName(strings.TrimSuffix(strings.ToLower(domain), ".") + ".")
+	if err != nil {
+		return 0, err
+	}
+	var idBytes [2]byte
+	if _, err := rand.Read(idBytes[:]); err != nil {
+		return 0, err
+	}
+	id := binary.BigEndian.Uint16(idBytes[:])
+
+	query, err := (&dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id},
+		Questions: []dnsmessage.Question{{Name: name, Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET}},
+	}).Pack()
+	if err != nil {
+		return 0, err
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, nameserverCheckTimeout)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(queryCtx, "udp", server)
+	if err != nil {
+		return 0, fmt.Errorf("no answer: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := queryCtx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(query); err != nil {
+		return 0, fmt.Errorf("no answer: %w", err)
+	}
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return 0, fmt.Errorf("no answer: %w", err)
+		}
+		var response dnsmessage.Message
+		if err := response.Unpack(buf[:n]); err != nil || response.ID != id || !response.Response {
+			// Not the answer to this query; keep waiting for it.
+			continue
+		}
+		return soaSerial(&response, name)
+	}
+}
+
+// soaSerial returns the serial of the SOA record for name in an authoritative
+// response, or why the response does not show the server serving the zone.
+func soaSerial(response *dnsmessage.Message, name dnsmessage.Name) (uint32, error) {
+	if response.RCode != dnsmessage.RCodeSuccess {
+		return 0, fmt.Errorf("answered %s", strings.TrimPrefix(response.RCode.String(), "RCode"))
+	}
+	if !response.Authoritative {
+		return 0, fmt.Errorf("answered without authority (lame delegation)")
+	}
+	for _, answer := range response.Answers {
+		soa, ok := answer.Body.(*dnsmessage.SOAResource)
+		if ok && strings.EqualFold(answer.Header.Name.String(), name.String()) {
+			return soa.Serial, nil
+		}
+	}
+	return 0, fmt.Errorf("has no SOA record for %s", strings.TrimSuffix(name.String(), "."))
+}
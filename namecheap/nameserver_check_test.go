@@ -0,0 +1,279 @@
+package namecheap_provider
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsStandIn is a UDP DNS server on a local port that answers every query
+// with what respond returns.
+type dnsStandIn struct {
+	addr    string
+	queries atomic.Int32
+}
+
+type dnsStandInAnswer struct {
+	rcode         dnsmessage.RCode
+	authoritative bool
+	answers       []dnsmessage.Resource
+}
+
+func startDNSStandIn(t *testing.T, respond func(q dnsmessage.Question) dnsStandInAnswer) *dnsStandIn {
+	t.Helper()
+	return startDNSStandInAt(t, "127.0.0.1:0", respond)
+}
+
+// startDNSStandInAt starts a stand-in listening on address.
+func startDNSStandInAt(t *testing.T, address string, respond func(q dnsmessage.Question) dnsStandInAnswer) *dnsStandIn {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", address)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	s := &dnsStandIn{addr: conn.LocalAddr().String()}
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, peer, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var query dnsmessage.Message
+			if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) != 1 {
+				continue
+			}
+			s.queries.Add(1)
+			answer := respond(query.Questions[0])
+			response := dnsmessage.Message{
+				Header: dnsmessage.Header{
+					ID:            query.ID,
+					Response:      true,
+					Authoritative: answer.authoritative,
+					RCode:         answer.rcode,
+				},
+				Questions: query.Questions,
+				Answers:   answer.answers,
+			}
+			packed, err := response.Pack()
+			if err != nil {
+				continue
+			}
+			_, _ = conn.WriteTo(packed, peer)
+		}
+	}()
+	return s
+}
+
+func soaAnswer(name dnsmessage.Name, serial uint32) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: name, Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET, TTL: 3600},
+		Body: &dnsmessage.SOAResource{
+			NS:     dnsmessage.MustNewName("ns1.example.net."),
+			MBox:   dnsmessage.MustNewName("hostmaster.example.net."),
+			Serial: serial,
+		},
+	}
+}
+
+// authoritativeSOA answers as a nameserver serving the zone.
+func authoritativeSOA(serial uint32) func(dnsmessage.Question) dnsStandInAnswer {
+	return func(q dnsmessage.Question) dnsStandInAnswer {
+		return dnsStandInAnswer{authoritative: true, answers: []dnsmessage.Resource{soaAnswer(q.Name, serial)}}
+	}
+}
+
+// standInResolver answers A queries for the names in hosts and nothing else.
+func standInResolver(t *testing.T, hosts map[string]string) *dnsStandIn {
+	return startDNSStandIn(t, func(q dnsmessage.Question) dnsStandInAnswer {
+		ip, ok := hosts[strings.TrimSuffix(q.Name.String(), ".")]
+		if !ok {
+			return dnsStandInAnswer{rcode: dnsmessage.RCodeNameError}
+		}
+		if q.Type != dnsmessage.TypeA {
+			return dnsStandInAnswer{}
+		}
+		var a [4]byte
+		copy(a[:], net.ParseIP(ip).To4())
+		return dnsStandInAnswer{answers: []dnsmessage.Resource{{
+			Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+			Body:   &dnsmessage.AResource{A: a},
+		}}}
+	})
+}
+
+// startNameserverStandIns starts a stand-in for each loopback address in
+// nameservers, all on one port the way real nameservers share port 53, and
+// returns the config that sends verify_nameservers' queries to that port and
+// looks hostnames up with resolver. A loopback address with no stand-in leaves
+// the query unanswered.
+func startNameserverStandIns(t *testing.T, resolver *dnsStandIn, nameservers map[string]func(q dnsmessage.Question) dnsStandInAnswer) (*providerConfig, map[string]*dnsStandIn) {
+	t.Helper()
+	addresses := make([]string, 0, len(nameservers))
+	for address := range nameservers {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	port := "0"
+	standIns := make(map[string]*dnsStandIn, len(addresses))
+	for _, address := range addresses {
+		standIn := startDNSStandInAt(t, net.JoinHostPort(address, port), nameservers[address])
+		_, port, _ = net.SplitHostPort(standIn.addr)
+		standIns[address] = standIn
+	}
+	if port == "0" {
+		// Nothing listens on the discard port.
+		port = "9"
+	}
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return &providerConfig{defaults: builtinProviderDefaults(), dnsResolver: resolver.addr, nameserverQueryPort: portNumber}, standIns
+}
+
+func TestDNSResolverAddress(t *testing.T) {
+	assert.Equal(t, "", dnsResolverAddress(""))
+	assert.Equal(t, "9.9.9.9:53", dnsResolverAddress("9.9.9.9"))
+	assert.Equal(t, "127.0.0.1:5353", dnsResolverAddress("127.0.0.1:5353"))
+	assert.Equal(t, "[2620:fe::fe]:53", dnsResolverAddress("2620:fe::fe"))
+	assert.Equal(t, "[2620:fe::fe]:53", dnsResolverAddress("[2620:fe::fe]"))
+
+	for _, ok := range []string{"", "9.9.9.9", "127.0.0.1:5353", "2620:fe::fe", "[2620:fe::fe]:53"} {
+		_, errs := validateDNSResolver(ok, "dns_resolver")
+		assert.Empty(t, errs, ok)
+	}
+	for _, bad := range []string{"dns.example.net", "dns.example.net:53", "9.9.9.9:"} {
+		_, errs := validateDNSResolver(bad, "dns_resolver")
+		assert.NotEmpty(t, errs, bad)
+	}
+}
+
+func TestVerifyNameservers_AllServing(t *testing.T) {
+	resolver := standInResolver(t, map[string]string{"ns1.provider.net": "127.0.0.11", "ns2.provider.net": "127.0.0.12"})
+	config, standIns := startNameserverStandIns(t, resolver, map[string]func(dnsmessage.Question) dnsStandInAnswer{
+		"127.0.0.11": authoritativeSOA(2024010101),
+		"127.0.0.12": authoritativeSOA(2024010101),
+	})
+	ns1, ns2 := standIns["127.0.0.11"], standIns["127.0.0.12"]
+
+	diags := verifyNameservers(context.Background(), config, "example.com",
+		nameserverTargetsFromHosts([]string{"ns1.provider.net", "ns2.provider.net"}))
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.EqualValues(t, 1, ns1.queries.Load())
+	assert.EqualValues(t, 1, ns2.queries.Load())
+}
+
+// TestVerifyNameservers_Failures reports each way a nameserver can fail to
+// serve the zone as its own diagnostic, naming the one that does serve it.
+func TestVerifyNameservers_Failures(t *testing.T) {
+	resolver := standInResolver(t, map[string]string{
+		"ns1.provider.net":     "127.0.0.11",
+		"lame.provider.net":    "127.0.0.12",
+		"refused.provider.net": "127.0.0.13",
+		"empty.provider.net":   "127.0.0.14",
+	})
+	config, _ := startNameserverStandIns(t, resolver, map[string]func(dnsmessage.Question) dnsStandInAnswer{
+		"127.0.0.11": authoritativeSOA(7),
+		"127.0.0.12": func(q dnsmessage.Question) dnsStandInAnswer {
+			return dnsStandInAnswer{answers: []dnsmessage.Resource{soaAnswer(q.Name, 7)}}
+		},
+		"127.0.0.13": func(dnsmessage.Question) dnsStandInAnswer {
+			return dnsStandInAnswer{rcode: dnsmessage.RCodeRefused}
+		},
+		"127.0.0.14": func(dnsmessage.Question) dnsStandInAnswer {
+			return dnsStandInAnswer{authoritative: true}
+		},
+	})
+
+	diags := verifyNameservers(context.Background(), config, "example.com", nameserverTargetsFromHosts([]string{
+		"ns1.provider.net", "lame.provider.net", "refused.provider.net", "empty.provider.net", "typo.provider.net",
+	}))
+	require.Len(t, diags, 4)
+
+	want := map[string]string{
+		"Nameserver lame.provider.net does not serve example.com":    "127.0.0.12: answered without authority (lame delegation)",
+		"Nameserver refused.provider.net does not serve example.com": "127.0.0.13: answered Refused",
+		"Nameserver empty.provider.net does not serve example.com":   "127.0.0.14: has no SOA record for example.com",
+		"Nameserver typo.provider.net does not serve example.com":    "does not resolve",
+	}
+	for _, d := range diags {
+		reason, ok := want[d.Summary]
+		require.True(t, ok, "unexpected diagnostic %q", d.Summary)
+		assert.Contains(t, d.Detail, reason)
+		assert.Contains(t, d.Detail, "Serving example.com: ns1.provider.net (127.0.0.11, serial 7)")
+		assert.Contains(t, d.Detail, "The delegation of example.com was not changed")
+	}
+}
+
+// TestVerifyNameservers_GlueAddresses asks a nameserver at the addresses it
+// was given, without resolving it, and accepts it when one of them answers.
+func TestVerifyNameservers_GlueAddresses(t *testing.T) {
+	resolver := standInResolver(t, nil)
+	config, _ := startNameserverStandIns(t, resolver, map[string]func(dnsmessage.Question) dnsStandInAnswer{
+		"127.0.0.11": authoritativeSOA(1),
+	})
+
+	diags := verifyNameservers(context.Background(), config, "example.com", []nameserverTarget{
+		{host: "ns1.example.com", addresses: []string{"127.0.0.11", "::1"}},
+	})
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.EqualValues(t, 0, resolver.queries.Load())
+}
+
+// TestVerifyNameservers_PartlyServing passes a nameserver one of whose
+// addresses answers without authority, and warns about that address.
+func TestVerifyNameservers_PartlyServing(t *testing.T) {
+	resolver := standInResolver(t, nil)
+	config, _ := startNameserverStandIns(t, resolver, map[string]func(dnsmessage.Question) dnsStandInAnswer{
+		"127.0.0.11": authoritativeSOA(3),
+		"127.0.0.12": func(q dnsmessage.Question) dnsStandInAnswer {
+			return dnsStandInAnswer{answers: []dnsmessage.Resource{soaAnswer(q.Name, 3)}}
+		},
+	})
+
+	diags := verifyNameservers(context.Background(), config, "example.com", []nameserverTarget{
+		{host: "ns1.example.com", addresses: []string{"127.0.0.11", "127.0.0.12"}},
+	})
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Equal(t, "Nameserver ns1.example.com did not answer for example.com on every address", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "answered with authority from 127.0.0.11, serial 3")
+	assert.Contains(t, diags[0].Detail, "127.0.0.12: answered without authority (lame delegation)")
+}
+
+// TestResourceRecordCreate_VerifyNameserversFails proves a failed check stops
+// the apply before the delegation is touched.
+func TestResourceRecordCreate_VerifyNameserversFails(t *testing.T) {
+	resolver := standInResolver(t, map[string]string{"ns1.provider.net": "127.0.0.11"})
+	config, _ := startNameserverStandIns(t, resolver, nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		t.Errorf("unexpected command after a failed check: %s", r.FormValue("Command"))
+	}))
+	defer server.Close()
+	meta := &providerMeta{client: newTestClient(server.URL), config: config}
+
+	data := schema.TestResourceDataRaw(t, resourceNamecheapDomainRecords(nil).Schema, map[string]interface{}{
+		"domain":             "example.com",
+		"mode":               ncModeOverwrite,
+		"nameservers":        []interface{}{"ns1.provider.net", "ns2.provider.net"},
+		"verify_nameservers": true,
+	})
+	diags := resourceRecordCreate(context.Background(), data, meta)
+	require.True(t, diags.HasError())
+	assert.Len(t, diags, 2)
+	assert.Empty(t, data.Id())
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
 	"github.com/namecheap/terraform-provider-namecheap/namecheap/internal/mutexkv"
 )
@@ -36,7 +37,8 @@ const (
 )
 
 func Provider() *schema.Provider {
-	return &schema.Provider{
+	defaults := &providerDefaultsSource{}
+	p := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"user_name": {
 				Type:        schema.TypeString,
@@ -69,6 +71,70 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("NAMECHEAP_CLIENT_IP", nil),
 			},
 
+			"client_ip_detection": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Controls how client_ip is auto-detected when it is left unset: which sources are asked, in what order, over which address family, and whether two of them must agree. Omitting the block keeps the single api.ipify.org lookup with a 5 second timeout. When client_ip is set, the block is only used by namecheap_api_access and verify_credentials_on_configure to compare the configured address with the detected one.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"urls": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Description: "HTTPS endpoints that answer a GET with the caller's public IP address as plain text, queried in order until one succeeds (or, with require_agreement, until two agree). Plain http is refused: the answer becomes the ClientIp sent with every API call, so it must not be open to tampering on the way. Defaults to https://api.ipify.org when neither urls nor command is set.",
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validation.IsURLWithHTTPS,
+							},
+						},
+						"command": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Description: "A local command, as a program followed by its arguments (no shell is involved), that prints the caller's public IP address on standard output. It is queried before any urls.",
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validation.StringIsNotEmpty,
+							},
+						},
+						"address_family": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      clientIPFamilyAny,
+							ValidateFunc: validation.StringInSlice([]string{clientIPFamilyAny, clientIPFamilyIPv4, clientIPFamilyIPv6}, false),
+							Description: fmt.Sprintf("Which address family to detect: %q (default) accepts whatever the network stack uses, %q and %q pin the HTTP connection to that family and reject answers of the other one. Use %q when Namecheap is reached over IPv6 egress.",
+								clientIPFamilyAny, clientIPFamilyIPv4, clientIPFamilyIPv6, clientIPFamilyIPv6),
+						},
+						"timeout": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          defaultClientIPDetectionTimeout,
+							ValidateDiagFunc: validatePositiveDuration,
+							Description:      "Timeout applied to each detection source on its own, as a Go duration string (e.g. \"5s\"). Defaults to \"5s\".",
+						},
+						"require_agreement": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Require two sources to report the same address before it is used. Needs at least two sources (urls plus command). Protects against a source behind a different egress path reporting an address Namecheap never sees.",
+						},
+					},
+				},
+			},
+
+			"verify_credentials_on_configure": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Run the namecheap_api_access checks while the provider is configured and fail immediately, naming the cause, when the credentials are rejected or the calling IP is not whitelisted. Costs one API call (namecheap.users.getBalances) and one public IP lookup per run. Defaults to false.",
+				DefaultFunc: schema.EnvDefaultFunc("NAMECHEAP_VERIFY_CREDENTIALS_ON_CONFIGURE", false),
+			},
+
+			"client_ip_cache_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Path of a file where an auto-detected client_ip is stored after each successful detection, and read back when every detection source is unreachable, so a transient outage of the detection endpoints does not fail the run. A cached address is used with a warning. Ignored when client_ip is set.",
+				DefaultFunc: schema.EnvDefaultFunc("NAMECHEAP_CLIENT_IP_CACHE_FILE", nil),
+			},
+
 			"use_sandbox": {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -123,23 +189,121 @@ func Provider() *schema.Provider {
 				DefaultFunc:      schema.EnvDefaultFunc("NAMECHEAP_REQUEST_TIMEOUT", defaultRequestTimeout),
 				ValidateDiagFunc: validatePositiveDuration,
 			},
+
+			"defaults": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Values namecheap_domain_records and namecheap_domain_host_record use for attributes a resource leaves unset, in place of the built-in defaults. Plans show the effective value, so changing a default plans an in-place update of every record that relies on it.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"ttl": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntBetween(namecheap.MinTTL, namecheap.MaxTTL),
+							Description:  fmt.Sprintf("Default record ttl in seconds, between %d and %d. The built-in default is %d.", namecheap.MinTTL, namecheap.MaxTTL, defaultRecordTTL),
+						},
+						"mx_pref": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntBetween(0, 255),
+							Description:  fmt.Sprintf("Default MX preference, between 0 and 255. The built-in default is %d. Only MX records have a preference; for other types Namecheap stores a fixed %d whatever is sent.", defaultRecordMXPref, hostRecordFixedMXPref),
+						},
+						"records_mode": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice([]string{ncModeMerge, ncModeOverwrite}, true),
+							Description:  fmt.Sprintf("Default mode of namecheap_domain_records: %s or %s. The built-in default is %s. Setting %s here makes every records resource without a mode own its whole zone.", ncModeMerge, ncModeOverwrite, ncModeMerge, ncModeOverwrite),
+						},
+						"email_type": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice(namecheap.AllowedEmailTypeValues, false),
+							Description:  fmt.Sprintf("Default email_type of namecheap_domain_records: %s. Unset by default, which leaves each domain's email setting as it is. Has no effect on a domain delegated to custom nameservers.", strings.Join(namecheap.AllowedEmailTypeValues, ", ")),
+						},
+					},
+				},
+			},
+
+			"proxy_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Proxy through which every outbound connection is made, both Namecheap API calls and client_ip detection, as an http://, https:// or socks5:// URL. Credentials may be embedded as user:password@. When unset, the standard HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables apply.",
+				DefaultFunc: schema.EnvDefaultFunc("NAMECHEAP_PROXY_URL", nil),
+			},
+
+			"ca_bundle_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Path of a PEM file of CA certificates trusted in addition to the system roots, for an egress proxy that re-signs TLS traffic with its own CA. Applies to Namecheap API calls and client_ip detection.",
+				DefaultFunc: schema.EnvDefaultFunc("NAMECHEAP_CA_BUNDLE_FILE", nil),
+			},
+
+			"client_cert_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Path of a PEM client certificate presented on every TLS connection, for an egress proxy that requires mutual TLS. Must be set together with client_key_file.",
+				DefaultFunc: schema.EnvDefaultFunc("NAMECHEAP_CLIENT_CERT_FILE", nil),
+			},
+
+			"client_key_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Path of the PEM private key for client_cert_file. Must be set together with client_cert_file.",
+				DefaultFunc: schema.EnvDefaultFunc("NAMECHEAP_CLIENT_KEY_FILE", nil),
+			},
+
+			"insecure_skip_verify": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Skip TLS certificate verification on every outbound connection. Only accepted together with use_sandbox = true, since it would expose a production API key to any interceptor; prefer ca_bundle_file. Defaults to false.",
+				DefaultFunc: schema.EnvDefaultFunc("NAMECHEAP_INSECURE_SKIP_VERIFY", false),
+			},
+
+			"dns_resolver": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "DNS resolver that verify_nameservers looks nameserver hostnames up with, as an IP address with an optional port (e.g. \"9.9.9.9\" or \"127.0.0.1:5353\"). Defaults to the system resolver.",
+				DefaultFunc:  schema.EnvDefaultFunc("NAMECHEAP_DNS_RESOLVER", nil),
+				ValidateFunc: validateDNSResolver,
+			},
+
+			"nameserver_query_port": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "Port verify_nameservers sends its SOA queries to on each nameserver address. Only needed to check nameservers that listen on a non-standard port, such as a test setup. Defaults to 53.",
+				DefaultFunc:  schema.EnvDefaultFunc("NAMECHEAP_NAMESERVER_QUERY_PORT", defaultNameserverQueryPort),
+				ValidateFunc: validation.IsPortNumber,
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"namecheap_domain_records":      resourceNamecheapDomainRecords(),
+			"namecheap_domain_records":      resourceNamecheapDomainRecords(defaults),
 			"namecheap_personal_nameserver": resourceNamecheapPersonalNameserver(),
 			"namecheap_domain_contacts":     resourceNamecheapDomainContacts(),
 			"namecheap_email_forwarding":    resourceNamecheapEmailForwarding(),
-			"namecheap_domain_host_record":  resourceNamecheapDomainHostRecord(),
+			"namecheap_email_forward":       resourceNamecheapEmailForward(),
+			"namecheap_domain_host_record":  resourceNamecheapDomainHostRecord(defaults),
+			"namecheap_address":             resourceNamecheapAddress(),
+			"namecheap_portfolio_contacts":  resourceNamecheapPortfolioContacts(),
+			"namecheap_email_setup":         resourceNamecheapEmailSetup(),
+			"namecheap_domain_delegation":   resourceNamecheapDomainDelegation(),
+			"namecheap_add_funds_request":   resourceNamecheapAddFundsRequest(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"namecheap_domain":          dataSourceNamecheapDomain(),
-			"namecheap_domains":         dataSourceNamecheapDomains(),
-			"namecheap_domain_records":  dataSourceNamecheapDomainRecords(),
-			"namecheap_account_balance": dataSourceNamecheapAccountBalance(),
-			"namecheap_tld_pricing":     dataSourceNamecheapTldPricing(),
+			"namecheap_domain":            dataSourceNamecheapDomain(),
+			"namecheap_domains":           dataSourceNamecheapDomains(),
+			"namecheap_domain_records":    dataSourceNamecheapDomainRecords(),
+			"namecheap_portfolio_records": dataSourceNamecheapPortfolioRecords(),
+			"namecheap_account_balance":   dataSourceNamecheapAccountBalance(),
+			"namecheap_tld_pricing":       dataSourceNamecheapTldPricing(),
+			"namecheap_renewal_forecast":  dataSourceNamecheapRenewalForecast(),
+			"namecheap_api_access":        dataSourceNamecheapAPIAccess(),
+			"namecheap_addresses":         dataSourceNamecheapAddresses(),
 		},
 		ConfigureContextFunc: configureContext,
 	}
+	defaults.provider = p
+	return p
 }
 
 func configureContext(ctx context.Context, data *schema.ResourceData) (interface{}, diag.Diagnostics) {
@@ -267,39 +431,37 @@ func configureContext(ctx context.Context, data *schema.ResourceData) (interface
 		}
 	}
 
+	settings, diags := transportSettingsFromConfig(data)
+	if diags.HasError() {
+		return nil, diags
+	}
+	transport, diags := settings.build()
+	if diags.HasError() {
+		return nil, diags
+	}
+	detector, diags := clientIPDetectorFromConfig(data, transport)
+	if diags.HasError() {
+		return nil, diags
+	}
+
 	// client_ip is only meaningful when it names the public IP the Namecheap
 	// API sees as the caller (and which the account has whitelisted). When it
 	// is left unset we auto-detect that public IP rather than sending the old
 	// non-functional 0.0.0.0 default. An explicitly-set value (inline or via
 	// NAMECHEAP_CLIENT_IP) is always honored unchanged, so this stays
 	// non-breaking.
+	baseCtx := ctx
+	if baseCtx == nil {
+		baseCtx = context.Background()
+	}
+	ipSource := clientIPSourceConfigured
 	if clientIp == "" {
-		baseCtx := ctx
-		if baseCtx == nil {
-			baseCtx = context.Background()
-		}
-		detectCtx, cancel := context.WithTimeout(baseCtx, 5*time.Second)
-		defer cancel()
-
-		ip, detectErr := detectClientIP(detectCtx, &http.Client{})
-		if detectErr != nil {
-			return nil, diag.Diagnostics{
-				{
-					Severity: diag.Error,
-					Summary:  "Unable to auto-detect client_ip",
-					Detail: fmt.Sprintf(
-						"client_ip is unset and the provider could not auto-detect this machine's public IP address: %s. "+
-							"To resolve, either set the client_ip argument (or the NAMECHEAP_CLIENT_IP environment variable) to the "+
-							"public IP this provider calls from, and make sure that IP is whitelisted at "+
-							"https://ap.www.namecheap.com/settings/tools/apiaccess/whitelisted-ips.",
-						detectErr,
-					),
-					AttributePath: cty.Path{cty.GetAttrStep{Name: "client_ip"}},
-				},
-			}
+		ip, source, detectDiags := autoDetectClientIP(baseCtx, data, detector)
+		diags = append(diags, detectDiags...)
+		if detectDiags.HasError() {
+			return nil, diags
 		}
-		clientIp = ip
-		log.Printf("[INFO] namecheap: auto-detected client_ip %s", ip)
+		clientIp, ipSource = ip, source
 	}
 
 	client := namecheap.NewClient(&namecheap.ClientOptions{
@@ -308,7 +470,7 @@ func configureContext(ctx context.Context, data *schema.ResourceData) (interface
 		ApiKey:     apiKey,
 		ClientIp:   clientIp,
 		UseSandbox: useSandbox,
-		HTTPClient: &http.Client{Timeout: requestTimeout},
+		HTTPClient: &http.Client{Timeout: requestTimeout, Transport: transport},
 		RateLimit: &namecheap.RateLimitOptions{
 			PerMinute: requestsPerMinute,
 		},
@@ -334,7 +496,134 @@ func configureContext(ctx context.Context, data *schema.ResourceData) (interface
 	// (see endpoint_override_testacc.go), which the acceptance-test harness uses.
 	applyTestEndpointOverride(client)
 
-	return client, diag.Diagnostics{}
+	config := &providerConfig{
+		clientIPDetector:    detector,
+		clientIPSource:      ipSource,
+		defaults:            providerDefaultsFromConfig(data),
+		dnsResolver:         dnsResolverAddress(data.Get("dns_resolver").(string)),
+		nameserverQueryPort: data.Get("nameserver_query_port").(int),
+	}
+
+	// The pre-flight runs last, against the endpoint the client will really
+	// use, so a failure names the cause before any resource gets to report the
+	// ambiguous 1011102 in its place.
+	if data.Get("verify_credentials_on_configure").(bool) {
+		report := checkAPIAccess(baseCtx, client, config)
+		if !report.ok {
+			return nil, append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Namecheap API access check failed",
+				Detail:        report.verdict,
+				AttributePath: cty.Path{cty.GetAttrStep{Name: "verify_credentials_on_configure"}},
+			})
+		}
+	}
+
+	return &providerMeta{client: client, config: config}, diags
+}
+
+// autoDetectClientIP resolves client_ip when the configuration leaves it unset,
+// from the sources in the client_ip_detection block (or api.ipify.org alone when
+// the block is absent), falling back to client_ip_cache_file when every source
+// fails. A successful detection refreshes the cache file; a failure to write it
+// is logged rather than failing the run, since the address itself is good.
+// The returned source says which of the two produced the address.
+func autoDetectClientIP(ctx context.Context, data *schema.ResourceData, detector *clientIPDetector) (string, string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	cacheFile := strings.TrimSpace(data.Get("client_ip_cache_file").(string))
+
+	ip, detectErr := detector.detect(ctx)
+	if detectErr == nil {
+		log.Printf("[INFO] namecheap: auto-detected client_ip %s", ip)
+		if cacheFile != "" {
+			if err := writeCachedClientIP(cacheFile, ip); err != nil {
+				log.Printf("[WARN] namecheap: %s", err)
+			}
+		}
+		return ip, clientIPSourceDetected, diags
+	}
+
+	if cacheFile != "" {
+		cached, cacheErr := readCachedClientIP(cacheFile, detector.family)
+		if cacheErr == nil {
+			return cached, clientIPSourceCache, append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Using cached client_ip",
+				Detail: fmt.Sprintf("client_ip could not be auto-detected (%s), so the address last detected, %s, was read from %s. "+
+					"If this machine's egress address has changed since, Namecheap will reject the calls as coming from a non-whitelisted IP.",
+					detectErr, cached, cacheFile),
+				AttributePath: cty.Path{cty.GetAttrStep{Name: "client_ip_cache_file"}},
+			})
+		}
+		detectErr = fmt.Errorf("%w; the cache fallback also failed: %s", detectErr, cacheErr)
+	}
+
+	return "", "", append(diags, diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  "Unable to auto-detect client_ip",
+		Detail: fmt.Sprintf(
+			"client_ip is unset and the provider could not auto-detect this machine's public IP address: %s. "+
+				"To resolve, either set the client_ip argument (or the NAMECHEAP_CLIENT_IP environment variable) to the "+
+				"public IP this provider calls from, and make sure that IP is whitelisted at "+
+				"https://ap.www.namecheap.com/settings/tools/apiaccess/whitelisted-ips. Detection endpoints that are reachable "+
+				"from this network can be listed in client_ip_detection, and client_ip_cache_file keeps the last good address "+
+				"for when they are not.",
+			detectErr,
+		),
+		AttributePath: cty.Path{cty.GetAttrStep{Name: "client_ip"}},
+	})
+}
+
+// clientIPDetectorFromConfig expands the client_ip_detection block into a
+// clientIPDetector. An absent block yields the historical behavior: one lookup
+// against ipDetectionURL with a 5 second timeout over any address family.
+// Detection dials through transport, the same one the API client uses, so it
+// reports the address the API calls actually leave from.
+func clientIPDetectorFromConfig(data *schema.ResourceData, transport *http.Transport) (*clientIPDetector, diag.Diagnostics) {
+	detector := defaultClientIPDetector()
+
+	if raw, ok := data.GetOk("client_ip_detection"); ok {
+		blocks := raw.([]interface{})
+		if len(blocks) > 0 && blocks[0] != nil {
+			block := blocks[0].(map[string]interface{})
+			detector.urls = convertInterfacesToString(block["urls"].([]interface{}))
+			detector.command = convertInterfacesToString(block["command"].([]interface{}))
+			if family, _ := block["address_family"].(string); family != "" {
+				detector.family = family
+			}
+			detector.requireAgreement = block["require_agreement"].(bool)
+
+			if timeoutRaw, _ := block["timeout"].(string); timeoutRaw != "" {
+				parsed, err := time.ParseDuration(timeoutRaw)
+				if err != nil || parsed <= 0 {
+					return nil, diag.Diagnostics{{
+						Severity:      diag.Error,
+						Summary:       "Invalid client_ip_detection timeout",
+						Detail:        fmt.Sprintf("client_ip_detection.timeout %q must be a Go duration string greater than zero", timeoutRaw),
+						AttributePath: cty.Path{cty.GetAttrStep{Name: "client_ip_detection"}, cty.IndexStep{Key: cty.NumberIntVal(0)}, cty.GetAttrStep{Name: "timeout"}},
+					}}
+				}
+				detector.timeout = parsed
+			}
+		}
+	}
+
+	// Agreement between two sources cannot happen with fewer than two of them, and
+	// failing every run with "no two sources agreed" would hide the real mistake.
+	if detector.requireAgreement && len(detector.sources()) < 2 {
+		return nil, diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "client_ip_detection.require_agreement needs two sources",
+			Detail: "require_agreement makes detection wait until two sources report the same address, but only one source is configured. " +
+				"List at least two urls (or a command and a url), or turn require_agreement off.",
+			AttributePath: cty.Path{cty.GetAttrStep{Name: "client_ip_detection"}, cty.IndexStep{Key: cty.NumberIntVal(0)}, cty.GetAttrStep{Name: "require_agreement"}},
+		}}
+	}
+
+	detector.httpClient = &http.Client{
+		Transport: clientIPDetectionTransport(transport, detector.family),
+	}
+	return detector, nil
 }
 
 // validateRequestsPerMinute enforces that requests_per_minute stays within
@@ -0,0 +1,154 @@
+package namecheap_provider
+
+import (
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+)
+
+// providerMeta is the provider meta configureContext returns: the SDK client
+// and the provider-block settings it was configured with. CRUD functions take
+// the client from it and hand the settings they need to their helpers.
+type providerMeta struct {
+	client *namecheap.Client
+	config *providerConfig
+}
+
+// providerConfig carries the provider-block settings that resources and data
+// sources need beyond the SDK client itself.
+type providerConfig struct {
+	// clientIPDetector is the detector built from the client_ip_detection
+	// block, reused by anything that needs to re-check the public address.
+	clientIPDetector *clientIPDetector
+
+	// clientIPSource records where the client's ClientIp came from: one of the
+	// clientIPSource* constants.
+	clientIPSource string
+
+	// defaults are the values of the provider's defaults block, with the
+	// built-in default filled in for anything it leaves unset.
+	defaults providerDefaults
+
+	// dnsResolver is the host:port of the dns_resolver setting, or "" for the
+	// system resolver.
+	dnsResolver string
+
+	// nameserverQueryPort is the nameserver_query_port setting: the port
+	// verify_nameservers sends its SOA queries to.
+	nameserverQueryPort int
+}
+
+// providerDefaults are the record attribute defaults the defaults block can
+// override. emailType is empty when no default applies, which leaves a
+// domain's email setting alone.
+type providerDefaults struct {
+	ttl         int
+	mxPref      int
+	recordsMode string
+	emailType   string
+}
+
+// Built-in record defaults, used for anything the defaults block leaves unset.
+const (
+	defaultRecordTTL    = 1800
+	defaultRecordMXPref = 10
+)
+
+// Where a configured client_ip came from.
+const (
+	clientIPSourceConfigured = "configured"
+	clientIPSourceDetected   = "detected"
+	clientIPSourceCache      = "cache"
+)
+
+// defaultProviderConfig returns the settings an empty provider block produces.
+func defaultProviderConfig() *providerConfig {
+	return &providerConfig{
+		clientIPDetector:    defaultClientIPDetector(),
+		clientIPSource:      clientIPSourceConfigured,
+		defaults:            builtinProviderDefaults(),
+		nameserverQueryPort: defaultNameserverQueryPort,
+	}
+}
+
+func builtinProviderDefaults() providerDefaults {
+	return providerDefaults{
+		ttl:         defaultRecordTTL,
+		mxPref:      defaultRecordMXPref,
+		recordsMode: ncModeMerge,
+	}
+}
+
+// providerDefaultsFromConfig reads the defaults block over the built-in
+// defaults.
+func providerDefaultsFromConfig(data *schema.ResourceData) providerDefaults {
+	defaults := builtinProviderDefaults()
+
+	blocks, _ := data.Get("defaults").([]interface{})
+	if len(blocks) == 0 || blocks[0] == nil {
+		return defaults
+	}
+	block := blocks[0].(map[string]interface{})
+
+	if ttl, _ := block["ttl"].(int); ttl != 0 {
+		defaults.ttl = ttl
+	}
+	// 0 is the most preferred MX preference, not "unset", so GetOk cannot tell
+	// the two apart. The provider's ResourceData carries the raw configuration,
+	// which GetOkExists consults.
+	if raw, ok := data.GetOkExists("defaults.0.mx_pref"); ok {
+		defaults.mxPref = raw.(int)
+	}
+	if mode, _ := block["records_mode"].(string); mode != "" {
+		defaults.recordsMode = strings.ToUpper(mode)
+	}
+	if emailType, _ := block["email_type"].(string); emailType != "" {
+		defaults.emailType = emailType
+	}
+	return defaults
+}
+
+// providerDefaultsSource gives the record attributes the defaults block covers
+// the defaults of the provider their resource belongs to, as schema
+// DefaultFuncs.
+//
+// The defaults are applied as DefaultFuncs rather than at apply time so that a
+// plan shows the effective value of an unset attribute: an attribute defaulted
+// behind the plan's back would read back from the API as something the
+// configuration does not say, and diff forever. Until the provider is
+// configured (validation runs first), and for a nil source, the built-in
+// defaults apply.
+type providerDefaultsSource struct {
+	provider *schema.Provider
+}
+
+func (s *providerDefaultsSource) current() providerDefaults {
+	if s != nil && s.provider != nil {
+		if meta, ok := s.provider.Meta().(*providerMeta); ok {
+			return meta.config.defaults
+		}
+	}
+	return builtinProviderDefaults()
+}
+
+func (s *providerDefaultsSource) ttl() (interface{}, error) {
+	return s.current().ttl, nil
+}
+
+func (s *providerDefaultsSource) mxPref() (interface{}, error) {
+	return s.current().mxPref, nil
+}
+
+func (s *providerDefaultsSource) recordsMode() (interface{}, error) {
+	return s.current().recordsMode, nil
+}
+
+func (s *providerDefaultsSource) emailType() (interface{}, error) {
+	// nil rather than "": an empty default would read as a configured
+	// email_type and be sent to the API.
+	if emailType := s.current().emailType; emailType != "" {
+		return emailType, nil
+	}
+	return nil, nil
+}
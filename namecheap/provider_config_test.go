@@ -0,0 +1,123 @@
+package namecheap_provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configureWithDefaults configures a provider with the given defaults block
+// (nil for none) and an explicit client_ip, so no detection runs.
+func configureWithDefaults(t *testing.T, defaults map[string]interface{}) *schema.Provider {
+	t.Helper()
+	setRequiredCredentialsWithoutClientIP(t)
+
+	raw := map[string]interface{}{"client_ip": testPlaceholderClientIP}
+	if defaults != nil {
+		raw["defaults"] = []interface{}{defaults}
+	}
+	p := Provider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	return p
+}
+
+func TestProviderDefaultsBuiltIn(t *testing.T) {
+	p := configureWithDefaults(t, nil)
+
+	defaults := p.Meta().(*providerMeta).config.defaults
+	assert.Equal(t, builtinProviderDefaults(), defaults)
+	assert.Equal(t, 1800, defaults.ttl)
+	assert.Equal(t, 10, defaults.mxPref)
+	assert.Equal(t, ncModeMerge, defaults.recordsMode)
+	assert.Empty(t, defaults.emailType)
+}
+
+func TestProviderDefaultsFromConfig(t *testing.T) {
+	p := configureWithDefaults(t, map[string]interface{}{
+		"ttl":          300,
+		"mx_pref":      0,
+		"records_mode": "overwrite",
+		"email_type":   "MX",
+	})
+
+	defaults := p.Meta().(*providerMeta).config.defaults
+	assert.Equal(t, 300, defaults.ttl)
+	assert.Equal(t, 0, defaults.mxPref, "0 is the most preferred MX preference, not unset")
+	assert.Equal(t, ncModeOverwrite, defaults.recordsMode, "the mode is canonicalized to upper case")
+	assert.Equal(t, "MX", defaults.emailType)
+}
+
+// TestProviderDefaultsShowInPlan is the point of the feature: an unset record
+// attribute is planned with the provider's default, not the built-in one, so
+// the plan shows what will be written and a refresh reading it back is clean.
+func TestProviderDefaultsShowInPlan(t *testing.T) {
+	p := configureWithDefaults(t, map[string]interface{}{
+		"ttl":          300,
+		"mx_pref":      5,
+		"records_mode": "OVERWRITE",
+	})
+
+	records := p.ResourcesMap["namecheap_domain_records"]
+	diff, err := records.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"domain": "example.com",
+		"record": []interface{}{map[string]interface{}{
+			"hostname": "mail",
+			"type":     "MX",
+			"address":  "mx.example.com",
+		}},
+	}), p.Meta())
+	require.NoError(t, err)
+
+	planned := map[string]string{}
+	for key, attr := range diff.Attributes {
+		planned[key] = attr.New
+	}
+	assert.Equal(t, "OVERWRITE", planned["mode"])
+	assert.NotContains(t, planned, "email_type", "no email_type default means none is planned")
+
+	var ttl, mxPref string
+	for key, value := range planned {
+		switch {
+		case strings.HasSuffix(key, ".ttl"):
+			ttl = value
+		case strings.HasSuffix(key, ".mx_pref"):
+			mxPref = value
+		}
+	}
+	assert.Equal(t, "300", ttl)
+	assert.Equal(t, "5", mxPref)
+
+	hostRecord := p.ResourcesMap["namecheap_domain_host_record"].Schema
+	ttlDefault, err := hostRecord["ttl"].DefaultValue()
+	require.NoError(t, err)
+	assert.Equal(t, 300, ttlDefault)
+}
+
+func TestProviderDefaultsBeforeConfigure(t *testing.T) {
+	// Validation runs before the provider is configured; the built-in defaults
+	// must apply until then rather than failing on a missing client.
+	p := Provider()
+	value, err := p.ResourcesMap["namecheap_domain_records"].Schema["mode"].DefaultValue()
+	require.NoError(t, err)
+	assert.Equal(t, ncModeMerge, value)
+
+	value, err = p.ResourcesMap["namecheap_domain_records"].Schema["email_type"].DefaultValue()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
+
+func TestStateMXPref(t *testing.T) {
+	mx := &namecheap.DomainsDNSHostRecordDetailed{Type: namecheap.String("MX"), MXPref: namecheap.Int(5)}
+	a := &namecheap.DomainsDNSHostRecordDetailed{Type: namecheap.String("A"), MXPref: namecheap.Int(10)}
+
+	assert.Equal(t, 5, *stateMXPref(mx, namecheap.UInt8(20), 20), "an MX record keeps its live preference")
+	assert.Equal(t, 30, *stateMXPref(a, namecheap.UInt8(30), 20), "other types keep the configured value")
+	assert.Equal(t, 20, *stateMXPref(a, nil, 20), "an unconfigured record takes the provider default")
+}
@@ -14,11 +14,13 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/namecheap/go-namecheap-sdk/v2/namecheap"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var testAccNamecheapProvider *schema.Provider
@@ -280,6 +282,7 @@ func setRequiredCredentialsWithoutClientIP(t *testing.T) {
 	t.Setenv("NAMECHEAP_API_USER", "test-api-user")
 	t.Setenv("NAMECHEAP_API_KEY", "test-api-key")
 	t.Setenv("NAMECHEAP_CLIENT_IP", "")
+	t.Setenv("NAMECHEAP_CLIENT_IP_CACHE_FILE", "")
 }
 
 func TestProviderConfigureAutoDetectsClientIPWhenUnset(t *testing.T) {
@@ -298,7 +301,7 @@ func TestProviderConfigureAutoDetectsClientIPWhenUnset(t *testing.T) {
 	diags := rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
 	assert.False(t, diags.HasError(), "expected no errors on successful auto-detect, got: %v", diags)
 
-	client := rawProvider.Meta().(*namecheap.Client)
+	client := rawProvider.Meta().(*providerMeta).client
 	assert.Equal(t, "203.0.113.7", client.ClientOptions.ClientIp)
 }
 
@@ -341,7 +344,7 @@ func TestProviderConfigureExplicitClientIPHonored(t *testing.T) {
 	diags := rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
 	assert.False(t, diags.HasError(), "expected no errors when client_ip is set inline, got: %v", diags)
 
-	client := rawProvider.Meta().(*namecheap.Client)
+	client := rawProvider.Meta().(*providerMeta).client
 	assert.Equal(t, "198.51.100.42", client.ClientOptions.ClientIp)
 }
 
@@ -364,10 +367,135 @@ func TestProviderConfigureWhitespaceClientIPTriggersDetection(t *testing.T) {
 	diags := rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
 	assert.False(t, diags.HasError(), "expected no errors, got: %v", diags)
 
-	client := rawProvider.Meta().(*namecheap.Client)
+	client := rawProvider.Meta().(*providerMeta).client
 	assert.Equal(t, "203.0.113.7", client.ClientOptions.ClientIp)
 }
 
+// ipServer starts an httptest server that answers every request with body, and
+// closes it when the test ends.
+func ipServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// closedServerURL returns the URL of a server that has already been shut down,
+// so requests to it fail with connection refused.
+func closedServerURL() string {
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}))
+	url := server.URL
+	server.Close()
+	return url
+}
+
+func TestProviderConfigureRecordsClientIPSource(t *testing.T) {
+	setRequiredCredentialsWithoutClientIP(t)
+	withDetectionURL(t, ipServer(t, "203.0.113.7").URL)
+
+	rawProvider := Provider()
+	diags := rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{}))
+	assert.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	assert.Equal(t, clientIPSourceDetected, rawProvider.Meta().(*providerMeta).config.clientIPSource)
+
+	rawProvider = Provider()
+	diags = rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"client_ip": "198.51.100.42",
+	}))
+	assert.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	assert.Equal(t, clientIPSourceConfigured, rawProvider.Meta().(*providerMeta).config.clientIPSource)
+}
+
+func TestProviderConfigureClientIPDetectionURLsFallBackInOrder(t *testing.T) {
+	setRequiredCredentialsWithoutClientIP(t)
+	// The built-in endpoint must not be consulted once urls are listed.
+	withDetectionURL(t, ipServer(t, "192.0.2.99").URL)
+
+	rawProvider := Provider()
+	raw := map[string]interface{}{
+		"client_ip_detection": []interface{}{map[string]interface{}{
+			"urls": []interface{}{closedServerURL(), ipServer(t, "203.0.113.8").URL},
+		}},
+	}
+	diags := rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
+	assert.False(t, diags.HasError(), "expected the second url to answer, got: %v", diags)
+
+	client := rawProvider.Meta().(*providerMeta).client
+	assert.Equal(t, "203.0.113.8", client.ClientOptions.ClientIp)
+}
+
+func TestProviderConfigureClientIPDetectionRequireAgreementNeedsTwoSources(t *testing.T) {
+	setRequiredCredentialsWithoutClientIP(t)
+
+	rawProvider := Provider()
+	raw := map[string]interface{}{
+		"client_ip_detection": []interface{}{map[string]interface{}{
+			"urls":              []interface{}{ipServer(t, "203.0.113.8").URL},
+			"require_agreement": true,
+		}},
+	}
+	diags := rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
+	assert.True(t, diags.HasError(), "require_agreement with one source must be rejected")
+	assert.Equal(t, "client_ip_detection.require_agreement needs two sources", diags[0].Summary)
+}
+
+func TestProviderConfigureClientIPCacheFallback(t *testing.T) {
+	setRequiredCredentialsWithoutClientIP(t)
+	withDetectionURL(t, closedServerURL())
+
+	cacheFile := t.TempDir() + "/client_ip"
+	assert.NoError(t, os.WriteFile(cacheFile, []byte("198.51.100.9\n"), 0o600))
+
+	rawProvider := Provider()
+	raw := map[string]interface{}{
+		"client_ip_cache_file": cacheFile,
+	}
+	diags := rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
+	assert.False(t, diags.HasError(), "a readable cache file must stand in for detection, got: %v", diags)
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, diag.Warning, diags[0].Severity)
+		assert.Equal(t, "Using cached client_ip", diags[0].Summary)
+	}
+
+	client := rawProvider.Meta().(*providerMeta).client
+	assert.Equal(t, "198.51.100.9", client.ClientOptions.ClientIp)
+}
+
+func TestProviderConfigureClientIPCacheRefreshedOnDetection(t *testing.T) {
+	setRequiredCredentialsWithoutClientIP(t)
+	withDetectionURL(t, ipServer(t, "203.0.113.7").URL)
+
+	cacheFile := t.TempDir() + "/client_ip"
+	assert.NoError(t, os.WriteFile(cacheFile, []byte("198.51.100.9\n"), 0o600))
+
+	rawProvider := Provider()
+	raw := map[string]interface{}{
+		"client_ip_cache_file": cacheFile,
+	}
+	diags := rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
+	assert.Empty(t, diags)
+
+	cached, err := os.ReadFile(cacheFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.7\n", string(cached))
+}
+
+func TestProviderConfigureClientIPCacheMissingStillFails(t *testing.T) {
+	setRequiredCredentialsWithoutClientIP(t)
+	withDetectionURL(t, closedServerURL())
+
+	rawProvider := Provider()
+	raw := map[string]interface{}{
+		"client_ip_cache_file": t.TempDir() + "/absent",
+	}
+	diags := rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
+	assert.True(t, diags.HasError())
+	assert.Equal(t, "Unable to auto-detect client_ip", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "cache fallback also failed")
+}
+
 // Resilience config options: requests_per_minute, max_retries,
 // retry_max_elapsed, retry_base_delay, retry_max_delay, request_timeout.
 
@@ -431,8 +559,9 @@ func TestProviderResilienceDefaultsPreserveCurrentBehavior(t *testing.T) {
 	diags := rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
 	assert.False(t, diags.HasError(), "expected no errors with only required fields set, got: %v", diags)
 
-	client, ok := rawProvider.Meta().(*namecheap.Client)
-	assert.True(t, ok, "expected provider meta to be *namecheap.Client")
+	meta, ok := rawProvider.Meta().(*providerMeta)
+	require.True(t, ok, "expected provider meta to be *providerMeta")
+	client := meta.client
 	assert.Equal(t, defaultRequestsPerMinute, client.ClientOptions.RateLimit.PerMinute)
 	assert.Equal(t, defaultMaxRetries, client.ClientOptions.Retry.MaxAttempts)
 	assert.Equal(t, 2*time.Minute, client.ClientOptions.Retry.MaxElapsed)
@@ -457,7 +586,7 @@ func TestProviderResilienceFieldsFromInlineConfig(t *testing.T) {
 	diags := rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
 	assert.False(t, diags.HasError(), "expected no errors, got: %v", diags)
 
-	client := rawProvider.Meta().(*namecheap.Client)
+	client := rawProvider.Meta().(*providerMeta).client
 	assert.Equal(t, 5, client.ClientOptions.RateLimit.PerMinute)
 	assert.Equal(t, 10, client.ClientOptions.Retry.MaxAttempts)
 	assert.Equal(t, 90*time.Second, client.ClientOptions.Retry.MaxElapsed)
@@ -478,7 +607,7 @@ func TestProviderResilienceFieldsFromEnvVars(t *testing.T) {
 	diags := rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
 	assert.False(t, diags.HasError(), "expected no errors, got: %v", diags)
 
-	client := rawProvider.Meta().(*namecheap.Client)
+	client := rawProvider.Meta().(*providerMeta).client
 	assert.Equal(t, 7, client.ClientOptions.RateLimit.PerMinute)
 	assert.Equal(t, 6, client.ClientOptions.Retry.MaxAttempts)
 	assert.Equal(t, 3*time.Minute, client.ClientOptions.Retry.MaxElapsed)
@@ -533,6 +662,26 @@ func TestProviderValidateRequestsPerMinuteBounds(t *testing.T) {
 	}
 }
 
+func TestProviderValidateClientIPDetectionURLs(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"https://ip.example.com/", false},
+		{"http://ip.example.com/", true},
+		{"ftp://ip.example.com/", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			raw := map[string]interface{}{
+				"client_ip_detection": []interface{}{map[string]interface{}{"urls": []interface{}{tt.url}}},
+			}
+			diags := Provider().Validate(terraform.NewResourceConfigRaw(raw))
+			assert.Equal(t, tt.wantErr, diags.HasError(), "urls=%s diags=%v", tt.url, diags)
+		})
+	}
+}
+
 func TestProviderValidateMaxRetriesBounds(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -806,7 +955,7 @@ func TestProviderRetryBackoffFromEnvVars(t *testing.T) {
 	diags := rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
 	assert.False(t, diags.HasError(), "expected no errors, got: %v", diags)
 
-	client := rawProvider.Meta().(*namecheap.Client)
+	client := rawProvider.Meta().(*providerMeta).client
 	assert.Equal(t, 5*time.Second, client.ClientOptions.Retry.BaseDelay)
 	assert.Equal(t, time.Minute, client.ClientOptions.Retry.MaxDelay)
 }
@@ -943,7 +1092,7 @@ func TestProviderRetryBackoffConfigMatchesCI(t *testing.T) {
 	diags := rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
 	assert.False(t, diags.HasError(), "expected no errors, got: %v", diags)
 
-	retry := rawProvider.Meta().(*namecheap.Client).ClientOptions.Retry
+	retry := rawProvider.Meta().(*providerMeta).client.ClientOptions.Retry
 	assert.Equal(t, ciRetry.attempts, retry.MaxAttempts)
 	assert.Equal(t, ciRetry.baseDelay, retry.BaseDelay)
 	assert.Equal(t, ciRetry.maxDelay, retry.MaxDelay)
@@ -979,7 +1128,7 @@ func TestProviderRetryCostsOneRequestPerAttempt(t *testing.T) {
 	diags := rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
 	assert.False(t, diags.HasError(), "expected no errors, got: %v", diags)
 
-	client := rawProvider.Meta().(*namecheap.Client)
+	client := rawProvider.Meta().(*providerMeta).client
 	client.BaseURL = server.URL
 
 	start := time.Now()
@@ -0,0 +1,66 @@
+package namecheap_provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// defaultResourceTimeout is the default of every operation in a resource's
+// timeouts block. It is the deadline the SDK imposed on each operation before
+// the blocks were declared, so declaring them changes nothing until one is set.
+const defaultResourceTimeout = 20 * time.Minute
+
+// resourceTimeouts declares the timeouts block shared by every resource. The SDK
+// turns the configured value into the deadline of the context each CRUD function
+// receives, which bounds the SDK's retries (retry_max_elapsed applies per call,
+// not per operation) and the wait for ncMutexKV.
+func resourceTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(defaultResourceTimeout),
+		Read:   schema.DefaultTimeout(defaultResourceTimeout),
+		Update: schema.DefaultTimeout(defaultResourceTimeout),
+		Delete: schema.DefaultTimeout(defaultResourceTimeout),
+	}
+}
+
+// lockDomain takes the ncMutexKV lock that serializes changes to domain within
+// one run, giving up when ctx ends. A nil return holds the lock, which the
+// caller releases with ncMutexKV.Unlock(domain).
+//
+// Without the deadline, one operation stuck on a slow zone write left every
+// other resource on the same domain blocked for as long as it took, with
+// nothing in the log to say why.
+func lockDomain(ctx context.Context, domain string) diag.Diagnostics {
+	if err := ncMutexKV.LockContext(ctx, domain); err != nil {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Timed out waiting for another change to " + domain,
+			Detail: fmt.Sprintf("Changes to one domain's DNS are applied one at a time, and another change to %s was still in progress "+
+				"when this operation's deadline passed (%s). Raise the relevant value in the resource's timeouts block, "+
+				"or reduce the number of resources writing to the domain at once.", domain, err),
+		}}
+	}
+	return nil
+}
+
+// timeoutDiagnostic explains an SDK call that failed on a deadline, or returns
+// nil when err is not one. The error alone cannot say whose deadline it was:
+// the HTTP client's request_timeout and the operation's timeouts block both
+// surface as context.DeadlineExceeded, so the detail names both.
+func timeoutDiagnostic(err error) diag.Diagnostics {
+	if !errors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  "Namecheap API call timed out",
+		Detail: fmt.Sprintf("%s. Either a single request exceeded the provider's request_timeout, or the whole operation exceeded "+
+			"the deadline set by the resource's timeouts block (%s by default). The change may still have been applied; "+
+			"the next plan reads back what Namecheap holds.", err, defaultResourceTimeout),
+	}}
+}
@@ -0,0 +1,113 @@
+package namecheap_provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourcesDeclareTimeouts(t *testing.T) {
+	for name, resource := range Provider().ResourcesMap {
+		t.Run(name, func(t *testing.T) {
+			require.NotNil(t, resource.Timeouts, "resource declares no timeouts block")
+			for operation, timeout := range map[string]*time.Duration{
+				schema.TimeoutCreate: resource.Timeouts.Create,
+				schema.TimeoutRead:   resource.Timeouts.Read,
+				schema.TimeoutUpdate: resource.Timeouts.Update,
+				schema.TimeoutDelete: resource.Timeouts.Delete,
+			} {
+				if assert.NotNil(t, timeout, operation) {
+					assert.Equal(t, defaultResourceTimeout, *timeout, operation)
+				}
+			}
+		})
+	}
+}
+
+func TestLockDomainGivesUpAtDeadline(t *testing.T) {
+	ncMutexKV.Lock("held.example")
+	defer ncMutexKV.Unlock("held.example")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	diags := lockDomain(ctx, "held.example")
+	require.True(t, diags.HasError())
+	assert.Equal(t, "Timed out waiting for another change to held.example", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "timeouts block")
+}
+
+// A MERGE write blocked behind another change to the same domain must fail at
+// its deadline without calling the API, rather than queueing forever.
+func TestDomainRecordsCreateTimesOutWaitingForLock(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	ncMutexKV.Lock("queued.example")
+	defer ncMutexKV.Unlock("queued.example")
+
+	data := resourceNamecheapDomainRecords(nil).TestResourceData()
+	_ = data.Set("domain", "queued.example")
+	_ = data.Set("mode", ncModeMerge)
+	_ = data.Set("email_type", "NONE")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	diags := resourceRecordCreate(ctx, data, testMeta(newTestClient(server.URL)))
+	require.True(t, diags.HasError())
+	assert.True(t, strings.HasPrefix(diags[0].Summary, "Timed out waiting"), diags[0].Summary)
+	assert.Zero(t, calls.Load(), "no API call may be made without the lock")
+	assert.Empty(t, data.Id())
+}
+
+func TestHostRecordUpdateTimedOutOnLockRestoresState(t *testing.T) {
+	ncMutexKV.Lock("queued.example")
+	defer ncMutexKV.Unlock("queued.example")
+
+	resource := resourceNamecheapDomainHostRecord(nil)
+	state := resource.TestResourceData()
+	state.SetId(hostRecordID("queued.example", "A", "www", "10.0.0.1"))
+	_ = state.Set("domain", "queued.example")
+	_ = state.Set("hostname", "www")
+	_ = state.Set("type", "A")
+	_ = state.Set("address", "10.0.0.1")
+	_ = state.Set("ttl", 1800)
+
+	diff, err := schema.InternalMap(resource.Schema).Diff(context.Background(), state.State(),
+		terraform.NewResourceConfigRaw(map[string]interface{}{
+			"domain": "queued.example", "hostname": "www", "type": "A", "address": "10.0.0.2", "ttl": 1800,
+		}), nil, nil, true)
+	require.NoError(t, err)
+	data, err := schema.InternalMap(resource.Schema).Data(state.State(), diff)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.2", data.Get("address"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	diags := resourceNamecheapDomainHostRecordUpdate(ctx, data, testMeta(newTestClient("http://127.0.0.1:0")))
+	require.True(t, diags.HasError())
+	assert.Equal(t, "10.0.0.1", data.Get("address"), "the planned address must not be persisted")
+}
+
+func TestDiagFromClientErrorExplainsDeadline(t *testing.T) {
+	diags := diagFromClientError(fmt.Errorf("requesting namecheap.domains.dns.setHosts: %w", context.DeadlineExceeded))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Namecheap API call timed out", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "request_timeout")
+	assert.Contains(t, diags[0].Detail, "timeouts block")
+}
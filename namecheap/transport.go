@@ -0,0 +1,153 @@
+package namecheap_provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// transportSettings are the network settings of the provider block that shape
+// every outbound connection: the Namecheap API calls and client_ip detection
+// alike. Both are built from the one *http.Transport returned by build, so an
+// egress proxy that intercepts TLS is configured once and cannot be bypassed by
+// the detection request reporting a different address than the API sees.
+type transportSettings struct {
+	proxyURL           *url.URL
+	caBundleFile       string
+	clientCertFile     string
+	clientKeyFile      string
+	insecureSkipVerify bool
+}
+
+// proxySchemes are the proxy_url schemes net/http can dial through.
+var proxySchemes = []string{"http", "https", "socks5"}
+
+// transportSettingsFromConfig reads and cross-checks the transport arguments.
+// Every failure is tied to the attribute that caused it.
+func transportSettingsFromConfig(data *schema.ResourceData) (transportSettings, diag.Diagnostics) {
+	var settings transportSettings
+
+	if raw := strings.TrimSpace(data.Get("proxy_url").(string)); raw != "" {
+		parsed, err := url.Parse(raw)
+		if err == nil && (parsed.Host == "" || !slices.Contains(proxySchemes, parsed.Scheme)) {
+			err = fmt.Errorf("must be an absolute URL with one of the schemes %s", strings.Join(proxySchemes, ", "))
+		}
+		if err != nil {
+			return settings, diag.Diagnostics{{
+				Severity:      diag.Error,
+				Summary:       "Invalid proxy_url",
+				Detail:        fmt.Sprintf("proxy_url %q: %s", raw, err),
+				AttributePath: cty.Path{cty.GetAttrStep{Name: "proxy_url"}},
+			}}
+		}
+		settings.proxyURL = parsed
+	}
+
+	settings.caBundleFile = strings.TrimSpace(data.Get("ca_bundle_file").(string))
+	settings.clientCertFile = strings.TrimSpace(data.Get("client_cert_file").(string))
+	settings.clientKeyFile = strings.TrimSpace(data.Get("client_key_file").(string))
+	settings.insecureSkipVerify = data.Get("insecure_skip_verify").(bool)
+
+	// A certificate without its key (or the reverse) cannot be presented, and
+	// silently connecting without one turns into an opaque handshake failure at
+	// the proxy.
+	if (settings.clientCertFile == "") != (settings.clientKeyFile == "") {
+		missing := "client_key_file"
+		if settings.clientCertFile == "" {
+			missing = "client_cert_file"
+		}
+		return settings, diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Incomplete client certificate",
+			Detail:        "client_cert_file and client_key_file must be set together; " + missing + " is missing.",
+			AttributePath: cty.Path{cty.GetAttrStep{Name: missing}},
+		}}
+	}
+
+	// Certificate verification is what keeps the API key from being handed to
+	// whoever answers on the production endpoint. Turning it off is tolerated for
+	// the sandbox, whose accounts hold nothing of value, and nowhere else.
+	if settings.insecureSkipVerify && !data.Get("use_sandbox").(bool) {
+		return settings, diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "insecure_skip_verify requires use_sandbox",
+			Detail: "insecure_skip_verify disables TLS certificate verification, which would expose the production API key to any " +
+				"interceptor. It is only accepted together with use_sandbox = true. To trust a corporate proxy's certificate, " +
+				"set ca_bundle_file instead.",
+			AttributePath: cty.Path{cty.GetAttrStep{Name: "insecure_skip_verify"}},
+		}}
+	}
+
+	return settings, nil
+}
+
+// build returns a transport derived from http.DefaultTransport with the
+// settings applied. With no settings it is an unmodified clone, so proxies from
+// HTTPS_PROXY/NO_PROXY keep working exactly as before these arguments existed.
+func (s transportSettings) build() (*http.Transport, diag.Diagnostics) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if s.proxyURL != nil {
+		transport.Proxy = http.ProxyURL(s.proxyURL)
+	}
+
+	if s.caBundleFile == "" && s.clientCertFile == "" && !s.insecureSkipVerify {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if s.caBundleFile != "" {
+		pem, err := os.ReadFile(s.caBundleFile)
+		if err != nil {
+			return nil, transportFileError("ca_bundle_file", "Unable to read ca_bundle_file", err)
+		}
+		// The bundle extends the system roots rather than replacing them: the
+		// usual reason to set it is a proxy re-signing traffic, and everything it
+		// does not intercept is still signed by a public CA.
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, transportFileError("ca_bundle_file", "Invalid ca_bundle_file",
+				fmt.Errorf("%s contains no PEM-encoded certificates", s.caBundleFile))
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if s.clientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(s.clientCertFile, s.clientKeyFile)
+		if err != nil {
+			return nil, transportFileError("client_cert_file", "Unable to load client certificate", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if s.insecureSkipVerify {
+		log.Printf("[WARN] namecheap: insecure_skip_verify is set; TLS certificates are not verified")
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+func transportFileError(attribute, summary string, err error) diag.Diagnostics {
+	return diag.Diagnostics{{
+		Severity:      diag.Error,
+		Summary:       summary,
+		Detail:        err.Error(),
+		AttributePath: cty.Path{cty.GetAttrStep{Name: attribute}},
+	}}
+}
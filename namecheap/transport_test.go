@@ -0,0 +1,190 @@
+package namecheap_provider
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clearTransportEnvVars keeps an ambient proxy or certificate setting from
+// leaking into the configuration under test.
+func clearTransportEnvVars(t *testing.T) {
+	t.Helper()
+	for _, k := range []string{
+		"NAMECHEAP_PROXY_URL", "NAMECHEAP_CA_BUNDLE_FILE", "NAMECHEAP_CLIENT_CERT_FILE",
+		"NAMECHEAP_CLIENT_KEY_FILE", "NAMECHEAP_INSECURE_SKIP_VERIFY", "HTTP_PROXY", "HTTPS_PROXY",
+	} {
+		t.Setenv(k, "")
+	}
+}
+
+// writeServerCA writes the certificate of a TLS httptest server as a PEM
+// bundle, the way a corporate proxy's CA would be handed to the provider.
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	block := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(path, block, 0o600))
+	return path
+}
+
+func TestProviderConfigureProxyURLAppliesToDetection(t *testing.T) {
+	setRequiredCredentialsWithoutClientIP(t)
+	clearTransportEnvVars(t)
+
+	// A plain-HTTP proxy sees the absolute request URI of the origin it is asked
+	// to reach; answering itself proves detection was routed through it.
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		_, _ = w.Write([]byte("203.0.113.7"))
+	}))
+	defer proxy.Close()
+
+	rawProvider := Provider()
+	raw := map[string]interface{}{
+		"proxy_url": proxy.URL,
+		"client_ip_detection": []interface{}{map[string]interface{}{
+			"urls": []interface{}{"http://ip.example.invalid/"},
+		}},
+	}
+	diags := rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
+	require.False(t, diags.HasError(), "expected detection through the proxy, got: %v", diags)
+
+	assert.Equal(t, "http://ip.example.invalid/", proxied)
+	client := rawProvider.Meta().(*providerMeta).client
+	assert.Equal(t, "203.0.113.7", client.ClientOptions.ClientIp)
+
+	transport, ok := client.ClientOptions.HTTPClient.Transport.(*http.Transport)
+	require.True(t, ok, "the API client should carry the configured transport")
+	req, _ := http.NewRequest(http.MethodGet, "https://api.namecheap.com/xml.response", nil)
+	proxyURL, err := transport.Proxy(req)
+	require.NoError(t, err)
+	assert.Equal(t, proxy.URL, proxyURL.String(), "API calls must use the same proxy as detection")
+}
+
+func TestProviderConfigureCABundleTrustsPrivateCA(t *testing.T) {
+	setRequiredCredentialsWithoutClientIP(t)
+	clearTransportEnvVars(t)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("203.0.113.7"))
+	}))
+	defer server.Close()
+	detection := []interface{}{map[string]interface{}{"urls": []interface{}{server.URL}}}
+
+	// Without the bundle the self-signed certificate is rejected.
+	diags := Provider().Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"client_ip_detection": detection,
+	}))
+	assert.True(t, diags.HasError(), "an untrusted certificate must fail detection")
+
+	rawProvider := Provider()
+	diags = rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"client_ip_detection": detection,
+		"ca_bundle_file":      writeServerCA(t, server),
+	}))
+	require.False(t, diags.HasError(), "expected the bundle to be trusted, got: %v", diags)
+	assert.Equal(t, "203.0.113.7", rawProvider.Meta().(*providerMeta).client.ClientOptions.ClientIp)
+}
+
+func TestProviderConfigureInvalidCABundle(t *testing.T) {
+	setRequiredCredentialsWithoutClientIP(t)
+	clearTransportEnvVars(t)
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(path, []byte("not a certificate"), 0o600))
+
+	diags := Provider().Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"client_ip":      testPlaceholderClientIP,
+		"ca_bundle_file": path,
+	}))
+	require.True(t, diags.HasError())
+	assert.Equal(t, "Invalid ca_bundle_file", diags[0].Summary)
+	assert.Equal(t, cty.Path{cty.GetAttrStep{Name: "ca_bundle_file"}}, diags[0].AttributePath)
+}
+
+func TestProviderConfigureInsecureSkipVerifyRequiresSandbox(t *testing.T) {
+	setRequiredCredentialsWithoutClientIP(t)
+	clearTransportEnvVars(t)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("203.0.113.7"))
+	}))
+	defer server.Close()
+
+	diags := Provider().Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"client_ip":            testPlaceholderClientIP,
+		"insecure_skip_verify": true,
+	}))
+	require.True(t, diags.HasError(), "insecure_skip_verify must be refused against production")
+	assert.Equal(t, "insecure_skip_verify requires use_sandbox", diags[0].Summary)
+
+	rawProvider := Provider()
+	diags = rawProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"use_sandbox":          true,
+		"insecure_skip_verify": true,
+		"client_ip_detection":  []interface{}{map[string]interface{}{"urls": []interface{}{server.URL}}},
+	}))
+	require.False(t, diags.HasError(), "expected an unverified sandbox connection, got: %v", diags)
+	assert.Equal(t, "203.0.113.7", rawProvider.Meta().(*providerMeta).client.ClientOptions.ClientIp)
+}
+
+func TestProviderConfigureTransportSettingsRejected(t *testing.T) {
+	cases := map[string]struct {
+		raw       map[string]interface{}
+		summary   string
+		attribute string
+	}{
+		"proxy without host": {
+			raw:       map[string]interface{}{"proxy_url": "http://"},
+			summary:   "Invalid proxy_url",
+			attribute: "proxy_url",
+		},
+		"proxy with unsupported scheme": {
+			raw:       map[string]interface{}{"proxy_url": "ftp://proxy.example.com:21"},
+			summary:   "Invalid proxy_url",
+			attribute: "proxy_url",
+		},
+		"certificate without key": {
+			raw:       map[string]interface{}{"client_cert_file": "/tmp/client.pem"},
+			summary:   "Incomplete client certificate",
+			attribute: "client_key_file",
+		},
+		"key without certificate": {
+			raw:       map[string]interface{}{"client_key_file": "/tmp/client.key"},
+			summary:   "Incomplete client certificate",
+			attribute: "client_cert_file",
+		},
+		"unreadable certificate": {
+			raw: map[string]interface{}{
+				"client_cert_file": "/nonexistent/client.pem",
+				"client_key_file":  "/nonexistent/client.key",
+			},
+			summary:   "Unable to load client certificate",
+			attribute: "client_cert_file",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			setRequiredCredentialsWithoutClientIP(t)
+			clearTransportEnvVars(t)
+			tc.raw["client_ip"] = testPlaceholderClientIP
+
+			diags := Provider().Configure(context.Background(), terraform.NewResourceConfigRaw(tc.raw))
+			require.True(t, diags.HasError())
+			assert.Equal(t, tc.summary, diags[0].Summary)
+			assert.Equal(t, cty.Path{cty.GetAttrStep{Name: tc.attribute}}, diags[0].AttributePath)
+		})
+	}
+}